@@ -1,15 +1,19 @@
 package deploy
 
 import (
+	"context"
 	"errors"
 	"fmt"
-	"sync"
 	"time"
 
-	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/daaru00/aws-ssm-document-cli/cmd/prune"
 	"github.com/daaru00/aws-ssm-document-cli/internal/aws"
 	"github.com/daaru00/aws-ssm-document-cli/internal/config"
 	"github.com/daaru00/aws-ssm-document-cli/internal/document"
+	"github.com/daaru00/aws-ssm-document-cli/internal/graph"
+	"github.com/daaru00/aws-ssm-document-cli/internal/output"
+	"github.com/daaru00/aws-ssm-document-cli/internal/pool"
+	"github.com/daaru00/aws-ssm-document-cli/internal/report"
 	"github.com/urfave/cli/v2"
 )
 
@@ -19,7 +23,7 @@ func NewCommand(globalFlags []cli.Flag) *cli.Command {
 		Name:    "deploy",
 		Aliases: []string{"up"},
 		Usage:   "Deploy SSM Documents",
-		Flags: append(globalFlags, []cli.Flag{
+		Flags: append(append(globalFlags, config.NewFilterFlags()...), []cli.Flag{
 			&cli.StringFlag{
 				Name:    "artifact-bucket",
 				Usage:   "Then artifact bucket name",
@@ -30,6 +34,16 @@ func NewCommand(globalFlags []cli.Flag) *cli.Command {
 				Usage:   "Then source code bucket name",
 				EnvVars: []string{"SSM_DOCUMENT_SOURCES_BUCKET", "SSM_DOCUMENT_SOURCES_BUCKET_NAME"},
 			},
+			&cli.StringFlag{
+				Name:    "backup-dir",
+				Usage:   "Directory where snapshots of documents are written before overwriting their content, ignored when artifact bucket is set",
+				Value:   ".backups",
+				EnvVars: []string{"SSM_DOCUMENT_BACKUP_DIR"},
+			},
+			&cli.BoolFlag{
+				Name:  "no-backup",
+				Usage: "Do not snapshot documents before overwriting their content",
+			},
 			&cli.BoolFlag{
 				Name:    "yes",
 				Aliases: []string{"y"},
@@ -38,12 +52,12 @@ func NewCommand(globalFlags []cli.Flag) *cli.Command {
 			&cli.BoolFlag{
 				Name:    "build",
 				Aliases: []string{"b"},
-				Usage:   "Build document before deploy",
+				Usage:   "Build document before deploy, script files are inlined in steps",
 			},
 			&cli.BoolFlag{
 				Name:    "upload",
 				Aliases: []string{"u"},
-				Usage:   "Upload code to source bucket",
+				Usage:   "Upload script files to sources bucket and attach them to the document",
 			},
 			&cli.BoolFlag{
 				Name:    "start",
@@ -55,6 +69,42 @@ func NewCommand(globalFlags []cli.Flag) *cli.Command {
 				Aliases: []string{"a"},
 				Usage:   "Select all documents",
 			},
+			&cli.DurationFlag{
+				Name:  "grace-period",
+				Usage: "Time given to running deploys to complete after an interrupt",
+				Value: pool.DefaultGracePeriod,
+			},
+			&cli.StringSliceFlag{
+				Name:    "report",
+				Usage:   "Write a report, format is type=path where type is \"junit\" or \"markdown\"",
+				EnvVars: []string{"SSM_DOCUMENT_REPORT"},
+			},
+			&cli.BoolFlag{
+				Name:  "prune",
+				Usage: "Remove documents of the project no more found locally after deploy",
+			},
+			&cli.BoolFlag{
+				Name:  "adopt",
+				Usage: "Take ownership of documents not deployed by this project",
+			},
+			&cli.StringSliceFlag{
+				Name:    "keep-tag-prefix",
+				Usage:   "Prefix of deployed tags to leave untouched, \"aws:\" tags are always kept",
+				EnvVars: []string{"SSM_DOCUMENT_KEEP_TAG_PREFIXES"},
+			},
+			&cli.BoolFlag{
+				Name:  "force-protected",
+				Usage: "Deploy protected documents without typed confirmation, allowing share permissions removal",
+			},
+			&cli.BoolFlag{
+				Name:    "require-approval",
+				Usage:   "Submit updated versions for review instead of setting them as default",
+				EnvVars: []string{"SSM_DOCUMENT_REQUIRE_APPROVAL"},
+			},
+			&cli.StringFlag{
+				Name:  "review-comment",
+				Usage: "Comment attached to versions submitted for review",
+			},
 			&cli.IntFlag{
 				Name:  "parallels",
 				Usage: "Set max deploy executed in parallel",
@@ -68,6 +118,23 @@ func NewCommand(globalFlags []cli.Flag) *cli.Command {
 
 // Action contain the command flow
 func Action(c *cli.Context) error {
+	// Excluded documents would be considered orphans
+	if c.Bool("prune") && len(c.StringSlice("exclude")) > 0 {
+		return errors.New("Flag --exclude cannot be used with --prune, excluded documents would be removed")
+	}
+
+	// Ownership marker requires an explicit project
+	_, err := config.GetProject(c)
+	if err != nil {
+		return err
+	}
+
+	// Setup output printer
+	printer, err := output.NewPrinter(c)
+	if err != nil {
+		return err
+	}
+
 	// Create AWS session
 	ses := aws.NewAwsSession(c)
 
@@ -84,98 +151,220 @@ func Action(c *cli.Context) error {
 		return err
 	}
 
+	// Keep loaded documents to search orphans
+	loadedDocuments := *documents
+
 	// Ask document selection
-	documents, err = config.AskMultipleDocumentsSelection(c, *documents)
+	documents, err = config.AskMultipleDocumentsSelectionWithState(c, *documents)
 	if err != nil {
 		return err
 	}
 
-	// Setup errors channel
-	errs := make(chan error, len(*documents))
-
-	// Setup max parallels
-	allDocuments := *documents
-	parallels := c.Int("parallels")
-
-	// Start parallel deploy
-	for start := 0; start < len(allDocuments); start += parallels {
-		// Update chunk start and end
-		end := start + parallels
-		if end > len(allDocuments) {
-			end = len(allDocuments)
+	// Setup review of updated versions and ownership
+	for _, document := range *documents {
+		if c.Bool("require-approval") {
+			document.RequireApproval = true
+		}
+		err = document.ValidateReview()
+		if err != nil {
+			return err
 		}
+		document.ReviewComment = c.String("review-comment")
+		document.Adopt = c.Bool("adopt")
+		document.KeepTagPrefixes = c.StringSlice("keep-tag-prefix")
+	}
 
-		// Split array chunk
-		chunk := allDocuments[start:end]
+	// Ask typed confirmation for protected documents
+	config.ApplyProtection(c, *accountID, *documents)
+	err = config.AskProtectedConfirmation(c, *accountID, *documents, "deploy")
+	if err != nil {
+		return err
+	}
 
-		// Setup wait group for async jobs
-		var waitGroup sync.WaitGroup
+	// Stop starting new documents on interrupt
+	ctx, stop := pool.NotifyContext(printer.Progress())
+	defer stop()
 
-		// Loop over found document
-		for _, cy := range chunk {
+	// Build documents dependency graph
+	allDocuments := *documents
+	documentsGraph, err := graph.New(allDocuments)
+	if err != nil {
+		return err
+	}
 
-			// Execute parallel deploy
-			waitGroup.Add(1)
-			go func(document *document.Document) {
-				defer waitGroup.Done()
+	// Prepare results indexed as documents
+	documentsReport := output.NewReport("deploy", accountID, region, allDocuments)
+	results := map[*document.Document]*document.Result{}
+	for index, document := range allDocuments {
+		results[document] = documentsReport.Results[index]
+	}
 
-				err := deploySingleDocument(ses, region, accountID, document)
+	// Setup build of script files
+	var buildOptions *document.BuildOptions
+	if c.Bool("build") || c.Bool("upload") {
+		buildOptions = &document.BuildOptions{
+			Upload: c.Bool("upload"),
+			Bucket: c.String("sources-bucket"),
+		}
+	}
 
-				errs <- err
-			}(cy)
+	// Setup snapshot of deployed documents
+	var backupOptions *document.BackupOptions
+	if !c.Bool("no-backup") {
+		backupOptions = &document.BackupOptions{
+			Dir:    c.String("backup-dir"),
+			Bucket: c.String("artifact-bucket"),
 		}
+	}
 
-		// Wait until all remove ends
-		waitGroup.Wait()
+	// Start parallel deploy using the shared pool, dependencies first
+	outcomes, err := documentsGraph.Run(ctx, pool.New(c.Int("parallels"), c.Duration("grace-period")), false, func(ctx context.Context, document *document.Document) error {
+		res := results[document]
+		start := time.Now()
+		defer func() {
+			res.Duration = time.Since(start).Seconds()
+		}()
 
-		// Do dummy wait
-		time.Sleep(2 * time.Second)
+		return deploySingleDocument(ctx, printer, document, res, buildOptions, backupOptions)
+	})
+	if err != nil {
+		return err
 	}
+	documentsReport.Collect(outcomes)
 
-	// Close errors channel
-	close(errs)
+	// Print summary when interrupted, errors otherwise
+	if ctx.Err() != nil {
+		names := []string{}
+		for _, document := range allDocuments {
+			names = append(names, document.Name)
+		}
+		fmt.Fprintln(printer.Progress(), "")
+		pool.PrintSummary(printer.Progress(), names, outcomes)
+	} else {
+		for _, outcome := range outcomes {
+			if outcome.Err != nil {
+				fmt.Fprintln(printer.Progress(), outcome.Err)
+			}
+		}
+	}
 
-	// Check errors
-	var inError int
-	for i := 0; i < len(*documents); i++ {
-		err := <-errs
+	// Remove orphan documents when deploy succeeded
+	inError := pool.Count(outcomes, pool.StateFailed)
+	notStarted := pool.Count(outcomes, pool.StateNotStarted)
+	pruneInError := 0
+	if c.Bool("prune") && inError == 0 && notStarted == 0 {
+		pruneInError, err = prune.Prune(ctx, c, printer, ses, *accountID, loadedDocuments, documentsReport)
 		if err != nil {
-			inError++
-			fmt.Println(err)
+			return err
 		}
 	}
+
+	// Write reports
+	err = report.WriteAll(c.StringSlice("report"), documentsReport)
+	if err != nil {
+		return err
+	}
+
+	// Print structured results
+	err = printer.Print(documentsReport)
+	if err != nil {
+		return err
+	}
+
+	// Check errors
+	if notStarted > 0 {
+		return fmt.Errorf("Interrupted, %d of %d document fail deploy and %d never started", inError, len(allDocuments), notStarted)
+	}
 	if inError > 0 {
-		return fmt.Errorf("%d of %d document fail deploy", inError, len(*documents))
+		return fmt.Errorf("%d of %d document fail deploy", inError, len(allDocuments))
+	}
+	if pruneInError > 0 {
+		return fmt.Errorf("%d orphan documents fail remove", pruneInError)
 	}
 
 	return nil
 }
 
-func deploySingleDocument(ses *session.Session, region *string, accountID *string, document *document.Document) error {
-	var err error
+func deploySingleDocument(ctx context.Context, printer *output.Printer, document *document.Document, res *document.Result, buildOptions *document.BuildOptions, backupOptions *document.BackupOptions) error {
+	// Check document configuration
+	err := document.Validate()
+	if err != nil {
+		return err
+	}
+
+	// Build script files
+	if buildOptions != nil {
+		printer.Progressf("[%s] Building..", document.Name)
+		err = document.Build(ctx, *buildOptions)
+		if err != nil {
+			return err
+		}
+	}
+
+	isAlreadyDeployed := document.IsDeployed(ctx)
+
+	// Check if deploy will overwrite content
+	isContentChanged := false
+	if isAlreadyDeployed && backupOptions != nil {
+		isContentChanged, err = document.IsContentChanged(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Snapshot deployed document, a refused update must not write a backup
+	if backupOptions != nil && isContentChanged {
+		err = document.CheckOwnership(ctx)
+		if err != nil {
+			return err
+		}
 
-	isAlreadyDeployed := document.IsDeployed()
+		printer.Progressf("[%s] Backing up..", document.Name)
+		res.Backup, err = document.Backup(ctx, *backupOptions)
+		if err != nil {
+			return err
+		}
+		printer.Progressf("[%s] Backup written to %s", document.Name, res.Backup)
+	}
 
 	// Deploy document
 	if !isAlreadyDeployed {
-		fmt.Println(fmt.Sprintf("[%s] Creating..", document.Name))
+		printer.Progressf("[%s] Creating..", document.Name)
 	} else {
-		fmt.Println(fmt.Sprintf("[%s] Updating..", document.Name))
+		printer.Progressf("[%s] Updating..", document.Name)
 	}
-	err = document.Deploy()
+	err = document.Deploy(ctx, res)
 	if err != nil {
 		return err
 	}
 
 	// Update tags
 	if isAlreadyDeployed {
-		fmt.Println(fmt.Sprintf("[%s] Updating tags..", document.Name))
-		err = document.UpdateTags()
+		printer.Progressf("[%s] Updating tags..", document.Name)
+		err = document.UpdateTags(ctx, res)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Reconcile associations
+	if document.Associations != nil {
+		printer.Progressf("[%s] Updating associations..", document.Name)
+		err = document.DeployAssociations(ctx, res)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Reconcile maintenance window tasks
+	if document.MaintenanceWindows != nil {
+		printer.Progressf("[%s] Updating maintenance window tasks..", document.Name)
+		err = document.DeployMaintenanceWindows(ctx, res)
 		if err != nil {
 			return err
 		}
 	}
 
-	fmt.Println(fmt.Sprintf("[%s] Deploy completed!", document.Name))
+	printer.Progressf("[%s] Deploy completed!", document.Name)
 	return nil
 }
@@ -0,0 +1,172 @@
+package exec
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/daaru00/aws-ssm-document-cli/internal/aws"
+	"github.com/daaru00/aws-ssm-document-cli/internal/config"
+	"github.com/daaru00/aws-ssm-document-cli/internal/local"
+	"github.com/daaru00/aws-ssm-document-cli/internal/output"
+	"github.com/daaru00/aws-ssm-document-cli/internal/pool"
+	"github.com/urfave/cli/v2"
+)
+
+// Result of a local document execution
+type Result struct {
+	Name    string             `yaml:"name" json:"name"`
+	Status  string             `yaml:"status" json:"status"`
+	Sandbox string             `yaml:"sandbox,omitempty" json:"sandbox,omitempty"`
+	Steps   []local.StepResult `yaml:"steps" json:"steps"`
+	Error   string             `yaml:"error,omitempty" json:"error,omitempty"`
+}
+
+// NewCommand - Return exec-local commands
+func NewCommand(globalFlags []cli.Flag) *cli.Command {
+	return &cli.Command{
+		Name:    "exec-local",
+		Aliases: []string{"run-local"},
+		Usage:   "Execute Shell documents locally",
+		Flags: append(append(globalFlags, config.NewFilterFlags()...), []cli.Flag{
+			&cli.StringSliceFlag{
+				Name:    "param",
+				Aliases: []string{"P"},
+				Usage:   "Override parameter value, format is Name=Value",
+			},
+			&cli.StringSliceFlag{
+				Name:    "env",
+				Aliases: []string{"e"},
+				Usage:   "Set environment variable, format is KEY=VALUE",
+			},
+			&cli.StringFlag{
+				Name:  "shell",
+				Usage: "Shell used to execute scripts",
+				Value: "sh",
+			},
+			&cli.BoolFlag{
+				Name:  "no-sandbox",
+				Usage: "Run steps in the real working directory instead of a throwaway sandbox directory",
+			},
+			&cli.BoolFlag{
+				Name:  "keep-sandbox",
+				Usage: "Do not delete the sandbox directory after execution",
+			},
+			&cli.BoolFlag{
+				Name:    "all",
+				Aliases: []string{"a"},
+				Usage:   "Select all documents",
+			},
+		}...),
+		Action:    Action,
+		ArgsUsage: "[path...]",
+	}
+}
+
+// Action contain the command flow
+func Action(c *cli.Context) error {
+	// Setup output printer
+	printer, err := output.NewPrinter(c)
+	if err != nil {
+		return err
+	}
+
+	// Parse parameters
+	params, err := local.ParseParams(c.StringSlice("param"))
+	if err != nil {
+		return err
+	}
+
+	// Create AWS session
+	ses := aws.NewAwsSession(c)
+
+	// Get documents
+	documents, err := config.LoadDocuments(c, ses)
+	if err != nil {
+		return err
+	}
+
+	// Ask documents selection
+	documents, err = config.AskMultipleDocumentsSelection(c, *documents)
+	if err != nil {
+		return err
+	}
+
+	// Stop execution on interrupt
+	ctx, stop := pool.NotifyContext(printer.Progress())
+	defer stop()
+
+	// Setup runner
+	runner := local.NewRunner()
+	runner.Sandbox = !c.Bool("no-sandbox")
+	runner.KeepSandbox = c.Bool("keep-sandbox")
+	runner.Shell = c.String("shell")
+	runner.Env = c.StringSlice("env")
+
+	// Execute documents one by one
+	results := []*Result{}
+	inError := 0
+	for _, document := range *documents {
+		result := &Result{
+			Name:   document.Name,
+			Status: local.StatusSuccess,
+			Steps:  []local.StepResult{},
+		}
+		results = append(results, result)
+
+		printer.Progressf("[%s] Executing locally..", document.Name)
+		steps, err := local.Prepare(document, params)
+		if err == nil {
+			result.Steps, result.Sandbox, err = runner.Run(ctx, steps)
+		}
+		if err != nil {
+			result.Status = local.StatusFailed
+			result.Error = err.Error()
+			inError++
+			printer.Progressf("%s", err)
+			continue
+		}
+
+		// Print steps results
+		for _, step := range result.Steps {
+			printer.Progressf("[%s] [%s] %s (exit code %d)", document.Name, step.Step, step.Status, step.ExitCode)
+			printOutput(printer, "stdout", step.Stdout)
+			printOutput(printer, "stderr", step.Stderr)
+
+			if step.Status != local.StatusSuccess && result.Status == local.StatusSuccess {
+				result.Status = step.Status
+			}
+		}
+		if runner.KeepSandbox && len(result.Sandbox) > 0 {
+			printer.Progressf("[%s] Sandbox directory %s", document.Name, result.Sandbox)
+		}
+		if result.Status != local.StatusSuccess {
+			inError++
+		}
+		printer.Progressf("[%s] Execution completed with status %s", document.Name, result.Status)
+	}
+
+	// Print structured results
+	err = printer.Print(results)
+	if err != nil {
+		return err
+	}
+
+	if inError > 0 {
+		return fmt.Errorf("%d of %d documents fail local execution", inError, len(results))
+	}
+
+	return nil
+}
+
+// printOutput print step output indented
+func printOutput(printer *output.Printer, name string, content string) {
+	content = strings.TrimRight(content, "\n")
+	if len(content) == 0 {
+		return
+	}
+
+	printer.Progressf("  %s:", name)
+	for _, line := range strings.Split(content, "\n") {
+		printer.Progressf("    %s", line)
+	}
+}
@@ -0,0 +1,60 @@
+package graph
+
+import (
+	"fmt"
+
+	"github.com/daaru00/aws-ssm-document-cli/internal/aws"
+	"github.com/daaru00/aws-ssm-document-cli/internal/config"
+	"github.com/daaru00/aws-ssm-document-cli/internal/graph"
+	"github.com/daaru00/aws-ssm-document-cli/internal/output"
+	"github.com/urfave/cli/v2"
+)
+
+// NewCommand - Return graph commands
+func NewCommand(globalFlags []cli.Flag) *cli.Command {
+	return &cli.Command{
+		Name:      "graph",
+		Usage:     "Print SSM Documents dependency graph in DOT format",
+		Flags:     globalFlags,
+		Action:    Action,
+		ArgsUsage: "[path...]",
+	}
+}
+
+// Action contain the command flow
+func Action(c *cli.Context) error {
+	// Setup output printer
+	printer, err := output.NewPrinter(c)
+	if err != nil {
+		return err
+	}
+
+	// Create AWS session
+	ses := aws.NewAwsSession(c)
+
+	// Get documents
+	documents, err := config.LoadDocuments(c, ses)
+	if err != nil {
+		return err
+	}
+
+	// Build documents dependency graph
+	documentsGraph, err := graph.New(*documents)
+	if err != nil {
+		return err
+	}
+
+	// Check for cycles
+	_, err = documentsGraph.Waves()
+	if err != nil {
+		return err
+	}
+
+	// Print graph nodes for structured output
+	if printer.IsStructured() {
+		return printer.Print(documentsGraph.Nodes())
+	}
+
+	fmt.Print(documentsGraph.DOT())
+	return nil
+}
@@ -0,0 +1,77 @@
+package mock
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/daaru00/aws-ssm-document-cli/internal/pool"
+	"github.com/daaru00/aws-ssm-document-cli/pkg/ssmfake"
+	"github.com/urfave/cli/v2"
+)
+
+// NewCommand - Return serve-mock commands
+func NewCommand(globalFlags []cli.Flag) *cli.Command {
+	return &cli.Command{
+		Name:  "serve-mock",
+		Usage: "Start a local SSM compatible server for offline testing",
+		Flags: append(globalFlags, []cli.Flag{
+			&cli.StringFlag{
+				Name:    "listen",
+				Aliases: []string{"l"},
+				Usage:   "Address to listen on",
+				Value:   "127.0.0.1:4583",
+				EnvVars: []string{"SSM_DOCUMENT_MOCK_LISTEN"},
+			},
+			&cli.StringFlag{
+				Name:    "state-file",
+				Usage:   "File where state is persisted, if not set state is kept in memory",
+				EnvVars: []string{"SSM_DOCUMENT_MOCK_STATE_FILE"},
+			},
+			&cli.StringFlag{
+				Name:  "account-id",
+				Usage: "Account ID returned to callers",
+				Value: "123456789012",
+			},
+		}...),
+		Action: Action,
+	}
+}
+
+// Action contain the command flow
+func Action(c *cli.Context) error {
+	// Setup fake backend
+	fake := ssmfake.New()
+	fake.AccountID = c.String("account-id")
+	if len(c.String("region")) > 0 {
+		fake.Region = c.String("region")
+	}
+
+	server, err := ssmfake.NewServer(fake, c.String("state-file"))
+	if err != nil {
+		return err
+	}
+
+	// Stop server on interrupt
+	ctx, stop := pool.NotifyContext(os.Stderr)
+	defer stop()
+
+	httpServer := &http.Server{
+		Addr:    c.String("listen"),
+		Handler: server,
+	}
+	go func() {
+		<-ctx.Done()
+		httpServer.Shutdown(context.Background())
+	}()
+
+	fmt.Printf("Mock server listening on http://%s\n", httpServer.Addr)
+	fmt.Printf("Use it with: --endpoint-url http://%s or AWS_ENDPOINT_URL=http://%s\n", httpServer.Addr, httpServer.Addr)
+	err = httpServer.ListenAndServe()
+	if err != nil && err != http.ErrServerClosed {
+		return err
+	}
+
+	return nil
+}
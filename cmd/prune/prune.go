@@ -0,0 +1,272 @@
+package prune
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/daaru00/aws-ssm-document-cli/internal/aws"
+	"github.com/daaru00/aws-ssm-document-cli/internal/config"
+	"github.com/daaru00/aws-ssm-document-cli/internal/document"
+	"github.com/daaru00/aws-ssm-document-cli/internal/output"
+	"github.com/daaru00/aws-ssm-document-cli/internal/pool"
+	"github.com/daaru00/aws-ssm-document-cli/internal/report"
+	"github.com/urfave/cli/v2"
+)
+
+// NewCommand - Return prune commands
+func NewCommand(globalFlags []cli.Flag) *cli.Command {
+	return &cli.Command{
+		Name:  "prune",
+		Usage: "Remove SSM Documents of the project no more found locally",
+		Flags: append(globalFlags, []cli.Flag{
+			&cli.BoolFlag{
+				Name:    "yes",
+				Aliases: []string{"y"},
+				Usage:   "Answer yes for all confirmations",
+			},
+			&cli.BoolFlag{
+				Name:  "dry-run",
+				Usage: "Only list orphan documents without removing them",
+			},
+			&cli.StringFlag{
+				Name:    "backup-dir",
+				Usage:   "Directory where snapshots of documents are written before removing them, ignored when artifact bucket is set",
+				Value:   ".backups",
+				EnvVars: []string{"SSM_DOCUMENT_BACKUP_DIR"},
+			},
+			&cli.StringFlag{
+				Name:    "artifact-bucket",
+				Usage:   "The Artifact bucket name, snapshots of documents are uploaded here",
+				EnvVars: []string{"SSM_DOCUMENT_ARTIFACT_BUCKET", "SSM_DOCUMENT_ARTIFACT_BUCKET_NAME"},
+			},
+			&cli.BoolFlag{
+				Name:  "no-backup",
+				Usage: "Do not snapshot documents before removing them",
+			},
+			&cli.BoolFlag{
+				Name:  "force-protected",
+				Usage: "Remove also protected orphan documents",
+			},
+			&cli.DurationFlag{
+				Name:  "grace-period",
+				Usage: "Time given to running removes to complete after an interrupt",
+				Value: pool.DefaultGracePeriod,
+			},
+			&cli.StringSliceFlag{
+				Name:    "report",
+				Usage:   "Write a report, format is type=path where type is \"junit\" or \"markdown\"",
+				EnvVars: []string{"SSM_DOCUMENT_REPORT"},
+			},
+			&cli.IntFlag{
+				Name:  "parallels",
+				Usage: "Set max remove executed in parallel",
+				Value: 5,
+			},
+		}...),
+		Action:    Action,
+		ArgsUsage: "[path...]",
+	}
+}
+
+// Action contain the command flow
+func Action(c *cli.Context) error {
+	// Ownership marker requires an explicit project
+	_, err := config.GetProject(c)
+	if err != nil {
+		return err
+	}
+
+	// Setup output printer
+	printer, err := output.NewPrinter(c)
+	if err != nil {
+		return err
+	}
+
+	// Create AWS session
+	ses := aws.NewAwsSession(c)
+
+	// Get caller infos
+	accountID := aws.GetCallerAccountID(ses)
+	region := aws.GetCallerRegion(ses)
+	if accountID == nil {
+		return errors.New("No valid AWS credentials found")
+	}
+
+	// Get documents
+	documents, err := config.LoadDocuments(c, ses)
+	if err != nil {
+		return err
+	}
+
+	// Stop removing documents on interrupt
+	ctx, stop := pool.NotifyContext(printer.Progress())
+	defer stop()
+
+	// Remove orphan documents
+	documentsReport := output.NewReport("prune", accountID, region, []*document.Document{})
+	inError, err := Prune(ctx, c, printer, ses, *accountID, *documents, documentsReport)
+	if err != nil {
+		return err
+	}
+
+	// Write reports
+	err = report.WriteAll(c.StringSlice("report"), documentsReport)
+	if err != nil {
+		return err
+	}
+
+	// Print structured results
+	err = printer.Print(documentsReport)
+	if err != nil {
+		return err
+	}
+
+	if inError > 0 {
+		return fmt.Errorf("%d of %d orphan documents fail remove", inError, len(documentsReport.Results))
+	}
+
+	return nil
+}
+
+// Prune remove the project documents not found in the locally loaded documents, after a preview and confirmation.
+// Results are appended to documentsReport, return the number of documents that fail remove.
+func Prune(ctx context.Context, c *cli.Context, printer *output.Printer, ses *session.Session, accountID string, documents []*document.Document, documentsReport *output.Report) (int, error) {
+	// Refuse to consider every deployed document an orphan
+	project, err := config.GetProject(c)
+	if err != nil {
+		return 0, err
+	}
+	if len(documents) == 0 {
+		return 0, fmt.Errorf("No local documents found, refusing to prune all documents of project %s", project)
+	}
+
+	// Search orphan documents
+	printer.Progressf("Searching orphan documents of project %s..", project)
+	orphans, err := document.FindOrphans(ctx, ssm.New(ses), project, documents)
+	if err != nil {
+		return 0, err
+	}
+	if len(orphans) == 0 {
+		printer.Progressf("No orphan documents found")
+		return 0, nil
+	}
+
+	// Preview orphan documents
+	results := []*document.Result{}
+	orphanDocuments := []*document.Document{}
+	for _, name := range orphans {
+		orphan := document.New(ses, name)
+		orphan.Project = project
+		orphanDocuments = append(orphanDocuments, orphan)
+
+		res := orphan.NewResult()
+		res.Action = document.ActionOrphan
+		results = append(results, res)
+		printer.Progressf("[%s] Deployed but not found locally", name)
+	}
+	documentsReport.Results = append(documentsReport.Results, results...)
+
+	// Protect orphans using project protection rules
+	config.ApplyProtection(c, accountID, orphanDocuments)
+	if c.Bool("dry-run") {
+		return 0, nil
+	}
+
+	// Ask confirmation
+	err = askConfirmation(c, printer, fmt.Sprintf("Are you sure you want to remove %d orphan documents?", len(orphans)))
+	if err != nil {
+		return 0, err
+	}
+
+	// Start parallel remove using the shared pool, orphans have no dependencies graph
+	workers := pool.New(c.Int("parallels"), c.Duration("grace-period"))
+	outcomes := workers.Run(ctx, len(orphanDocuments), func(ctx context.Context, index int) error {
+		orphan := orphanDocuments[index]
+		res := results[index]
+		start := time.Now()
+		defer func() {
+			res.Duration = time.Since(start).Seconds()
+		}()
+
+		err := removeOrphan(ctx, c, printer, orphan, res)
+		if err != nil {
+			return err
+		}
+		printer.Progressf("[%s] Remove completed!", orphan.Name)
+		return nil
+	})
+	orphansReport := &output.Report{Results: results}
+	orphansReport.Collect(outcomes)
+
+	// Print summary when interrupted, errors otherwise
+	if ctx.Err() != nil {
+		fmt.Fprintln(printer.Progress(), "")
+		pool.PrintSummary(printer.Progress(), orphans, outcomes)
+	} else {
+		for _, outcome := range outcomes {
+			if outcome.Err != nil {
+				fmt.Fprintln(printer.Progress(), outcome.Err)
+			}
+		}
+	}
+
+	return orphansReport.Failed(), nil
+}
+
+func removeOrphan(ctx context.Context, c *cli.Context, printer *output.Printer, orphan *document.Document, res *document.Result) error {
+	// Check document protection and ownership before taking the snapshot
+	err := orphan.CheckProtection("remove")
+	if err != nil {
+		return err
+	}
+	err = orphan.CheckOwnership(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Snapshot deployed document
+	if !c.Bool("no-backup") {
+		printer.Progressf("[%s] Backing up..", orphan.Name)
+		res.Backup, err = orphan.Backup(ctx, document.BackupOptions{
+			Dir:    c.String("backup-dir"),
+			Bucket: c.String("artifact-bucket"),
+		})
+		if err != nil {
+			return err
+		}
+		printer.Progressf("[%s] Backup written to %s", orphan.Name, res.Backup)
+	}
+
+	// Remove document
+	printer.Progressf("[%s] Removing..", orphan.Name)
+	return orphan.Remove(ctx, res)
+}
+
+func askConfirmation(c *cli.Context, printer *output.Printer, message string) error {
+	// Check yes flag
+	if c.Bool("yes") {
+		return nil
+	}
+
+	// Ask confirmation
+	confirm := false
+	prompt := &survey.Confirm{
+		Message: message,
+	}
+	err := printer.Ask(prompt, &confirm)
+	if err != nil {
+		return fmt.Errorf("Cannot ask confirmation: %s, use --yes to confirm", err)
+	}
+
+	// Check respose
+	if confirm == false {
+		return errors.New("Not confirmed prune, skip operation")
+	}
+
+	return nil
+}
@@ -0,0 +1,117 @@
+package prune
+
+import (
+	"context"
+	"flag"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/daaru00/aws-ssm-document-cli/internal/document"
+	"github.com/daaru00/aws-ssm-document-cli/internal/output"
+	"github.com/daaru00/aws-ssm-document-cli/pkg/ssmfake"
+	"github.com/urfave/cli/v2"
+)
+
+func newTestDocument(fake *ssmfake.Fake, name string) *document.Document {
+	d := document.NewWithClient(fake, aws.String("us-east-1"), name)
+	d.Project = "demo"
+	d.Content = document.Content{
+		SchemaVersion: "2.2",
+		Description:   "Test document",
+		MainSteps: []document.MainStep{
+			{
+				Action: "aws:runShellScript",
+				Name:   "run",
+				Inputs: document.ShellInput{RunCommand: []string{"echo 1"}},
+			},
+		},
+	}
+	return d
+}
+
+func TestPruneRemoveManagedResources(t *testing.T) {
+	fake := ssmfake.New()
+	ctx := context.Background()
+
+	// Deploy a document with an association and a window task, then drop its configuration
+	orphan := newTestDocument(fake, "Orphan")
+	orphan.Associations = []document.Association{
+		{Name: "nightly", Targets: []document.Target{{Key: "InstanceIds", Values: document.StringList{"*"}}}},
+	}
+	orphan.MaintenanceWindows = []document.MaintenanceWindowTask{
+		{
+			Window:         "nightly",
+			MaxConcurrency: "1",
+			MaxErrors:      "0",
+			Targets:        []document.Target{{Key: "WindowTargetIds", Values: document.StringList{"abc"}}},
+			Create:         true,
+			Schedule:       "cron(0 2 ? * * *)",
+			Duration:       2,
+		},
+	}
+	res := orphan.NewResult()
+	err := orphan.Deploy(ctx, res)
+	if err == nil {
+		err = orphan.DeployAssociations(ctx, res)
+	}
+	if err == nil {
+		err = orphan.DeployMaintenanceWindows(ctx, res)
+	}
+	if err != nil {
+		t.Fatalf("unexpected deploy error: %s", err)
+	}
+	kept := newTestDocument(fake, "Kept")
+	err = kept.Deploy(ctx, kept.NewResult())
+	if err != nil {
+		t.Fatalf("unexpected deploy error: %s", err)
+	}
+
+	// Serve the fake to the session based command
+	server, err := ssmfake.NewServer(fake, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+	ses := session.Must(session.NewSession(&aws.Config{
+		Region:      aws.String("us-east-1"),
+		Endpoint:    aws.String(httpServer.URL),
+		Credentials: credentials.NewStaticCredentials("id", "secret", ""),
+		MaxRetries:  aws.Int(0),
+	}))
+
+	set := flag.NewFlagSet("test", flag.ContinueOnError)
+	set.String("project", "demo", "")
+	set.Bool("yes", true, "")
+	set.Bool("no-backup", true, "")
+	set.Int("parallels", 2, "")
+	set.Duration("grace-period", time.Second, "")
+	c := cli.NewContext(nil, set, nil)
+	printer, err := output.NewPrinter(c)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	report := output.NewReport("prune", aws.String(fake.AccountID), aws.String("us-east-1"), []*document.Document{})
+	inError, err := Prune(ctx, c, printer, ses, fake.AccountID, []*document.Document{kept}, report)
+	if err != nil || inError != 0 {
+		t.Fatalf("unexpected prune result: %d %v", inError, err)
+	}
+
+	if len(report.Results) != 1 || report.Results[0].Action != document.ActionRemoved {
+		t.Fatalf("unexpected results: %+v", report.Results)
+	}
+	if fake.Document("Orphan") != nil || fake.Document("Kept") == nil {
+		t.Error("only the orphan document should be removed")
+	}
+	if fake.Association(document.ManagedNamePrefix+"nightly") != nil {
+		t.Error("orphan association should be deleted")
+	}
+	if window := fake.MaintenanceWindow("nightly"); window == nil || len(window.Tasks) != 0 {
+		t.Errorf("orphan window task should be deregistered, window kept: %+v", window)
+	}
+}
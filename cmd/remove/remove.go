@@ -1,16 +1,20 @@
 package remove
 
 import (
+	"context"
 	"errors"
 	"fmt"
-	"sync"
+	"strings"
 	"time"
 
 	"github.com/AlecAivazis/survey/v2"
-	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/daaru00/aws-ssm-document-cli/internal/aws"
 	"github.com/daaru00/aws-ssm-document-cli/internal/config"
 	"github.com/daaru00/aws-ssm-document-cli/internal/document"
+	"github.com/daaru00/aws-ssm-document-cli/internal/graph"
+	"github.com/daaru00/aws-ssm-document-cli/internal/output"
+	"github.com/daaru00/aws-ssm-document-cli/internal/pool"
+	"github.com/daaru00/aws-ssm-document-cli/internal/report"
 	"github.com/urfave/cli/v2"
 )
 
@@ -20,7 +24,7 @@ func NewCommand(globalFlags []cli.Flag) *cli.Command {
 		Name:    "remove",
 		Aliases: []string{"delete", "down"},
 		Usage:   "Remove SSM Documents",
-		Flags: append(globalFlags, []cli.Flag{
+		Flags: append(append(globalFlags, config.NewFilterFlags()...), []cli.Flag{
 			&cli.StringFlag{
 				Name:    "artifact-bucket",
 				Usage:   "The Artifact bucket name",
@@ -39,6 +43,28 @@ func NewCommand(globalFlags []cli.Flag) *cli.Command {
 				Name:  "delete-sources-bucket",
 				Usage: "Remove also source bucket",
 			},
+			&cli.StringFlag{
+				Name:    "backup-dir",
+				Usage:   "Directory where snapshots of documents are written before removing them, ignored when artifact bucket is set",
+				Value:   ".backups",
+				EnvVars: []string{"SSM_DOCUMENT_BACKUP_DIR"},
+			},
+			&cli.BoolFlag{
+				Name:  "no-backup",
+				Usage: "Do not snapshot documents before removing them",
+			},
+			&cli.BoolFlag{
+				Name:  "keep-shared",
+				Usage: "Only remove share permissions of shared documents, without deleting them",
+			},
+			&cli.BoolFlag{
+				Name:  "force-protected",
+				Usage: "Remove also protected documents",
+			},
+			&cli.BoolFlag{
+				Name:  "adopt",
+				Usage: "Remove also documents not deployed by this project",
+			},
 			&cli.BoolFlag{
 				Name:    "yes",
 				Aliases: []string{"y"},
@@ -49,6 +75,16 @@ func NewCommand(globalFlags []cli.Flag) *cli.Command {
 				Aliases: []string{"a"},
 				Usage:   "Select all documents",
 			},
+			&cli.DurationFlag{
+				Name:  "grace-period",
+				Usage: "Time given to running removes to complete after an interrupt",
+				Value: pool.DefaultGracePeriod,
+			},
+			&cli.StringSliceFlag{
+				Name:    "report",
+				Usage:   "Write a report, format is type=path where type is \"junit\" or \"markdown\"",
+				EnvVars: []string{"SSM_DOCUMENT_REPORT"},
+			},
 			&cli.IntFlag{
 				Name:  "parallels",
 				Usage: "Set max remove executed in parallel",
@@ -62,7 +98,17 @@ func NewCommand(globalFlags []cli.Flag) *cli.Command {
 
 // Action contain the command flow
 func Action(c *cli.Context) error {
-	var err error
+	// Ownership marker requires an explicit project
+	_, err := config.GetProject(c)
+	if err != nil {
+		return err
+	}
+
+	// Setup output printer
+	printer, err := output.NewPrinter(c)
+	if err != nil {
+		return err
+	}
 
 	// Create AWS session
 	ses := aws.NewAwsSession(c)
@@ -81,95 +127,159 @@ func Action(c *cli.Context) error {
 	}
 
 	// Ask documents selection
-	documents, err = config.AskMultipleDocumentsSelection(c, *documents)
+	documents, err = config.AskMultipleDocumentsSelectionWithState(c, *documents)
 	if err != nil {
 		return err
 	}
 
+	// Refuse to remove protected documents
+	config.ApplyProtection(c, *accountID, *documents)
+	protected := config.GetProtectedDocuments(*documents)
+	if len(protected) > 0 {
+		names := []string{}
+		for _, document := range protected {
+			names = append(names, document.Name)
+		}
+		return fmt.Errorf("Documents %s are protected, use --force-protected to remove them", strings.Join(names, ", "))
+	}
+
 	// Ask confirmation
-	err = askConfirmation(c, fmt.Sprintf("Are you sure you want to remove %d documents?", len(*documents)))
+	err = askConfirmation(c, printer, fmt.Sprintf("Are you sure you want to remove %d documents?", len(*documents)))
 	if err != nil {
 		return err
 	}
 
-	// Setup errors channel
-	errs := make(chan error, len(*documents))
+	// Setup ownership check
+	for _, document := range *documents {
+		document.Adopt = c.Bool("adopt")
+		document.KeepShared = c.Bool("keep-shared")
+	}
+
+	// Stop starting new documents on interrupt
+	ctx, stop := pool.NotifyContext(printer.Progress())
+	defer stop()
 
-	// Setup max parallels
+	// Build documents dependency graph
 	allDocuments := *documents
-	parallels := c.Int("parallels")
-
-	// Start parallel deploy
-	for start := 0; start < len(allDocuments); start += parallels {
-		// Update chunk start and end
-		end := start + parallels
-		if end > len(allDocuments) {
-			end = len(allDocuments)
-		}
-
-		// Split array chunk
-		chunk := allDocuments[start:end]
+	documentsGraph, err := graph.New(allDocuments)
+	if err != nil {
+		return err
+	}
 
-		// Setup wait group for async jobs
-		var waitGroup sync.WaitGroup
+	// Prepare results indexed as documents
+	documentsReport := output.NewReport("remove", accountID, region, allDocuments)
+	results := map[*document.Document]*document.Result{}
+	for index, document := range allDocuments {
+		results[document] = documentsReport.Results[index]
+	}
 
-		// Loop over found document
-		for _, cy := range chunk {
+	// Setup snapshot of deployed documents
+	var backupOptions *document.BackupOptions
+	if !c.Bool("no-backup") {
+		backupOptions = &document.BackupOptions{
+			Dir:    c.String("backup-dir"),
+			Bucket: c.String("artifact-bucket"),
+		}
+	}
 
-			// Execute parallel deploy
-			waitGroup.Add(1)
-			go func(document *document.Document) {
-				defer waitGroup.Done()
+	// Start parallel remove using the shared pool, dependents first
+	outcomes, err := documentsGraph.Run(ctx, pool.New(c.Int("parallels"), c.Duration("grace-period")), true, func(ctx context.Context, document *document.Document) error {
+		res := results[document]
+		start := time.Now()
+		defer func() {
+			res.Duration = time.Since(start).Seconds()
+		}()
 
-				err := removeSingleDocument(ses, document, region)
+		return removeSingleDocument(ctx, printer, document, res, backupOptions)
+	})
+	if err != nil {
+		return err
+	}
+	documentsReport.Collect(outcomes)
 
-				errs <- err
-			}(cy)
+	// Print summary when interrupted, errors otherwise
+	if ctx.Err() != nil {
+		names := []string{}
+		for _, document := range allDocuments {
+			names = append(names, document.Name)
 		}
+		fmt.Fprintln(printer.Progress(), "")
+		pool.PrintSummary(printer.Progress(), names, outcomes)
+	} else {
+		for _, outcome := range outcomes {
+			if outcome.Err != nil {
+				fmt.Fprintln(printer.Progress(), outcome.Err)
+			}
+		}
+	}
 
-		// Wait until all remove ends
-		waitGroup.Wait()
-
-		// Do dummy wait
-		time.Sleep(2 * time.Second)
+	// Write reports
+	err = report.WriteAll(c.StringSlice("report"), documentsReport)
+	if err != nil {
+		return err
 	}
 
-	// Close errors channel
-	close(errs)
+	// Print structured results
+	err = printer.Print(documentsReport)
+	if err != nil {
+		return err
+	}
 
 	// Check errors
-	var inError int
-	for i := 0; i < len(*documents); i++ {
-		err := <-errs
-		if err != nil {
-			inError++
-			fmt.Println(err)
-		}
+	inError := pool.Count(outcomes, pool.StateFailed)
+	notStarted := pool.Count(outcomes, pool.StateNotStarted)
+	if notStarted > 0 {
+		return fmt.Errorf("Interrupted, %d of %d documents fail remove and %d never started", inError, len(allDocuments), notStarted)
 	}
 	if inError > 0 {
-		return fmt.Errorf("%d of %d documents fail remove", inError, len(*documents))
+		return fmt.Errorf("%d of %d documents fail remove", inError, len(allDocuments))
 	}
 
 	return nil
 }
 
-func removeSingleDocument(ses *session.Session, document *document.Document, region *string) error {
+func removeSingleDocument(ctx context.Context, printer *output.Printer, doc *document.Document, res *document.Result, backupOptions *document.BackupOptions) error {
 	var err error
 
-	if document.IsDeployed() {
+	if doc.IsDeployed(ctx) {
+		// Snapshot deployed document, a refused remove must not write a backup
+		if backupOptions != nil {
+			err = doc.CheckProtection("remove")
+			if err != nil {
+				return err
+			}
+			err = doc.CheckOwnership(ctx)
+			if err != nil {
+				return err
+			}
+
+			printer.Progressf("[%s] Backing up..", doc.Name)
+			res.Backup, err = doc.Backup(ctx, *backupOptions)
+			if err != nil {
+				return err
+			}
+			printer.Progressf("[%s] Backup written to %s", doc.Name, res.Backup)
+		}
+
 		// Remove document
-		fmt.Println(fmt.Sprintf("[%s] Removing..", document.Name))
-		err = document.Remove()
+		printer.Progressf("[%s] Removing..", doc.Name)
+		err = doc.Remove(ctx, res)
 		if err != nil {
 			return err
 		}
+	} else {
+		res.Action = document.ActionNotDeployed
 	}
 
-	fmt.Println(fmt.Sprintf("[%s] Remove completed!", document.Name))
+	if res.Action == document.ActionUnshared {
+		printer.Progressf("[%s] Unshared from %d accounts, document kept", doc.Name, len(res.AccountsRemoved))
+		return nil
+	}
+	printer.Progressf("[%s] Remove completed!", doc.Name)
 	return nil
 }
 
-func askConfirmation(c *cli.Context, message string) error {
+func askConfirmation(c *cli.Context, printer *output.Printer, message string) error {
 	// Check yes flag
 	if c.Bool("yes") {
 		return nil
@@ -180,7 +290,10 @@ func askConfirmation(c *cli.Context, message string) error {
 	prompt := &survey.Confirm{
 		Message: message,
 	}
-	survey.AskOne(prompt, &confirm)
+	err := printer.Ask(prompt, &confirm)
+	if err != nil {
+		return fmt.Errorf("Cannot ask confirmation: %s, use --yes to confirm", err)
+	}
 
 	// Check respose
 	if confirm == false {
@@ -0,0 +1,195 @@
+package restore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/daaru00/aws-ssm-document-cli/internal/aws"
+	"github.com/daaru00/aws-ssm-document-cli/internal/document"
+	"github.com/daaru00/aws-ssm-document-cli/internal/output"
+	"github.com/daaru00/aws-ssm-document-cli/internal/pool"
+	"github.com/daaru00/aws-ssm-document-cli/internal/report"
+	"github.com/urfave/cli/v2"
+)
+
+// NewCommand - Return restore commands
+func NewCommand(globalFlags []cli.Flag) *cli.Command {
+	return &cli.Command{
+		Name:  "restore",
+		Usage: "Re-create SSM Documents from backup snapshots",
+		Flags: append(globalFlags, []cli.Flag{
+			&cli.StringFlag{
+				Name:  "name",
+				Usage: "Restore the document with a different name, only with a single snapshot",
+			},
+			&cli.BoolFlag{
+				Name:    "yes",
+				Aliases: []string{"y"},
+				Usage:   "Answer yes for all confirmations",
+			},
+			&cli.DurationFlag{
+				Name:  "grace-period",
+				Usage: "Time given to running restores to complete after an interrupt",
+				Value: pool.DefaultGracePeriod,
+			},
+			&cli.StringSliceFlag{
+				Name:    "report",
+				Usage:   "Write a report, format is type=path where type is \"junit\" or \"markdown\"",
+				EnvVars: []string{"SSM_DOCUMENT_REPORT"},
+			},
+			&cli.IntFlag{
+				Name:  "parallels",
+				Usage: "Set max restore executed in parallel",
+				Value: 5,
+			},
+		}...),
+		Action:    Action,
+		ArgsUsage: "<snapshot...>",
+	}
+}
+
+// Action contain the command flow
+func Action(c *cli.Context) error {
+	// Check arguments
+	if c.Args().Len() == 0 {
+		return errors.New("No snapshot provided, use a local path or an s3://bucket/key location")
+	}
+	if len(c.String("name")) > 0 && c.Args().Len() > 1 {
+		return errors.New("Flag --name can be used only with a single snapshot")
+	}
+
+	// Setup output printer
+	printer, err := output.NewPrinter(c)
+	if err != nil {
+		return err
+	}
+
+	// Create AWS session
+	ses := aws.NewAwsSession(c)
+
+	// Get caller infos
+	accountID := aws.GetCallerAccountID(ses)
+	region := aws.GetCallerRegion(ses)
+	if accountID == nil {
+		return errors.New("No valid AWS credentials found")
+	}
+
+	// Stop restoring documents on interrupt
+	ctx, stop := pool.NotifyContext(printer.Progress())
+	defer stop()
+
+	// Read snapshots
+	snapshots := []*document.Snapshot{}
+	documents := []*document.Document{}
+	for _, location := range c.Args().Slice() {
+		snapshot, err := document.LoadSnapshot(ctx, s3.New(ses), location)
+		if err != nil {
+			return fmt.Errorf("Cannot read snapshot %s: %s", location, err)
+		}
+
+		name := snapshot.Name
+		if len(c.String("name")) > 0 {
+			name = c.String("name")
+		}
+		doc := document.New(ses, name)
+
+		snapshots = append(snapshots, snapshot)
+		documents = append(documents, doc)
+		printer.Progressf("[%s] %d versions from snapshot of %s", name, len(snapshot.Versions), snapshot.Date.Format(time.RFC3339))
+	}
+
+	// Ask confirmation
+	err = askConfirmation(c, printer, fmt.Sprintf("Are you sure you want to restore %d documents?", len(documents)))
+	if err != nil {
+		return err
+	}
+
+	// Start parallel restore using the shared pool
+	documentsReport := output.NewReport("restore", accountID, region, documents)
+	workers := pool.New(c.Int("parallels"), c.Duration("grace-period"))
+	outcomes := workers.Run(ctx, len(documents), func(ctx context.Context, index int) error {
+		doc := documents[index]
+		res := documentsReport.Results[index]
+		start := time.Now()
+		defer func() {
+			res.Duration = time.Since(start).Seconds()
+		}()
+
+		printer.Progressf("[%s] Restoring..", doc.Name)
+		err := doc.Restore(ctx, snapshots[index], res)
+		if err != nil {
+			return err
+		}
+		printer.Progressf("[%s] Restore completed!", doc.Name)
+		return nil
+	})
+	documentsReport.Collect(outcomes)
+
+	// Print summary when interrupted, errors otherwise
+	if ctx.Err() != nil {
+		names := []string{}
+		for _, doc := range documents {
+			names = append(names, doc.Name)
+		}
+		fmt.Fprintln(printer.Progress(), "")
+		pool.PrintSummary(printer.Progress(), names, outcomes)
+	} else {
+		for _, outcome := range outcomes {
+			if outcome.Err != nil {
+				fmt.Fprintln(printer.Progress(), outcome.Err)
+			}
+		}
+	}
+
+	// Write reports
+	err = report.WriteAll(c.StringSlice("report"), documentsReport)
+	if err != nil {
+		return err
+	}
+
+	// Print structured results
+	err = printer.Print(documentsReport)
+	if err != nil {
+		return err
+	}
+
+	// Check errors
+	inError := pool.Count(outcomes, pool.StateFailed)
+	notStarted := pool.Count(outcomes, pool.StateNotStarted)
+	if notStarted > 0 {
+		return fmt.Errorf("Interrupted, %d of %d documents fail restore and %d never started", inError, len(documents), notStarted)
+	}
+	if inError > 0 {
+		return fmt.Errorf("%d of %d documents fail restore", inError, len(documents))
+	}
+
+	return nil
+}
+
+func askConfirmation(c *cli.Context, printer *output.Printer, message string) error {
+	// Check yes flag
+	if c.Bool("yes") {
+		return nil
+	}
+
+	// Ask confirmation
+	confirm := false
+	prompt := &survey.Confirm{
+		Message: message,
+	}
+	err := printer.Ask(prompt, &confirm)
+	if err != nil {
+		return fmt.Errorf("Cannot ask confirmation: %s, use --yes to confirm", err)
+	}
+
+	// Check respose
+	if confirm == false {
+		return errors.New("Not confirmed documents restore, skip operation")
+	}
+
+	return nil
+}
@@ -0,0 +1,289 @@
+package review
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/daaru00/aws-ssm-document-cli/internal/aws"
+	"github.com/daaru00/aws-ssm-document-cli/internal/config"
+	"github.com/daaru00/aws-ssm-document-cli/internal/document"
+	"github.com/daaru00/aws-ssm-document-cli/internal/output"
+	"github.com/daaru00/aws-ssm-document-cli/internal/pool"
+	"github.com/daaru00/aws-ssm-document-cli/internal/report"
+	"github.com/urfave/cli/v2"
+)
+
+// NewReviewsCommand - Return reviews commands
+func NewReviewsCommand(globalFlags []cli.Flag) *cli.Command {
+	return &cli.Command{
+		Name:      "reviews",
+		Usage:     "List SSM Documents versions pending review",
+		Flags:     globalFlags,
+		Action:    ListAction,
+		ArgsUsage: "[path...]",
+	}
+}
+
+// NewApproveCommand - Return approve commands
+func NewApproveCommand(globalFlags []cli.Flag) *cli.Command {
+	return &cli.Command{
+		Name:  "approve",
+		Usage: "Approve SSM Documents versions pending review and set them as default",
+		Flags: append(globalFlags, reviewFlags()...),
+		Action: func(c *cli.Context) error {
+			return reviewAction(c, "approve", document.ActionApproved)
+		},
+		ArgsUsage: "[path...]",
+	}
+}
+
+// NewRejectCommand - Return reject commands
+func NewRejectCommand(globalFlags []cli.Flag) *cli.Command {
+	return &cli.Command{
+		Name:  "reject",
+		Usage: "Reject SSM Documents versions pending review",
+		Flags: append(globalFlags, reviewFlags()...),
+		Action: func(c *cli.Context) error {
+			return reviewAction(c, "reject", document.ActionRejected)
+		},
+		ArgsUsage: "[path...]",
+	}
+}
+
+// reviewFlags return flags shared by approve and reject commands
+func reviewFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:    "comment",
+			Aliases: []string{"m"},
+			Usage:   "Review comment",
+		},
+		&cli.BoolFlag{
+			Name:    "yes",
+			Aliases: []string{"y"},
+			Usage:   "Answer yes for all confirmations",
+		},
+		&cli.BoolFlag{
+			Name:    "all",
+			Aliases: []string{"a"},
+			Usage:   "Select all documents",
+		},
+		&cli.DurationFlag{
+			Name:  "grace-period",
+			Usage: "Time given to running reviews to complete after an interrupt",
+			Value: pool.DefaultGracePeriod,
+		},
+		&cli.StringSliceFlag{
+			Name:    "report",
+			Usage:   "Write a report, format is type=path where type is \"junit\" or \"markdown\"",
+			EnvVars: []string{"SSM_DOCUMENT_REPORT"},
+		},
+		&cli.IntFlag{
+			Name:  "parallels",
+			Usage: "Set max review executed in parallel",
+			Value: 5,
+		},
+	}
+}
+
+// ListAction contain the reviews command flow
+func ListAction(c *cli.Context) error {
+	// Setup output printer
+	printer, err := output.NewPrinter(c)
+	if err != nil {
+		return err
+	}
+
+	// Create AWS session
+	ses := aws.NewAwsSession(c)
+
+	// Get documents
+	documents, err := config.LoadDocuments(c, ses)
+	if err != nil {
+		return err
+	}
+
+	// Load reviews
+	reviews, _, err := loadPendingReviews(c.Context, *documents)
+	if err != nil {
+		return err
+	}
+
+	// Print reviews table
+	if len(reviews) == 0 {
+		printer.Progressf("No documents pending review")
+	} else {
+		printer.Progressf("%-40s %-10s %-10s %s", "Name", "Default", "Pending", "Comment")
+		for _, review := range reviews {
+			printer.Progressf("%-40s %-10s %-10s %s", review.Name, review.DefaultVersion, review.PendingVersion, review.Comment)
+		}
+	}
+
+	// Print structured results
+	return printer.Print(reviews)
+}
+
+// reviewAction approve or reject the selected documents pending review
+func reviewAction(c *cli.Context, verb string, action string) error {
+	// Setup output printer
+	printer, err := output.NewPrinter(c)
+	if err != nil {
+		return err
+	}
+
+	// Create AWS session
+	ses := aws.NewAwsSession(c)
+
+	// Get caller infos
+	accountID := aws.GetCallerAccountID(ses)
+	region := aws.GetCallerRegion(ses)
+	if accountID == nil {
+		return errors.New("No valid AWS credentials found")
+	}
+
+	// Get caller identity, a version cannot be approved by its author
+	callerArn := aws.GetCallerArn(ses)
+	if callerArn == nil {
+		return errors.New("No valid AWS credentials found")
+	}
+
+	// Get documents
+	documents, err := config.LoadDocuments(c, ses)
+	if err != nil {
+		return err
+	}
+
+	// Keep only documents pending review
+	_, pending, err := loadPendingReviews(c.Context, *documents)
+	if err != nil {
+		return err
+	}
+	if len(pending) == 0 {
+		return errors.New("No documents pending review")
+	}
+
+	// Ask documents selection
+	selected, err := config.AskMultipleDocumentsSelection(c, pending)
+	if err != nil {
+		return err
+	}
+
+	// Ask confirmation
+	err = askConfirmation(c, printer, fmt.Sprintf("Are you sure you want to %s %d documents?", verb, len(*selected)))
+	if err != nil {
+		return err
+	}
+
+	// Stop starting new reviews on interrupt
+	ctx, stop := pool.NotifyContext(printer.Progress())
+	defer stop()
+
+	// Execute review action using the shared pool
+	documentsReport := output.NewReport(verb, accountID, region, *selected)
+	workers := pool.New(c.Int("parallels"), c.Duration("grace-period"))
+	outcomes := workers.Run(ctx, len(*selected), func(ctx context.Context, index int) error {
+		doc := (*selected)[index]
+		res := documentsReport.Results[index]
+
+		printer.Progressf("[%s] Executing %s..", doc.Name, verb)
+		var version string
+		var err error
+		if action == document.ActionApproved {
+			version, err = doc.Approve(ctx, *callerArn, c.String("comment"))
+		} else {
+			version, err = doc.Reject(ctx, c.String("comment"))
+		}
+		res.Version = version
+		if err != nil {
+			printer.Progressf("[%s] %s", doc.Name, err)
+			return err
+		}
+		res.Action = action
+		printer.Progressf("[%s] Version %s %s!", doc.Name, version, action)
+		return nil
+	})
+	documentsReport.Collect(outcomes)
+
+	// Print summary when interrupted
+	if ctx.Err() != nil {
+		names := []string{}
+		for _, doc := range *selected {
+			names = append(names, doc.Name)
+		}
+		fmt.Fprintln(printer.Progress(), "")
+		pool.PrintSummary(printer.Progress(), names, outcomes)
+	}
+
+	// Write reports
+	err = report.WriteAll(c.StringSlice("report"), documentsReport)
+	if err != nil {
+		return err
+	}
+
+	// Print structured results
+	err = printer.Print(documentsReport)
+	if err != nil {
+		return err
+	}
+
+	// Check errors
+	inError := pool.Count(outcomes, pool.StateFailed)
+	notStarted := pool.Count(outcomes, pool.StateNotStarted)
+	if notStarted > 0 {
+		return fmt.Errorf("Interrupted, %d of %d documents fail %s and %d never started", inError, len(*selected), verb, notStarted)
+	}
+	if inError > 0 {
+		return fmt.Errorf("%d of %d document fail %s", inError, len(*selected), verb)
+	}
+
+	return nil
+}
+
+// loadPendingReviews return review state of documents with a version pending review
+func loadPendingReviews(ctx context.Context, documents []*document.Document) ([]*document.Review, []*document.Document, error) {
+	reviews := []*document.Review{}
+	pending := []*document.Document{}
+
+	for _, doc := range documents {
+		if !doc.IsDeployed(ctx) {
+			continue
+		}
+
+		review, err := doc.GetReview(ctx)
+		if err != nil {
+			return nil, nil, fmt.Errorf("[%s] %s", doc.Name, err)
+		}
+		if review.IsPending() {
+			reviews = append(reviews, review)
+			pending = append(pending, doc)
+		}
+	}
+
+	return reviews, pending, nil
+}
+
+func askConfirmation(c *cli.Context, printer *output.Printer, message string) error {
+	// Check yes flag
+	if c.Bool("yes") {
+		return nil
+	}
+
+	// Ask confirmation
+	confirm := false
+	prompt := &survey.Confirm{
+		Message: message,
+	}
+	err := printer.Ask(prompt, &confirm)
+	if err != nil {
+		return fmt.Errorf("Cannot ask confirmation: %s, use --yes to confirm", err)
+	}
+
+	// Check respose
+	if confirm == false {
+		return errors.New("Not confirmed review, skip operation")
+	}
+
+	return nil
+}
@@ -0,0 +1,169 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/daaru00/aws-ssm-document-cli/internal/aws"
+	"github.com/daaru00/aws-ssm-document-cli/internal/config"
+	"github.com/daaru00/aws-ssm-document-cli/internal/document"
+	"github.com/daaru00/aws-ssm-document-cli/internal/local"
+	"github.com/daaru00/aws-ssm-document-cli/internal/output"
+	"github.com/daaru00/aws-ssm-document-cli/internal/pool"
+	"github.com/daaru00/aws-ssm-document-cli/internal/report"
+	"github.com/urfave/cli/v2"
+)
+
+// NewCommand - Return test commands
+func NewCommand(globalFlags []cli.Flag) *cli.Command {
+	return &cli.Command{
+		Name:  "test",
+		Usage: "Run declarative test cases of Shell documents locally",
+		Flags: append(append(globalFlags, config.NewFilterFlags()...), []cli.Flag{
+			&cli.StringFlag{
+				Name:  "shell",
+				Usage: "Shell used to execute scripts",
+				Value: "sh",
+			},
+			&cli.BoolFlag{
+				Name:    "all",
+				Aliases: []string{"a"},
+				Usage:   "Select all documents",
+			},
+			&cli.DurationFlag{
+				Name:  "grace-period",
+				Usage: "Time given to running test cases to complete after an interrupt",
+				Value: pool.DefaultGracePeriod,
+			},
+			&cli.StringSliceFlag{
+				Name:    "report",
+				Usage:   "Write a report, format is type=path where type is \"junit\"",
+				EnvVars: []string{"SSM_DOCUMENT_REPORT"},
+			},
+			&cli.IntFlag{
+				Name:  "parallels",
+				Usage: "Set max test cases executed in parallel",
+				Value: 5,
+			},
+		}...),
+		Action:    Action,
+		ArgsUsage: "[path...]",
+	}
+}
+
+// Action contain the command flow
+func Action(c *cli.Context) error {
+	// Setup output printer
+	printer, err := output.NewPrinter(c)
+	if err != nil {
+		return err
+	}
+
+	// Create AWS session
+	ses := aws.NewAwsSession(c)
+
+	// Get documents
+	documents, err := config.LoadDocuments(c, ses)
+	if err != nil {
+		return err
+	}
+
+	// Keep only documents with test cases
+	testable := []*document.Document{}
+	for _, doc := range *documents {
+		if len(doc.Tests) > 0 {
+			testable = append(testable, doc)
+		}
+	}
+	if len(testable) == 0 {
+		return errors.New("No documents with test cases found")
+	}
+
+	// Ask documents selection
+	documents, err = config.AskMultipleDocumentsSelection(c, testable)
+	if err != nil {
+		return err
+	}
+
+	// Collect test cases
+	type testJob struct {
+		document *document.Document
+		testCase document.TestCase
+	}
+	jobs := []testJob{}
+	for _, doc := range *documents {
+		for _, testCase := range doc.Tests {
+			jobs = append(jobs, testJob{doc, testCase})
+		}
+	}
+
+	// Stop execution on interrupt
+	ctx, stop := pool.NotifyContext(printer.Progress())
+	defer stop()
+
+	// Run test cases in parallel
+	results := make([]*local.TestResult, len(jobs))
+	shell := c.String("shell")
+	workers := pool.New(c.Int("parallels"), c.Duration("grace-period"))
+	outcomes := workers.Run(ctx, len(jobs), func(ctx context.Context, index int) error {
+		job := jobs[index]
+		printer.Progressf("[%s] Running test %s..", job.document.Name, job.testCase.Name)
+
+		result, err := local.RunTest(ctx, job.document, job.testCase, shell)
+		results[index] = result
+		if err != nil {
+			result.Failures = append(result.Failures, err.Error())
+			printer.Progressf("[%s] Test %s error: %s", job.document.Name, job.testCase.Name, err)
+			return err
+		}
+
+		if result.Passed {
+			printer.Progressf("[%s] Test %s passed", job.document.Name, job.testCase.Name)
+			return nil
+		}
+		printer.Progressf("[%s] Test %s failed:", job.document.Name, job.testCase.Name)
+		for _, failure := range result.Failures {
+			printer.Progressf("  %s", failure)
+		}
+		return fmt.Errorf("[%s] Test %s failed", job.document.Name, job.testCase.Name)
+	})
+
+	// Mark not started test cases
+	for index, outcome := range outcomes {
+		if outcome.State == pool.StateNotStarted {
+			results[index] = &local.TestResult{
+				Document: jobs[index].document.Name,
+				Name:     jobs[index].testCase.Name,
+				Failures: []string{"not started"},
+				Steps:    []local.StepResult{},
+			}
+		}
+	}
+
+	// Write reports
+	err = report.WriteTestsAll(c.StringSlice("report"), results)
+	if err != nil {
+		return err
+	}
+
+	// Print structured results
+	err = printer.Print(results)
+	if err != nil {
+		return err
+	}
+
+	// Check failures
+	failed := 0
+	for _, result := range results {
+		if !result.Passed {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d test cases failed", failed, len(results))
+	}
+	printer.Progressf("%d test cases passed", len(results))
+
+	return nil
+}
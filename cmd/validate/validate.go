@@ -0,0 +1,114 @@
+package validate
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/daaru00/aws-ssm-document-cli/internal/aws"
+	"github.com/daaru00/aws-ssm-document-cli/internal/config"
+	"github.com/daaru00/aws-ssm-document-cli/internal/document"
+	"github.com/daaru00/aws-ssm-document-cli/internal/graph"
+	"github.com/daaru00/aws-ssm-document-cli/internal/output"
+	"github.com/daaru00/aws-ssm-document-cli/internal/pool"
+	"github.com/daaru00/aws-ssm-document-cli/internal/report"
+	"github.com/urfave/cli/v2"
+)
+
+// NewCommand - Return validate commands
+func NewCommand(globalFlags []cli.Flag) *cli.Command {
+	return &cli.Command{
+		Name:  "validate",
+		Usage: "Validate SSM Documents without deploying them",
+		Flags: append(globalFlags, []cli.Flag{
+			&cli.StringSliceFlag{
+				Name:    "report",
+				Usage:   "Write a report, format is type=path where type is \"junit\" or \"markdown\"",
+				EnvVars: []string{"SSM_DOCUMENT_REPORT"},
+			},
+		}...),
+		Action:    Action,
+		ArgsUsage: "[path...]",
+	}
+}
+
+// Action contain the command flow
+func Action(c *cli.Context) error {
+	// Setup output printer
+	printer, err := output.NewPrinter(c)
+	if err != nil {
+		return err
+	}
+
+	// Create AWS session
+	ses := aws.NewAwsSession(c)
+
+	// Get documents
+	documents, err := config.LoadDocuments(c, ses)
+	if err != nil {
+		return err
+	}
+	allDocuments := *documents
+
+	// Validate each document
+	documentsReport := output.NewReport("validate", nil, aws.GetCallerRegion(ses), allDocuments)
+	outcomes := make([]pool.Outcome, len(allDocuments))
+	for index, doc := range allDocuments {
+		start := time.Now()
+		err := validateSingleDocument(printer, doc)
+		documentsReport.Results[index].Duration = time.Since(start).Seconds()
+		if err != nil {
+			outcomes[index] = pool.Outcome{State: pool.StateFailed, Err: err}
+		} else {
+			outcomes[index] = pool.Outcome{State: pool.StateCompleted}
+			documentsReport.Results[index].Action = document.ActionValidated
+		}
+	}
+	documentsReport.Collect(outcomes)
+
+	// Check dependency cycles
+	documentsGraph, err := graph.New(allDocuments)
+	if err == nil {
+		_, err = documentsGraph.Waves()
+	}
+	if err != nil {
+		return err
+	}
+
+	// Print errors
+	for _, outcome := range outcomes {
+		if outcome.Err != nil {
+			fmt.Fprintln(printer.Progress(), outcome.Err)
+		}
+	}
+
+	// Write reports
+	err = report.WriteAll(c.StringSlice("report"), documentsReport)
+	if err != nil {
+		return err
+	}
+
+	// Print structured results
+	err = printer.Print(documentsReport)
+	if err != nil {
+		return err
+	}
+
+	// Check errors
+	inError := pool.Count(outcomes, pool.StateFailed)
+	if inError > 0 {
+		return fmt.Errorf("%d of %d documents fail validation", inError, len(allDocuments))
+	}
+
+	return nil
+}
+
+func validateSingleDocument(printer *output.Printer, document *document.Document) error {
+	printer.Progressf("[%s] Validating..", document.Name)
+	err := document.Validate()
+	if err != nil {
+		return err
+	}
+
+	printer.Progressf("[%s] Validation completed!", document.Name)
+	return nil
+}
@@ -0,0 +1,4 @@
+def script_handler(events, context):
+    message = events["Message"]
+    print(message)
+    return {"message": message}
@@ -1,3 +1,4 @@
 #!/bin/bash
+# @param Message String "Example parameter" default="Hello World"
 
 echo "{{ Message }}"
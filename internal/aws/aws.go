@@ -4,8 +4,11 @@ import (
 	"os"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/endpoints"
 	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ssm"
 	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/daaru00/aws-ssm-document-cli/internal/pool"
 	"github.com/urfave/cli/v2"
 )
 
@@ -26,21 +29,62 @@ func NewAwsSession(c *cli.Context) *session.Session {
 		awsConfig.LogLevel = aws.LogLevel(aws.LogDebugWithHTTPBody)
 	}
 
-	// Return a new session
-	return session.Must(session.NewSessionWithOptions(session.Options{
+	// Override SSM and STS endpoints
+	endpointURL := c.String("endpoint-url")
+	if len(endpointURL) != 0 {
+		awsConfig.EndpointResolver = newEndpointResolver(endpointURL)
+	}
+
+	// Setup shared rate limiter and throttling aware retryer
+	limiter := pool.NewLimiter(c.Float64("api-rate-limit"))
+	awsConfig.Retryer = pool.NewRetryer(limiter, c.Int("api-max-retries"))
+
+	// Create a new session
+	ses := session.Must(session.NewSessionWithOptions(session.Options{
 		Profile:           profile,
 		SharedConfigState: session.SharedConfigEnable,
 		Config:            awsConfig,
 	}))
+
+	// Every client created from session share the same limiter
+	pool.Attach(&ses.Handlers, limiter)
+
+	return ses
+}
+
+// newEndpointResolver return a resolver that use endpointURL for SSM and STS clients
+func newEndpointResolver(endpointURL string) endpoints.Resolver {
+	return endpoints.ResolverFunc(func(service, region string, opts ...func(*endpoints.Options)) (endpoints.ResolvedEndpoint, error) {
+		if service == ssm.EndpointsID || service == sts.EndpointsID {
+			return endpoints.ResolvedEndpoint{
+				URL:           endpointURL,
+				SigningRegion: region,
+			}, nil
+		}
+		return endpoints.DefaultResolver().EndpointFor(service, region, opts...)
+	})
 }
 
 // GetCallerAccountID return the account number
 func GetCallerAccountID(ses *session.Session) *string {
 	stsClient := sts.New(ses)
-	identity, _ := stsClient.GetCallerIdentity(&sts.GetCallerIdentityInput{})
+	identity, err := stsClient.GetCallerIdentity(&sts.GetCallerIdentityInput{})
+	if err != nil {
+		return nil
+	}
 	return identity.Account
 }
 
+// GetCallerArn return the ARN of the caller identity
+func GetCallerArn(ses *session.Session) *string {
+	stsClient := sts.New(ses)
+	identity, err := stsClient.GetCallerIdentity(&sts.GetCallerIdentityInput{})
+	if err != nil {
+		return nil
+	}
+	return identity.Arn
+}
+
 // GetCallerRegion return the account number
 func GetCallerRegion(ses *session.Session) *string {
 	return ses.Config.Region
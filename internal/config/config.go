@@ -1,8 +1,11 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"os"
+
+	"github.com/urfave/cli/v2"
 )
 
 // InterpolateContent interpolate variables from current environment
@@ -37,3 +40,12 @@ func ParseContent(content *string, parser *string, destination interface{}) erro
 
 	return nil
 }
+
+// GetProject return the project identifier, it must be set explicitly since it marks the ownership of deployed documents
+func GetProject(c *cli.Context) (string, error) {
+	project := c.String("project")
+	if len(project) == 0 {
+		return "", errors.New("Project is required, set it with --project, SSM_DOCUMENT_PROJECT environment variable or in the .env file")
+	}
+	return project, nil
+}
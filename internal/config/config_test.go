@@ -0,0 +1,29 @@
+package config
+
+import (
+	"flag"
+	"testing"
+
+	"github.com/urfave/cli/v2"
+)
+
+func TestGetProject(t *testing.T) {
+	set := flag.NewFlagSet("test", flag.ContinueOnError)
+	set.String("project", "", "")
+	c := cli.NewContext(nil, set, nil)
+
+	// Current directory name is not used as project
+	_, err := GetProject(c)
+	if err == nil {
+		t.Error("expected missing project error")
+	}
+
+	err = set.Set("project", "my-project")
+	if err != nil {
+		t.Fatal(err)
+	}
+	project, err := GetProject(c)
+	if err != nil || project != "my-project" {
+		t.Errorf("unexpected project %q, error: %v", project, err)
+	}
+}
@@ -0,0 +1,192 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/daaru00/aws-ssm-document-cli/internal/document"
+	"github.com/urfave/cli/v2"
+)
+
+// NewFilterFlags return the flags to select documents without prompt
+func NewFilterFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringSliceFlag{
+			Name:  "name",
+			Usage: "Select documents with name matching the pattern, for example \"Patch-*\"",
+		},
+		&cli.StringSliceFlag{
+			Name:  "tag",
+			Usage: "Select documents with the tag, format is KEY=VALUE or KEY",
+		},
+		&cli.StringSliceFlag{
+			Name:  "type",
+			Usage: "Select documents of the type, for example \"Automation\"",
+		},
+		&cli.StringSliceFlag{
+			Name:  "exclude",
+			Usage: "Do not search paths matching the pattern, with the .ssmdocignore syntax",
+		},
+		&cli.StringFlag{
+			Name:  "changed-since",
+			Usage: "Select documents with source files changed since the git reference, for example \"origin/main\"",
+		},
+	}
+}
+
+// HasFilters check if any selection filter is provided, excluded paths are not loaded so exclude is not a filter
+func HasFilters(c *cli.Context) bool {
+	return len(c.StringSlice("name")) > 0 ||
+		len(c.StringSlice("tag")) > 0 ||
+		len(c.StringSlice("type")) > 0 ||
+		len(c.String("changed-since")) > 0
+}
+
+// FilterDocuments return the documents matching all provided filters
+func FilterDocuments(c *cli.Context, documents []*document.Document) ([]*document.Document, error) {
+	// Validate patterns
+	for _, pattern := range c.StringSlice("name") {
+		_, err := path.Match(pattern, "")
+		if err != nil {
+			return nil, fmt.Errorf("Invalid pattern %s: %s", pattern, err)
+		}
+	}
+
+	// Search changed files
+	var changedFiles map[string]bool
+	if ref := c.String("changed-since"); len(ref) > 0 {
+		var err error
+		changedFiles, err = GetChangedFiles(ref)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	filtered := []*document.Document{}
+	for _, document := range documents {
+		if !matchAny(c.StringSlice("name"), document.Name) {
+			continue
+		}
+		if !matchTags(c.StringSlice("tag"), document.Tags) {
+			continue
+		}
+		if !matchType(c.StringSlice("type"), document.Type) {
+			continue
+		}
+		if changedFiles != nil && !isChanged(changedFiles, document) {
+			continue
+		}
+		filtered = append(filtered, document)
+	}
+
+	return filtered, nil
+}
+
+// GetChangedFiles return the absolute paths of files changed since the git reference,
+// including uncommitted and untracked files
+func GetChangedFiles(ref string) (map[string]bool, error) {
+	root, err := runGit("rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, err
+	}
+	root = strings.TrimSpace(root)
+
+	diff, err := runGit("diff", "--name-only", ref, "--")
+	if err != nil {
+		return nil, err
+	}
+	untracked, err := runGit("ls-files", "--others", "--exclude-standard", "--full-name", root)
+	if err != nil {
+		return nil, err
+	}
+
+	files := map[string]bool{}
+	for _, file := range strings.Split(diff+"\n"+untracked, "\n") {
+		file = strings.TrimSpace(file)
+		if len(file) > 0 {
+			files[filepath.Join(root, filepath.FromSlash(file))] = true
+		}
+	}
+	return files, nil
+}
+
+// runGit execute a git command and return its output
+func runGit(args ...string) (string, error) {
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	cmd := exec.Command("git", args...)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	err := cmd.Run()
+	if err != nil {
+		return "", fmt.Errorf("git %s failed: %s", strings.Join(args, " "), strings.TrimSpace(stderr.String()+" "+err.Error()))
+	}
+	return stdout.String(), nil
+}
+
+// matchAny check if value match any pattern, no patterns match everything
+func matchAny(patterns []string, value string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, pattern := range patterns {
+		if match, _ := path.Match(pattern, value); match {
+			return true
+		}
+	}
+	return false
+}
+
+// matchTags check if tags contain all the KEY=VALUE or KEY filters
+func matchTags(filters []string, tags map[string]string) bool {
+	for _, filter := range filters {
+		parts := strings.SplitN(filter, "=", 2)
+		value, ok := tags[parts[0]]
+		if !ok {
+			return false
+		}
+		if len(parts) == 2 && value != parts[1] {
+			return false
+		}
+	}
+	return true
+}
+
+// matchType check if the document type is one of types, case insensitive
+func matchType(types []string, documentType string) bool {
+	if len(types) == 0 {
+		return true
+	}
+	for _, filter := range types {
+		if strings.EqualFold(filter, documentType) {
+			return true
+		}
+	}
+	return false
+}
+
+// isChanged check if any source file of the document is in changed files
+func isChanged(changedFiles map[string]bool, d *document.Document) bool {
+	sources := d.GetSourceFiles()
+	if len(d.ConfigFile) > 0 {
+		sources = append(sources, TestFilePath(d.ConfigFile))
+	}
+	for _, source := range sources {
+		absolute, err := filepath.Abs(source)
+		if err != nil {
+			continue
+		}
+		if changedFiles[absolute] {
+			return true
+		}
+		// Resolve symlinks, git report paths from the real repository root
+		if resolved, err := filepath.EvalSymlinks(absolute); err == nil && changedFiles[resolved] {
+			return true
+		}
+	}
+	return false
+}
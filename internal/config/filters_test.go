@@ -0,0 +1,147 @@
+package config
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/daaru00/aws-ssm-document-cli/internal/document"
+	"github.com/urfave/cli/v2"
+)
+
+func newFilterContext(t *testing.T, args ...string) *cli.Context {
+	t.Helper()
+
+	set := flag.NewFlagSet("test", flag.ContinueOnError)
+	for _, f := range NewFilterFlags() {
+		err := f.Apply(set)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	err := set.Parse(args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cli.NewContext(nil, set, nil)
+}
+
+func names(documents []*document.Document) []string {
+	result := []string{}
+	for _, d := range documents {
+		result = append(result, d.Name)
+	}
+	return result
+}
+
+func testDocuments() []*document.Document {
+	return []*document.Document{
+		{Name: "Patch-Linux", Type: "Command", Tags: map[string]string{"Team": "ops"}, ConfigFile: "linux/document.yml"},
+		{Name: "Patch-Windows", Type: "Command", Tags: map[string]string{"Team": "win"}, ConfigFile: "windows/document.yml"},
+		{Name: "Restart", Type: "Automation", Tags: map[string]string{"Team": "ops", "Env": "prod"}, ConfigFile: "examples/restart/document.yml"},
+	}
+}
+
+func TestFilterDocuments(t *testing.T) {
+	cases := []struct {
+		args     []string
+		expected []string
+	}{
+		{[]string{"--name", "Patch-*"}, []string{"Patch-Linux", "Patch-Windows"}},
+		{[]string{"--tag", "Team=ops"}, []string{"Patch-Linux", "Restart"}},
+		{[]string{"--tag", "Team=ops", "--tag", "Env"}, []string{"Restart"}},
+		{[]string{"--type", "automation"}, []string{"Restart"}},
+		{[]string{"--name", "Patch-*", "--tag", "Team=ops"}, []string{"Patch-Linux"}},
+	}
+
+	for _, tc := range cases {
+		c := newFilterContext(t, tc.args...)
+		if !HasFilters(c) {
+			t.Errorf("expected filters for %v", tc.args)
+		}
+		filtered, err := FilterDocuments(c, testDocuments())
+		if err != nil {
+			t.Fatalf("unexpected error for %v: %s", tc.args, err)
+		}
+		if got := names(filtered); !reflect.DeepEqual(got, tc.expected) {
+			t.Errorf("filters %v: expected %v, got %v", tc.args, tc.expected, got)
+		}
+	}
+}
+
+func TestHasFiltersExclude(t *testing.T) {
+	// Excluded paths are skipped walking search paths, documents are not filtered again
+	c := newFilterContext(t, "--exclude", "examples/")
+	if HasFilters(c) {
+		t.Error("exclude should not be a selection filter")
+	}
+}
+
+func TestFilterDocumentsInvalidPattern(t *testing.T) {
+	_, err := FilterDocuments(newFilterContext(t, "--name", "[invalid"), testDocuments())
+	if err == nil {
+		t.Error("expected invalid pattern error")
+	}
+}
+
+func TestFilterDocumentsChangedSince(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	dir := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", dir, "-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %s %s", args, err, out)
+		}
+	}
+	write := func(file string, content string) {
+		t.Helper()
+		file = filepath.Join(dir, file)
+		os.MkdirAll(filepath.Dir(file), 0755)
+		err := ioutil.WriteFile(file, []byte(content), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write("a/document.yml", "name: A")
+	write("b/document.yml", "name: B")
+	write("b/script.sh", "echo 1")
+	write("c/document.yml", "name: C")
+	git("init", "-q")
+	git("add", "-A")
+	git("commit", "-q", "-m", "init")
+	write("b/script.sh", "echo 2")
+	write("c/document.test.yml", "tests: []")
+
+	documents := []*document.Document{
+		{Name: "A", Dir: filepath.Join(dir, "a"), ConfigFile: filepath.Join(dir, "a", "document.yml")},
+		{Name: "B", Dir: filepath.Join(dir, "b"), ConfigFile: filepath.Join(dir, "b", "document.yml"), File: filepath.Join(dir, "b", "script.sh")},
+		{Name: "C", Dir: filepath.Join(dir, "c"), ConfigFile: filepath.Join(dir, "c", "document.yml")},
+	}
+
+	cwd, _ := os.Getwd()
+	defer os.Chdir(cwd)
+	os.Chdir(dir)
+
+	filtered, err := FilterDocuments(newFilterContext(t, "--changed-since", "HEAD"), documents)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if got := names(filtered); !reflect.DeepEqual(got, []string{"B", "C"}) {
+		t.Errorf("expected changed documents B and C, got %v", got)
+	}
+
+	_, err = FilterDocuments(newFilterContext(t, "--changed-since", "unknown-ref"), documents)
+	if err == nil {
+		t.Error("expected error for unknown reference")
+	}
+}
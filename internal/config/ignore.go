@@ -0,0 +1,204 @@
+package config
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// IgnoreFileName is the name of files that list paths skipped searching documents, with gitignore syntax
+const IgnoreFileName = ".ssmdocignore"
+
+// DefaultIgnores are the directories skipped searching documents, can be re-included with a negated pattern
+var DefaultIgnores = []string{
+	".git",
+	".hg",
+	".svn",
+	"node_modules",
+	"vendor",
+	"bower_components",
+	".terraform",
+	".serverless",
+	".aws-sam",
+	".venv",
+	"venv",
+	"__pycache__",
+	".backups",
+}
+
+// ignoreRule is a single pattern of an ignore file, rules without base match paths in any directory
+type ignoreRule struct {
+	base     string
+	regexp   *regexp.Regexp
+	negate   bool
+	dirOnly  bool
+	anchored bool
+}
+
+// Ignorer match paths against ignore rules. Rules are evaluated in order, default ignores,
+// ignore files from the outer to the inner directory and exclude patterns, the last matching rule win.
+type Ignorer struct {
+	defaults []ignoreRule
+	files    []ignoreRule
+	excludes []ignoreRule
+	loaded   map[string]bool
+}
+
+// NewIgnorer creates a new Ignorer with the default ignores and exclude patterns relative to base directory
+func NewIgnorer(base string, excludes []string) *Ignorer {
+	ignorer := &Ignorer{
+		defaults: parseIgnorePatterns("", DefaultIgnores),
+		excludes: parseIgnorePatterns(base, excludes),
+		loaded:   map[string]bool{},
+	}
+
+	// Exclude patterns without separator match in any search path
+	for index, rule := range ignorer.excludes {
+		if !rule.anchored {
+			ignorer.excludes[index].base = ""
+		}
+	}
+	return ignorer
+}
+
+// AddFile add the patterns of the ignore file in directory, if exist
+func (i *Ignorer) AddFile(dir string) error {
+	if i.loaded == nil {
+		i.loaded = map[string]bool{}
+	}
+	if i.loaded[dir] {
+		return nil
+	}
+	i.loaded[dir] = true
+
+	file, err := os.Open(filepath.Join(dir, IgnoreFileName))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	patterns := []string{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		patterns = append(patterns, scanner.Text())
+	}
+	if err = scanner.Err(); err != nil {
+		return err
+	}
+
+	i.files = append(i.files, parseIgnorePatterns(dir, patterns)...)
+	return nil
+}
+
+// IsIgnored check if the absolute path is ignored, the last matching rule win
+func (i *Ignorer) IsIgnored(path string, isDir bool) bool {
+	ignored := false
+	rules := append(append(append([]ignoreRule{}, i.defaults...), i.files...), i.excludes...)
+	for _, rule := range rules {
+		if rule.dirOnly && !isDir {
+			continue
+		}
+		rel := strings.TrimPrefix(path[len(filepath.VolumeName(path)):], string(filepath.Separator))
+		if len(rule.base) > 0 {
+			var err error
+			rel, err = filepath.Rel(rule.base, path)
+			if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+				continue
+			}
+		}
+		if rule.regexp.MatchString(filepath.ToSlash(rel)) {
+			ignored = !rule.negate
+		}
+	}
+	return ignored
+}
+
+// parseIgnorePatterns convert gitignore patterns into rules relative to base directory
+func parseIgnorePatterns(base string, patterns []string) []ignoreRule {
+	rules := []ignoreRule{}
+	for _, pattern := range patterns {
+		rule, ok := parseIgnorePattern(base, pattern)
+		if ok {
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+// parseIgnorePattern convert a gitignore pattern into a rule, blank lines and comments are skipped
+func parseIgnorePattern(base string, pattern string) (ignoreRule, bool) {
+	rule := ignoreRule{base: base}
+
+	pattern = strings.TrimRight(pattern, " \t\r")
+	if len(pattern) == 0 || strings.HasPrefix(pattern, "#") {
+		return rule, false
+	}
+	if strings.HasPrefix(pattern, "!") {
+		rule.negate = true
+		pattern = pattern[1:]
+	} else if strings.HasPrefix(pattern, "\\") {
+		pattern = pattern[1:]
+	}
+	if strings.HasSuffix(pattern, "/") {
+		rule.dirOnly = true
+		pattern = strings.TrimRight(pattern, "/")
+	}
+	if len(pattern) == 0 {
+		return rule, false
+	}
+
+	// Patterns with a separator are relative to the base directory, otherwise match at any level
+	rule.anchored = strings.Contains(pattern, "/")
+	pattern = strings.TrimPrefix(pattern, "/")
+
+	expression := globToRegexp(pattern)
+	if !rule.anchored {
+		expression = "(.*/)?" + expression
+	}
+	compiled, err := regexp.Compile("^" + expression + "$")
+	if err != nil {
+		return rule, false
+	}
+	rule.regexp = compiled
+	return rule, true
+}
+
+// globToRegexp convert a glob pattern, with ** support, into a regular expression
+func globToRegexp(pattern string) string {
+	builder := strings.Builder{}
+	for index := 0; index < len(pattern); index++ {
+		char := pattern[index]
+		switch {
+		case strings.HasPrefix(pattern[index:], "**/"):
+			builder.WriteString("(.*/)?")
+			index += 2
+		case strings.HasPrefix(pattern[index:], "**"):
+			builder.WriteString(".*")
+			index++
+		case char == '*':
+			builder.WriteString("[^/]*")
+		case char == '?':
+			builder.WriteString("[^/]")
+		case char == '[':
+			end := strings.IndexByte(pattern[index:], ']')
+			if end < 0 {
+				builder.WriteString(regexp.QuoteMeta(string(char)))
+				continue
+			}
+			class := pattern[index+1 : index+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			builder.WriteString("[" + class + "]")
+			index += end
+		default:
+			builder.WriteString(regexp.QuoteMeta(string(char)))
+		}
+	}
+	return builder.String()
+}
@@ -0,0 +1,91 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestIgnorerPatterns(t *testing.T) {
+	base := filepath.FromSlash("/project")
+	ignorer := NewIgnorer(base, []string{"examples/", "/legacy/*.yml"})
+	ignorer.files = parseIgnorePatterns(base, []string{
+		"# comment",
+		"",
+		"build",
+		"docs/**/draft",
+		"*.tmp.yml",
+		"!vendor",
+		"!keep.tmp.yml",
+	})
+
+	cases := []struct {
+		path     string
+		isDir    bool
+		expected bool
+	}{
+		{"/project/node_modules", true, true},
+		{"/project/sub/.git", true, true},
+		{"/other/node_modules", true, true},
+		{"/project/vendor", true, false},
+		{"/project/build", true, true},
+		{"/project/a/build", false, true},
+		{"/project/docs/draft", true, true},
+		{"/project/docs/a/b/draft", true, true},
+		{"/project/a/docs/draft", true, false},
+		{"/project/a/document.tmp.yml", false, true},
+		{"/project/a/keep.tmp.yml", false, false},
+		{"/project/examples", true, true},
+		{"/other/examples", true, true},
+		{"/project/examples", false, false},
+		{"/project/legacy/document.yml", false, true},
+		{"/project/a/legacy/document.yml", false, false},
+		{"/project/documents/document.yml", false, false},
+	}
+	for _, tc := range cases {
+		if got := ignorer.IsIgnored(filepath.FromSlash(tc.path), tc.isDir); got != tc.expected {
+			t.Errorf("path %s: expected ignored %t, got %t", tc.path, tc.expected, got)
+		}
+	}
+}
+
+func TestIgnorerNestedFiles(t *testing.T) {
+	dir := t.TempDir()
+	write := func(file string, content string) {
+		t.Helper()
+		file = filepath.Join(dir, file)
+		os.MkdirAll(filepath.Dir(file), 0755)
+		err := ioutil.WriteFile(file, []byte(content), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(IgnoreFileName, "samples\n")
+	write(filepath.Join("team", IgnoreFileName), "!samples\nlocal\n")
+
+	ignorer := NewIgnorer(dir, []string{"team/samples"})
+	for _, sub := range []string{"", "team"} {
+		err := ignorer.AddFile(filepath.Join(dir, sub))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cases := []struct {
+		path     string
+		expected bool
+	}{
+		{"samples", true},
+		{"other/samples", true},
+		{"team/other/samples", false},
+		{"team/local", true},
+		{"local", false},
+		{"team/samples", true},
+	}
+	for _, tc := range cases {
+		if got := ignorer.IsIgnored(filepath.Join(dir, tc.path), true); got != tc.expected {
+			t.Errorf("path %s: expected ignored %t, got %t", tc.path, tc.expected, got)
+		}
+	}
+}
@@ -1,18 +1,56 @@
 package config
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
 
 	"github.com/AlecAivazis/survey/v2"
 	"github.com/daaru00/aws-ssm-document-cli/internal/document"
+	"github.com/daaru00/aws-ssm-document-cli/internal/output"
+	"github.com/daaru00/aws-ssm-document-cli/internal/pool"
 	"github.com/urfave/cli/v2"
 )
 
-// AskMultipleDocumentsSelection ask user to select multiple documents
+const (
+	// StateNotDeployed document is not deployed
+	StateNotDeployed = "not deployed"
+	// StateChanged document content differ from the deployed one
+	StateChanged = "changed"
+	// StateUnchanged document content is already deployed
+	StateUnchanged = "unchanged"
+	// StateUnknown document state cannot be retrieved
+	StateUnknown = "unknown"
+)
+
+// AskMultipleDocumentsSelection ask user to select multiple documents, unless selection filters are provided
 func AskMultipleDocumentsSelection(c *cli.Context, documents []*document.Document) (*[]*document.Document, error) {
+	return askSelection(c, documents, false)
+}
+
+// AskMultipleDocumentsSelectionWithState ask user to select multiple documents showing
+// their deployed state, unless selection filters are provided
+func AskMultipleDocumentsSelectionWithState(c *cli.Context, documents []*document.Document) (*[]*document.Document, error) {
+	return askSelection(c, documents, true)
+}
+
+func askSelection(c *cli.Context, documents []*document.Document, withState bool) (*[]*document.Document, error) {
 	selectedDocuments := []*document.Document{}
 
+	// Apply filters, filtered documents are selected without prompt
+	if HasFilters(c) {
+		filtered, err := FilterDocuments(c, documents)
+		if err != nil {
+			return &selectedDocuments, err
+		}
+		if len(filtered) == 0 {
+			return &selectedDocuments, errors.New("No documents match the selection filters")
+		}
+		return &filtered, nil
+	}
+
 	// Check if single document
 	if len(documents) == 1 {
 		return &documents, nil
@@ -23,22 +61,41 @@ func AskMultipleDocumentsSelection(c *cli.Context, documents []*document.Documen
 		return &documents, nil
 	}
 
+	// Setup output printer
+	printer, err := output.NewPrinter(c)
+	if err != nil {
+		return &selectedDocuments, err
+	}
+	if !printer.IsInteractive() {
+		return &selectedDocuments, fmt.Errorf("Cannot ask documents selection: %s, use --all or selection filters", output.ErrNonInteractive)
+	}
+
+	// Retrieve deployed state
+	states := make([]string, len(documents))
+	if withState {
+		printer.Progressf("Checking deployed documents..")
+		states = GetDocumentsState(context.Background(), pool.New(c.Int("parallels"), c.Duration("grace-period")), documents)
+	}
+
 	// Build table
-	header := fmt.Sprintf("%-25s", "Name")
+	header := fmt.Sprintf("%-30s %-22s %-13s %s", "Name", "Type", "State", "Path")
 	var options []string
-	for _, document := range documents {
-		options = append(options, fmt.Sprintf("%-20s", document.Name))
+	for index, document := range documents {
+		options = append(options, fmt.Sprintf("%-30s %-22s %-13s %s", document.Name, document.Type, states[index], relativePath(document.ConfigFile)))
 	}
 
 	// Ask selection
 	documentsSelectedIndexes := []int{}
 	prompt := &survey.MultiSelect{
-		Message:  "Select documents: \n\n  " + header + "\n",
+		Message:  "Select documents: \n\n       " + header + "\n",
 		Options:  options,
 		PageSize: 15,
 	}
-	survey.AskOne(prompt, &documentsSelectedIndexes)
-	fmt.Println("")
+	err = printer.Ask(prompt, &documentsSelectedIndexes)
+	if err != nil {
+		return &selectedDocuments, err
+	}
+	fmt.Fprintln(printer.Progress(), "")
 
 	// Check response
 	if len(documentsSelectedIndexes) == 0 {
@@ -52,3 +109,44 @@ func AskMultipleDocumentsSelection(c *cli.Context, documents []*document.Documen
 
 	return &selectedDocuments, nil
 }
+
+// GetDocumentsState return the deployed state of each document, retrieved in parallel by workers
+func GetDocumentsState(ctx context.Context, workers *pool.Pool, documents []*document.Document) []string {
+	states := make([]string, len(documents))
+	for index := range states {
+		states[index] = StateUnknown
+	}
+
+	workers.Run(ctx, len(documents), func(ctx context.Context, index int) error {
+		d := documents[index]
+		if !d.IsDeployed(ctx) {
+			states[index] = StateNotDeployed
+			return nil
+		}
+		changed, err := d.IsContentChanged(ctx)
+		if err != nil {
+			return err
+		}
+		if changed {
+			states[index] = StateChanged
+		} else {
+			states[index] = StateUnchanged
+		}
+		return nil
+	})
+
+	return states
+}
+
+// relativePath return the path relative to the current directory, if possible
+func relativePath(file string) string {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return file
+	}
+	rel, err := filepath.Rel(cwd, file)
+	if err != nil {
+		return file
+	}
+	return rel
+}
@@ -0,0 +1,67 @@
+package config
+
+import (
+	"context"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/daaru00/aws-ssm-document-cli/internal/document"
+	"github.com/daaru00/aws-ssm-document-cli/internal/pool"
+	"github.com/daaru00/aws-ssm-document-cli/pkg/ssmfake"
+)
+
+func TestAskSelectionNonInteractive(t *testing.T) {
+	ci, ok := os.LookupEnv("CI")
+	os.Setenv("CI", "true")
+	defer func() {
+		if ok {
+			os.Setenv("CI", ci)
+		} else {
+			os.Unsetenv("CI")
+		}
+	}()
+	c := newFilterContext(t)
+
+	_, err := AskMultipleDocumentsSelection(c, testDocuments())
+	if err == nil || !strings.Contains(err.Error(), "use --all or selection filters") {
+		t.Errorf("expected non-interactive error, got %v", err)
+	}
+
+	selected, err := AskMultipleDocumentsSelection(newFilterContext(t, "--name", "Restart"), testDocuments())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(*selected) != 1 || (*selected)[0].Name != "Restart" {
+		t.Errorf("unexpected selection: %v", names(*selected))
+	}
+
+	_, err = AskMultipleDocumentsSelection(newFilterContext(t, "--name", "Missing"), testDocuments())
+	if err == nil {
+		t.Error("expected error when no documents match")
+	}
+}
+
+func TestGetDocumentsState(t *testing.T) {
+	fake := ssmfake.New()
+	newDocument := func(name string, description string) *document.Document {
+		d := document.NewWithClient(fake, aws.String("us-east-1"), name)
+		d.Content = document.Content{SchemaVersion: "2.2", Description: description}
+		return d
+	}
+	for _, d := range []*document.Document{newDocument("Unchanged", "v1"), newDocument("Changed", "v1")} {
+		err := d.Deploy(context.Background(), d.NewResult())
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	documents := []*document.Document{newDocument("Unchanged", "v1"), newDocument("Changed", "v2"), newDocument("Missing", "v1")}
+	states := GetDocumentsState(context.Background(), pool.New(2, 0), documents)
+	expected := []string{StateUnchanged, StateChanged, StateNotDeployed}
+	if !reflect.DeepEqual(states, expected) {
+		t.Errorf("unexpected states: %v", states)
+	}
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/daaru00/aws-ssm-document-cli/internal/document"
+	"github.com/daaru00/aws-ssm-document-cli/internal/output"
 	"github.com/joho/godotenv"
 	"github.com/urfave/cli/v2"
 )
@@ -52,6 +53,17 @@ func LoadDocuments(c *cli.Context, ses *session.Session) (*[]*document.Document,
 		}
 	}
 
+	// Setup ignored paths, exclude patterns are relative to the current directory
+	cwd, err := os.Getwd()
+	if err != nil {
+		return &documents, err
+	}
+	ignorer := NewIgnorer(cwd, c.StringSlice("exclude"))
+	err = ignorer.AddFile(cwd)
+	if err != nil {
+		return &documents, err
+	}
+
 	// Iterate over search paths provided
 	for _, searchPath := range searchPaths {
 
@@ -65,7 +77,7 @@ func LoadDocuments(c *cli.Context, ses *session.Session) (*[]*document.Document,
 		fileMode := info.Mode()
 		if fileMode.IsDir() {
 			// Found document in directory
-			documentsFound, err := LoadDocumentsFromDir(ses, &searchPath, &fileName, &parser)
+			documentsFound, err := LoadDocumentsFromDir(ses, &searchPath, &fileName, &parser, ignorer)
 			if err != nil {
 				return nil, err
 			}
@@ -86,6 +98,22 @@ func LoadDocuments(c *cli.Context, ses *session.Session) (*[]*document.Document,
 		}
 	}
 
+	// Set project of ownership tag, required only by commands that change deployed documents
+	for _, document := range documents {
+		document.Project = c.String("project")
+	}
+
+	// Print loading warnings
+	printer, err := output.NewPrinter(c)
+	if err != nil {
+		return &documents, err
+	}
+	for _, document := range documents {
+		for _, warning := range document.Warnings {
+			printer.Progressf("[%s] Warning: %s", document.Name, warning)
+		}
+	}
+
 	return &documents, nil
 }
 
@@ -110,19 +138,57 @@ func LoadDocumentFromFile(ses *session.Session, filePath *string, parser *string
 		return nil, err
 	}
 
+	// Keep configuration directory to resolve relative paths
+	document.Dir = filepath.Dir(*filePath)
+	document.ConfigFile = *filePath
+
 	// If file path is provided convert to absolute
 	if len(document.File) > 0 {
 		document.File = filepath.Join(filepath.Dir(*filePath), document.File)
 	}
 
+	// Convert package files paths to absolute
+	if document.Package != nil {
+		for i, file := range document.Package.Files {
+			if !filepath.IsAbs(file.File) {
+				document.Package.Files[i].File = filepath.Join(filepath.Dir(*filePath), file.File)
+			}
+		}
+	}
+
+	// Load parameters from script annotations
+	if document.IsShellScript() {
+		script, err := ioutil.ReadFile(document.File)
+		if err != nil {
+			return nil, err
+		}
+		document.Warnings, err = document.LoadScriptParameters(string(script))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Record parameters definitions warnings, errors are reported by validation
+	warnings, _ := document.ValidateParameters()
+	document.Warnings = append(document.Warnings, warnings...)
+
+	// Load test cases
+	err = LoadDocumentTests(document, *filePath, parser)
+	if err != nil {
+		return nil, err
+	}
+
 	return document, nil
 }
 
-// LoadDocumentsFromDir search config files and load documents
-func LoadDocumentsFromDir(ses *session.Session, searchPath *string, fileNameToMatch *string, parser *string) ([]*document.Document, error) {
+// LoadDocumentsFromDir search config files and load documents, ignored directories are not walked
+func LoadDocumentsFromDir(ses *session.Session, searchPath *string, fileNameToMatch *string, parser *string, ignorer *Ignorer) ([]*document.Document, error) {
 	start := time.Now()
 	filesCount := 0
 	documents := []*document.Document{}
+	if ignorer == nil {
+		ignorer = &Ignorer{}
+	}
 
 	// Walk for each files in source path
 	err := filepath.Walk(*searchPath, func(filePath string, info os.FileInfo, err error) error {
@@ -130,16 +196,28 @@ func LoadDocumentsFromDir(ses *session.Session, searchPath *string, fileNameToMa
 			return err
 		}
 
-		// Skip directories
-		if info.IsDir() {
+		// Skip ignored paths, ignore rules match absolute paths
+		absolutePath, err := filepath.Abs(filePath)
+		if err != nil {
+			return err
+		}
+		if filePath != *searchPath && ignorer.IsIgnored(absolutePath, info.IsDir()) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
 			return nil
 		}
+
+		// Load ignore file of directories
+		if info.IsDir() {
+			return ignorer.AddFile(absolutePath)
+		}
 		filesCount++
 
 		// Check if file match name
 		fileName := filepath.Base(filePath)
 		match, _ := filepath.Match(*fileNameToMatch, fileName)
-		if !match {
+		if !match || IsTestFile(fileName) {
 			return nil
 		}
 
@@ -0,0 +1,59 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+)
+
+func TestLoadDocumentsFromDirInvalidConfiguration(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ssm-document-loader-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// Invalid configurations must not stop loading other documents
+	configs := map[string]string{
+		"valid":       "name: Valid\ncontent:\n  schemaVersion: \"2.2\"\n  mainSteps:\n    - action: aws:runShellScript\n      name: run\n      inputs:\n        runCommand: [\"echo ok\"]\n",
+		"association": "name: InvalidAssociation\nassociations:\n  - name: nightly\n",
+		"window":      "name: InvalidWindow\nmaintenanceWindows:\n  - window: nightly\n    create: false\n    schedule: cron(0 2 * * ? *)\n",
+		"parameter":   "name: InvalidParameter\nparameters:\n  Instance:\n    type: AWS::EC2::Instance::Id\n",
+	}
+	for subdir, config := range configs {
+		err = os.MkdirAll(filepath.Join(dir, subdir), 0755)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = ioutil.WriteFile(filepath.Join(dir, subdir, "document.yml"), []byte(config), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ses := session.Must(session.NewSession(&aws.Config{Region: aws.String("us-east-1")}))
+	fileName := "document.yml"
+	parser := "yml"
+	documents, err := LoadDocumentsFromDir(ses, &dir, &fileName, &parser, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(documents) != len(configs) {
+		t.Fatalf("expected %d documents, got %d", len(configs), len(documents))
+	}
+
+	// Errors are reported by validation
+	for _, document := range documents {
+		err = document.Validate()
+		if document.Name == "Valid" && err != nil {
+			t.Errorf("unexpected validation error: %s", err)
+		}
+		if document.Name != "Valid" && err == nil {
+			t.Errorf("[%s] expected validation error", document.Name)
+		}
+	}
+}
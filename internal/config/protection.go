@@ -0,0 +1,105 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/daaru00/aws-ssm-document-cli/internal/document"
+	"github.com/daaru00/aws-ssm-document-cli/internal/output"
+	"github.com/urfave/cli/v2"
+)
+
+// GetStage return the current stage, the name of the loaded env file
+func GetStage() string {
+	return os.Getenv("SSM_DOCUMENT_ENV")
+}
+
+// ApplyProtection mark documents as protected when the project protection rules match
+// the current stage and account, force flag allow to remove and unshare them
+func ApplyProtection(c *cli.Context, accountID string, documents []*document.Document) {
+	rules := document.ProtectionRules{
+		Stages:   explodeValues(c.StringSlice("protected-stage")),
+		Accounts: explodeValues(c.StringSlice("protected-account")),
+	}
+	protectAll := rules.Match(GetStage(), accountID)
+
+	for _, document := range documents {
+		if protectAll {
+			document.Protected = true
+		}
+		document.ForceProtected = c.Bool("force-protected")
+	}
+}
+
+// GetProtectedDocuments return the protected documents not forced
+func GetProtectedDocuments(documents []*document.Document) []*document.Document {
+	protected := []*document.Document{}
+	for _, document := range documents {
+		if document.Protected && !document.ForceProtected {
+			protected = append(protected, document)
+		}
+	}
+	return protected
+}
+
+// AskProtectedConfirmation ask to type the account ID, or the stage, before operating on protected documents.
+// The confirmation is asked even with the yes flag, only the force-protected flag skip it.
+func AskProtectedConfirmation(c *cli.Context, accountID string, documents []*document.Document, operation string) error {
+	protected := GetProtectedDocuments(documents)
+	if len(protected) == 0 {
+		return nil
+	}
+
+	// Setup output printer
+	printer, err := output.NewPrinter(c)
+	if err != nil {
+		return err
+	}
+	for _, document := range protected {
+		printer.Progressf("[%s] Document is protected", document.Name)
+	}
+
+	// Build accepted answers
+	stage := GetStage()
+	accepted := []string{accountID}
+	message := fmt.Sprintf("Type the account ID %s to %s %d protected documents:", accountID, operation, len(protected))
+	if len(stage) > 0 {
+		accepted = append(accepted, stage)
+		message = fmt.Sprintf("Type the account ID %s or the stage %s to %s %d protected documents:", accountID, stage, operation, len(protected))
+	}
+
+	// Ask typed confirmation
+	answer := ""
+	prompt := &survey.Input{
+		Message: message,
+	}
+	err = printer.Ask(prompt, &answer)
+	if err != nil {
+		return fmt.Errorf("Cannot ask confirmation for protected documents, use --force-protected to proceed: %s", err)
+	}
+
+	// Check response
+	for _, value := range accepted {
+		if strings.TrimSpace(answer) == value {
+			return nil
+		}
+	}
+	return errors.New("Not confirmed protected documents " + operation + ", skip operation")
+}
+
+// explodeValues split comma separated values
+func explodeValues(values []string) []string {
+	exploded := []string{}
+	for _, value := range values {
+		for _, item := range strings.Split(value, ",") {
+			item = strings.TrimSpace(item)
+			if len(item) > 0 {
+				exploded = append(exploded, item)
+			}
+		}
+	}
+	return exploded
+}
@@ -0,0 +1,63 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/daaru00/aws-ssm-document-cli/internal/document"
+)
+
+// testFileSuffix is added to the document file name, before the extension, to declare tests
+const testFileSuffix = ".test"
+
+// TestFilePath return the path of the tests file related to a document file
+func TestFilePath(filePath string) string {
+	extension := filepath.Ext(filePath)
+	return filePath[0:len(filePath)-len(extension)] + testFileSuffix + extension
+}
+
+// IsTestFile check if file path is a tests file
+func IsTestFile(filePath string) bool {
+	extension := filepath.Ext(filePath)
+	return strings.HasSuffix(filePath[0:len(filePath)-len(extension)], testFileSuffix)
+}
+
+// LoadDocumentTests load test cases from the tests file next to the document, if exist,
+// and resolve fixture sources relative to the document directory
+func LoadDocumentTests(d *document.Document, filePath string, parser *string) error {
+	testFilePath := TestFilePath(filePath)
+
+	// Check if file exist
+	_, err := os.Stat(testFilePath)
+	if err == nil {
+		fileContent, err := ioutil.ReadFile(testFilePath)
+		if err != nil {
+			return err
+		}
+
+		// Parse tests
+		tests := struct {
+			Tests []document.TestCase `yaml:"tests" json:"tests"`
+		}{}
+		err = ParseContent(InterpolateContent(&fileContent), parser, &tests)
+		if err != nil {
+			return err
+		}
+		d.Tests = append(d.Tests, tests.Tests...)
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	// Convert fixture sources to absolute
+	for i := range d.Tests {
+		for j, fixture := range d.Tests[i].Fixtures {
+			if len(fixture.Source) > 0 && !filepath.IsAbs(fixture.Source) {
+				d.Tests[i].Fixtures[j].Source = filepath.Join(filepath.Dir(filePath), fixture.Source)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,415 @@
+package document
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	// ActionRunShellScript run shell commands on Linux and macOS
+	ActionRunShellScript = "aws:runShellScript"
+	// ActionRunPowerShellScript run PowerShell commands on Windows
+	ActionRunPowerShellScript = "aws:runPowerShellScript"
+	// ActionRunDocument run another SSM document
+	ActionRunDocument = "aws:runDocument"
+	// ActionDownloadContent download scripts and documents from remote sources
+	ActionDownloadContent = "aws:downloadContent"
+	// ActionConfigurePackage install or uninstall a Distributor package
+	ActionConfigurePackage = "aws:configurePackage"
+	// ActionSoftwareInventory collect instance inventory
+	ActionSoftwareInventory = "aws:softwareInventory"
+	// ActionExecuteScript run a Python or PowerShell script in automation
+	ActionExecuteScript = "aws:executeScript"
+	// ActionExecuteAwsApi call an AWS API operation
+	ActionExecuteAwsApi = "aws:executeAwsApi"
+	// ActionBranch choose the next step evaluating conditions
+	ActionBranch = "aws:branch"
+	// ActionWaitForAwsResourceProperty wait for a resource property value
+	ActionWaitForAwsResourceProperty = "aws:waitForAwsResourceProperty"
+	// ActionApprove wait for a manual approval
+	ActionApprove = "aws:approve"
+	// ActionSleep pause the automation
+	ActionSleep = "aws:sleep"
+)
+
+// StepInputs are typed action inputs that can check required values
+type StepInputs interface {
+	Validate() error
+}
+
+// actionInputs return a new typed inputs for each known action
+var actionInputs = map[string]func() StepInputs{
+	ActionRunShellScript:             func() StepInputs { return &ShellInput{} },
+	ActionRunPowerShellScript:        func() StepInputs { return &ShellInput{} },
+	ActionRunDocument:                func() StepInputs { return &RunDocumentInput{} },
+	ActionDownloadContent:            func() StepInputs { return &DownloadContentInput{} },
+	ActionConfigurePackage:           func() StepInputs { return &ConfigurePackageInput{} },
+	ActionSoftwareInventory:          func() StepInputs { return &SoftwareInventoryInput{} },
+	ActionExecuteScript:              func() StepInputs { return &ExecuteScriptInput{} },
+	ActionExecuteAwsApi:              func() StepInputs { return &ExecuteAwsApiInput{} },
+	ActionBranch:                     func() StepInputs { return &BranchInput{} },
+	ActionWaitForAwsResourceProperty: func() StepInputs { return &WaitForAwsResourcePropertyInput{} },
+	ActionApprove:                    func() StepInputs { return &ApproveInput{} },
+	ActionSleep:                      func() StepInputs { return &SleepInput{} },
+}
+
+// StringList accept a single string or a list of strings
+type StringList []string
+
+// UnmarshalYAML parse a single string as a list of one element
+func (l *StringList) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var single string
+	if err := unmarshal(&single); err == nil {
+		*l = StringList{single}
+		return nil
+	}
+
+	var list []string
+	if err := unmarshal(&list); err != nil {
+		return err
+	}
+	*l = list
+	return nil
+}
+
+// Validate check shell script inputs
+func (i *ShellInput) Validate() error {
+	if len(i.RunCommand) == 0 {
+		return fmt.Errorf("runCommand is required")
+	}
+	return nil
+}
+
+// RunDocumentInput inputs of aws:runDocument
+type RunDocumentInput struct {
+	DocumentType       string      `yaml:"documentType,omitempty" json:"documentType,omitempty"`
+	DocumentPath       string      `yaml:"documentPath" json:"documentPath"`
+	DocumentParameters interface{} `yaml:"documentParameters,omitempty" json:"documentParameters,omitempty"`
+}
+
+// Validate check run document inputs
+func (i *RunDocumentInput) Validate() error {
+	if len(i.DocumentPath) == 0 {
+		return fmt.Errorf("documentPath is required")
+	}
+	return validateOneOf("documentType", i.DocumentType, "SSMDocument", "LocalPath")
+}
+
+// DownloadContentInput inputs of aws:downloadContent
+type DownloadContentInput struct {
+	SourceType      string      `yaml:"sourceType" json:"sourceType"`
+	SourceInfo      interface{} `yaml:"sourceInfo" json:"sourceInfo"`
+	DestinationPath string      `yaml:"destinationPath,omitempty" json:"destinationPath,omitempty"`
+}
+
+// Validate check download content inputs
+func (i *DownloadContentInput) Validate() error {
+	if len(i.SourceType) == 0 || i.SourceInfo == nil {
+		return fmt.Errorf("sourceType and sourceInfo are required")
+	}
+	return validateOneOf("sourceType", i.SourceType, "GitHub", "Git", "HTTP", "S3", "SSMDocument")
+}
+
+// ConfigurePackageInput inputs of aws:configurePackage
+type ConfigurePackageInput struct {
+	Name                string      `yaml:"name" json:"name"`
+	Action              string      `yaml:"action" json:"action"`
+	InstallationType    string      `yaml:"installationType,omitempty" json:"installationType,omitempty"`
+	Version             string      `yaml:"version,omitempty" json:"version,omitempty"`
+	AdditionalArguments interface{} `yaml:"additionalArguments,omitempty" json:"additionalArguments,omitempty"`
+}
+
+// Validate check configure package inputs
+func (i *ConfigurePackageInput) Validate() error {
+	if len(i.Name) == 0 || len(i.Action) == 0 {
+		return fmt.Errorf("name and action are required")
+	}
+	err := validateOneOf("action", i.Action, "Install", "Uninstall")
+	if err != nil {
+		return err
+	}
+	return validateOneOf("installationType", i.InstallationType, "Uninstall and reinstall", "In-place update")
+}
+
+// SoftwareInventoryInput inputs of aws:softwareInventory
+type SoftwareInventoryInput struct {
+	Applications                string `yaml:"applications,omitempty" json:"applications,omitempty"`
+	AwsComponents               string `yaml:"awsComponents,omitempty" json:"awsComponents,omitempty"`
+	NetworkConfig               string `yaml:"networkConfig,omitempty" json:"networkConfig,omitempty"`
+	WindowsUpdates              string `yaml:"windowsUpdates,omitempty" json:"windowsUpdates,omitempty"`
+	InstanceDetailedInformation string `yaml:"instanceDetailedInformation,omitempty" json:"instanceDetailedInformation,omitempty"`
+	Services                    string `yaml:"services,omitempty" json:"services,omitempty"`
+	WindowsRoles                string `yaml:"windowsRoles,omitempty" json:"windowsRoles,omitempty"`
+	CustomInventory             string `yaml:"customInventory,omitempty" json:"customInventory,omitempty"`
+	BillingInfo                 string `yaml:"billingInfo,omitempty" json:"billingInfo,omitempty"`
+	Files                       string `yaml:"files,omitempty" json:"files,omitempty"`
+	WindowsRegistry             string `yaml:"windowsRegistry,omitempty" json:"windowsRegistry,omitempty"`
+}
+
+// Validate check software inventory inputs, all are optional
+func (i *SoftwareInventoryInput) Validate() error {
+	return nil
+}
+
+// ExecuteScriptInput inputs of aws:executeScript, ScriptFile is a local script resolved by Build
+type ExecuteScriptInput struct {
+	Runtime      string                 `yaml:"Runtime" json:"Runtime"`
+	Handler      string                 `yaml:"Handler,omitempty" json:"Handler,omitempty"`
+	Script       string                 `yaml:"Script,omitempty" json:"Script,omitempty"`
+	Attachment   string                 `yaml:"Attachment,omitempty" json:"Attachment,omitempty"`
+	InputPayload map[string]interface{} `yaml:"InputPayload,omitempty" json:"InputPayload,omitempty"`
+	ScriptFile   string                 `yaml:"scriptFile,omitempty" json:"scriptFile,omitempty"`
+}
+
+// Validate check execute script inputs
+func (i *ExecuteScriptInput) Validate() error {
+	if len(i.Runtime) == 0 {
+		return fmt.Errorf("Runtime is required")
+	}
+	if isPythonRuntime(i.Runtime) && len(i.Handler) == 0 {
+		return fmt.Errorf("Handler is required by %s runtime", i.Runtime)
+	}
+	sources := 0
+	for _, source := range []string{i.Script, i.Attachment, i.ScriptFile} {
+		if len(source) > 0 {
+			sources++
+		}
+	}
+	if sources != 1 {
+		return fmt.Errorf("one of Script, Attachment or scriptFile is required")
+	}
+	return nil
+}
+
+// ExecuteAwsApiInput inputs of aws:executeAwsApi, API parameters are kept as they are
+type ExecuteAwsApiInput struct {
+	Service    string                 `yaml:"Service" json:"Service"`
+	Api        string                 `yaml:"Api" json:"Api"`
+	Parameters map[string]interface{} `yaml:",inline" json:"-"`
+}
+
+// Validate check execute AWS API inputs
+func (i *ExecuteAwsApiInput) Validate() error {
+	if len(i.Service) == 0 || len(i.Api) == 0 {
+		return fmt.Errorf("Service and Api are required")
+	}
+	return nil
+}
+
+// branchOperators are the operators supported by aws:branch choices
+var branchOperators = []string{
+	"StringEquals", "EqualsIgnoreCase", "StartsWith", "EndsWith", "Contains",
+	"NumericEquals", "NumericGreater", "NumericLesser", "NumericGreaterOrEquals", "NumericLesserOrEquals",
+	"BooleanEquals", "And", "Or", "Not",
+}
+
+// BranchChoice is an aws:branch choice, the condition is expressed by an operator key
+type BranchChoice struct {
+	NextStep  string                 `yaml:"NextStep,omitempty" json:"NextStep,omitempty"`
+	Variable  string                 `yaml:"Variable,omitempty" json:"Variable,omitempty"`
+	Condition map[string]interface{} `yaml:",inline" json:"-"`
+}
+
+// BranchInput inputs of aws:branch
+type BranchInput struct {
+	Choices []BranchChoice `yaml:"Choices" json:"Choices"`
+	Default string         `yaml:"Default,omitempty" json:"Default,omitempty"`
+}
+
+// Validate check branch inputs
+func (i *BranchInput) Validate() error {
+	if len(i.Choices) == 0 {
+		return fmt.Errorf("Choices is required")
+	}
+	for index, choice := range i.Choices {
+		if len(choice.NextStep) == 0 {
+			return fmt.Errorf("choice %d requires NextStep", index+1)
+		}
+		if len(choice.Condition) == 0 {
+			return fmt.Errorf("choice %d requires an operator", index+1)
+		}
+		for operator := range choice.Condition {
+			err := validateOneOf(fmt.Sprintf("choice %d operator", index+1), operator, branchOperators...)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// WaitForAwsResourcePropertyInput inputs of aws:waitForAwsResourceProperty, API parameters are kept as they are
+type WaitForAwsResourcePropertyInput struct {
+	Service          string                 `yaml:"Service" json:"Service"`
+	Api              string                 `yaml:"Api" json:"Api"`
+	PropertySelector string                 `yaml:"PropertySelector" json:"PropertySelector"`
+	DesiredValues    StringList             `yaml:"DesiredValues" json:"DesiredValues"`
+	Parameters       map[string]interface{} `yaml:",inline" json:"-"`
+}
+
+// Validate check wait for resource property inputs
+func (i *WaitForAwsResourcePropertyInput) Validate() error {
+	if len(i.Service) == 0 || len(i.Api) == 0 || len(i.PropertySelector) == 0 || len(i.DesiredValues) == 0 {
+		return fmt.Errorf("Service, Api, PropertySelector and DesiredValues are required")
+	}
+	return nil
+}
+
+// ApproveInput inputs of aws:approve
+type ApproveInput struct {
+	Approvers            StringList `yaml:"Approvers" json:"Approvers"`
+	MinRequiredApprovals string     `yaml:"MinRequiredApprovals,omitempty" json:"MinRequiredApprovals,omitempty"`
+	Message              string     `yaml:"Message,omitempty" json:"Message,omitempty"`
+	NotificationArn      string     `yaml:"NotificationArn,omitempty" json:"NotificationArn,omitempty"`
+}
+
+// Validate check approve inputs
+func (i *ApproveInput) Validate() error {
+	if len(i.Approvers) == 0 {
+		return fmt.Errorf("Approvers is required")
+	}
+	return nil
+}
+
+// SleepInput inputs of aws:sleep
+type SleepInput struct {
+	Duration  string `yaml:"Duration,omitempty" json:"Duration,omitempty"`
+	Timestamp string `yaml:"Timestamp,omitempty" json:"Timestamp,omitempty"`
+}
+
+// Validate check sleep inputs
+func (i *SleepInput) Validate() error {
+	if (len(i.Duration) == 0) == (len(i.Timestamp) == 0) {
+		return fmt.Errorf("one of Duration or Timestamp is required")
+	}
+	return nil
+}
+
+// DecodeInputs return step inputs decoded into the typed struct of the action,
+// unknown fields are reported as errors. Inputs of unknown actions are returned as they are.
+func (s *MainStep) DecodeInputs() (interface{}, error) {
+	factory, known := actionInputs[s.Action]
+	if !known {
+		return s.Inputs, nil
+	}
+
+	// Inputs can be already typed
+	if typed, ok := s.Inputs.(StepInputs); ok {
+		return typed, nil
+	}
+
+	raw, err := yaml.Marshal(s.Inputs)
+	if err != nil {
+		return nil, err
+	}
+	typed := factory()
+	err = yaml.UnmarshalStrict(raw, typed)
+	if err != nil {
+		message := strings.TrimPrefix(err.Error(), "yaml: ")
+		message = strings.TrimPrefix(message, "unmarshal errors:\n")
+		lines := strings.Split(message, "\n")
+		for i := range lines {
+			lines[i] = strings.TrimSpace(lines[i])
+		}
+		return nil, fmt.Errorf("invalid %s inputs: %s", s.Action, strings.Join(lines, ", "))
+	}
+	return typed, nil
+}
+
+// Validate check step name, typed inputs and step properties
+func (s *MainStep) Validate() error {
+	if len(s.Name) == 0 {
+		return fmt.Errorf("step name is required")
+	}
+	if len(s.Action) == 0 {
+		return fmt.Errorf("step %s action is required", s.Name)
+	}
+
+	inputs, err := s.DecodeInputs()
+	if err != nil {
+		return fmt.Errorf("step %s %s", s.Name, err)
+	}
+	if typed, ok := inputs.(StepInputs); ok {
+		err = typed.Validate()
+		if err != nil {
+			return fmt.Errorf("step %s %s inputs: %s", s.Name, s.Action, err)
+		}
+	}
+
+	// Check failure handling, step references are checked by ValidateSteps
+	if len(s.OnFailure) > 0 && s.OnFailure != "Abort" && s.OnFailure != "Continue" && s.OnFailure != "exit" && s.OnFailure != "successAndExit" && !strings.HasPrefix(s.OnFailure, "step:") {
+		return fmt.Errorf("step %s onFailure %q is not valid", s.Name, s.OnFailure)
+	}
+	if s.TimeoutSeconds < 0 || s.MaxAttempts < 0 {
+		return fmt.Errorf("step %s timeoutSeconds and maxAttempts cannot be negative", s.Name)
+	}
+
+	return nil
+}
+
+// ValidateSteps check each step and references between steps
+func ValidateSteps(steps []MainStep) error {
+	names := map[string]bool{}
+	for _, step := range steps {
+		err := step.Validate()
+		if err != nil {
+			return err
+		}
+		if names[step.Name] {
+			return fmt.Errorf("step %s is declared more than once", step.Name)
+		}
+		names[step.Name] = true
+	}
+
+	// Check step references
+	for _, step := range steps {
+		references := []string{step.NextStep}
+		for _, handler := range []string{step.OnFailure, step.OnCancel} {
+			if strings.HasPrefix(handler, "step:") {
+				references = append(references, strings.TrimPrefix(handler, "step:"))
+			}
+		}
+		if inputs, err := step.DecodeInputs(); err == nil {
+			if branch, ok := inputs.(*BranchInput); ok {
+				references = append(references, branch.Default)
+				for _, choice := range branch.Choices {
+					references = append(references, choice.NextStep)
+				}
+			}
+		}
+		for _, reference := range references {
+			if len(reference) > 0 && !names[reference] {
+				return fmt.Errorf("step %s reference unknown step %s", step.Name, reference)
+			}
+		}
+	}
+
+	return nil
+}
+
+// ValidateSteps check the steps declared in document content
+func (d *Document) ValidateSteps() error {
+	err := ValidateSteps(d.Content.MainSteps)
+	if err != nil {
+		return fmt.Errorf("[%s] %s", d.Name, err)
+	}
+	return nil
+}
+
+// validateOneOf check optional value is one of allowed values, placeholders are not checked
+func validateOneOf(name string, value string, allowed ...string) error {
+	if len(value) == 0 || strings.Contains(value, "{{") {
+		return nil
+	}
+	for _, item := range allowed {
+		if item == value {
+			return nil
+		}
+	}
+	sorted := append([]string{}, allowed...)
+	sort.Strings(sorted)
+	return fmt.Errorf("%s %q is not valid, valid values are %s", name, value, strings.Join(sorted, ", "))
+}
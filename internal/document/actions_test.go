@@ -0,0 +1,75 @@
+package document
+
+import (
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+func TestValidateSteps(t *testing.T) {
+	cases := []struct {
+		name  string
+		steps string
+		err   string
+	}{
+		{"shell single command", `[{action: "aws:runShellScript", name: run, inputs: {runCommand: "echo ok"}}]`, ""},
+		{"shell typo", `[{action: "aws:runShellScript", name: run, inputs: {runCommands: ["echo ok"]}}]`, "field runCommands not found"},
+		{"unknown action passthrough", `[{action: "aws:custom", name: run, inputs: {anything: 1}}]`, ""},
+		{"run document", `[{action: "aws:runDocument", name: run, inputs: {documentType: SSMDocument, documentPath: Other}}]`, ""},
+		{"run document type", `[{action: "aws:runDocument", name: run, inputs: {documentType: Remote, documentPath: Other}}]`, "documentType"},
+		{"configure package", `[{action: "aws:configurePackage", name: pkg, inputs: {name: agent, action: Install}}]`, ""},
+		{"execute api parameters", `[{action: "aws:executeAwsApi", name: api, inputs: {Service: ec2, Api: RebootInstances, InstanceIds: [i-1]}}]`, ""},
+		{"execute api required", `[{action: "aws:executeAwsApi", name: api, inputs: {Service: ec2}}]`, "Service and Api are required"},
+		{"execute script", `[{action: "aws:executeScript", name: script, inputs: {Runtime: python3.8, Handler: handler, Script: "def handler(e, c): pass"}}]`, ""},
+		{"sleep", `[{action: "aws:sleep", name: wait, inputs: {Duration: PT5M, Timestamp: "2026-01-01T00:00:00Z"}}]`, "one of Duration or Timestamp"},
+		{"approve", `[{action: "aws:approve", name: approve, inputs: {Approvers: "arn:aws:iam::123456789012:role/Approver"}}]`, ""},
+		{"wait property", `[{action: "aws:waitForAwsResourceProperty", name: wait, inputs: {Service: ec2, Api: DescribeInstances, PropertySelector: "$.State", DesiredValues: running, InstanceIds: [i-1]}}]`, ""},
+		{"branch", `[
+			{action: "aws:branch", name: choose, inputs: {Choices: [{NextStep: linux, Variable: "{{ Platform }}", StringEquals: Linux}], Default: done}},
+			{action: "aws:sleep", name: linux, inputs: {Duration: PT1S}, nextStep: done},
+			{action: "aws:sleep", name: done, inputs: {Duration: PT1S}, isEnd: true}
+		]`, ""},
+		{"branch operator", `[{action: "aws:branch", name: choose, inputs: {Choices: [{NextStep: choose, Variable: x, Equal: y}]}}]`, "operator"},
+		{"unknown next step", `[{action: "aws:sleep", name: wait, inputs: {Duration: PT1S}, nextStep: missing}]`, "unknown step missing"},
+		{"on failure step", `[{action: "aws:sleep", name: wait, inputs: {Duration: PT1S}, onFailure: "step:missing"}]`, "unknown step missing"},
+		{"on failure invalid", `[{action: "aws:sleep", name: wait, inputs: {Duration: PT1S}, onFailure: retry}]`, "onFailure"},
+		{"duplicate name", `[{action: "aws:custom", name: run}, {action: "aws:custom", name: run}]`, "more than once"},
+	}
+
+	for _, c := range cases {
+		steps := []MainStep{}
+		err := yaml.Unmarshal([]byte(c.steps), &steps)
+		if err != nil {
+			t.Fatalf("%s: cannot parse steps: %s", c.name, err)
+		}
+
+		err = ValidateSteps(steps)
+		if len(c.err) == 0 && err != nil {
+			t.Errorf("%s: unexpected error: %s", c.name, err)
+		}
+		if len(c.err) > 0 && (err == nil || !strings.Contains(err.Error(), c.err)) {
+			t.Errorf("%s: expected error %q, got %v", c.name, c.err, err)
+		}
+	}
+}
+
+func TestDecodeInputs(t *testing.T) {
+	step := MainStep{}
+	err := yaml.Unmarshal([]byte(`{action: "aws:runShellScript", name: run, onFailure: exit, timeoutSeconds: 60, inputs: {runCommand: "echo ok", timeoutSeconds: 30}}`), &step)
+	if err != nil {
+		t.Fatalf("cannot parse step: %s", err)
+	}
+	if step.OnFailure != "exit" || step.TimeoutSeconds != 60 {
+		t.Errorf("step properties not parsed: %+v", step)
+	}
+
+	inputs, err := step.DecodeInputs()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	shell, ok := inputs.(*ShellInput)
+	if !ok || len(shell.RunCommand) != 1 || shell.RunCommand[0] != "echo ok" || shell.TimeoutSeconds != "30" {
+		t.Errorf("unexpected inputs: %#v", inputs)
+	}
+}
@@ -0,0 +1,248 @@
+package document
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// annotationPrefix start a parameter declaration in script header comments
+const annotationPrefix = "@param"
+
+// PlaceholderRegex match SSM parameters placeholders like {{ Name }}
+var PlaceholderRegex = regexp.MustCompile(`\{\{\s*([A-Za-z0-9_.:/-]+)\s*\}\}`)
+
+// Placeholders return the sorted unique parameter names used in text
+func Placeholders(text string) []string {
+	found := map[string]bool{}
+	for _, match := range PlaceholderRegex.FindAllStringSubmatch(text, -1) {
+		found[match[1]] = true
+	}
+
+	names := []string{}
+	for name := range found {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// annotationToken is a word of an annotation, quoted words can contain spaces
+// and quoted flag is set when the word start with a quote
+type annotationToken struct {
+	value  string
+	quoted bool
+}
+
+// ParseScriptAnnotations parse parameters declared in the script header comments, like
+// `# @param Message String "Greeting message" default="Hello World"`,
+// the header ends at the first non empty line that is not a comment
+func ParseScriptAnnotations(script string) (map[string]Parameter, error) {
+	parameters := map[string]Parameter{}
+
+	for index, line := range strings.Split(script, "\n") {
+		line = strings.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+		if !strings.HasPrefix(line, "#") {
+			break
+		}
+
+		// Check annotation
+		line = strings.TrimSpace(strings.TrimLeft(line, "#"))
+		if !strings.HasPrefix(line, annotationPrefix+" ") {
+			continue
+		}
+
+		name, parameter, err := parseAnnotation(strings.TrimPrefix(line, annotationPrefix))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s", index+1, err)
+		}
+		if _, exist := parameters[name]; exist {
+			return nil, fmt.Errorf("line %d: parameter %s is already declared", index+1, name)
+		}
+		parameters[name] = parameter
+	}
+
+	return parameters, nil
+}
+
+// parseAnnotation parse a single annotation: Name Type ["Description"] [key=value...]
+func parseAnnotation(annotation string) (string, Parameter, error) {
+	parameter := Parameter{}
+
+	tokens, err := splitAnnotation(annotation)
+	if err != nil {
+		return "", parameter, err
+	}
+	if len(tokens) < 2 {
+		return "", parameter, fmt.Errorf("expected %s Name Type [\"Description\"] [key=value...]", annotationPrefix)
+	}
+	name := tokens[0].value
+	parameter.Type = tokens[1].value
+	tokens = tokens[2:]
+
+	// Description is optional
+	if len(tokens) > 0 && (tokens[0].quoted || !strings.Contains(tokens[0].value, "=")) {
+		parameter.Description = tokens[0].value
+		tokens = tokens[1:]
+	}
+
+	// Parse options
+	for _, token := range tokens {
+		parts := strings.SplitN(token.value, "=", 2)
+		if len(parts) != 2 {
+			return name, parameter, fmt.Errorf("option %s is not valid, expected key=value", token.value)
+		}
+		key, value := parts[0], parts[1]
+
+		switch key {
+		case "default":
+			parameter.Default, err = parseAnnotationDefault(parameter.Type, value)
+		case "allowedValues":
+			parameter.AllowedValues = strings.Split(value, ",")
+		case "allowedPattern":
+			parameter.AllowedPattern = value
+		case "displayType":
+			parameter.DisplayType = value
+		case "minItems":
+			parameter.MinItems, err = strconv.Atoi(value)
+		case "maxItems":
+			parameter.MaxItems, err = strconv.Atoi(value)
+		case "minChars":
+			parameter.MinChars, err = strconv.Atoi(value)
+		case "maxChars":
+			parameter.MaxChars, err = strconv.Atoi(value)
+		default:
+			return name, parameter, fmt.Errorf("option %s is not supported", key)
+		}
+		if err != nil {
+			return name, parameter, fmt.Errorf("option %s is not valid: %s", key, err)
+		}
+	}
+
+	return name, parameter, nil
+}
+
+// parseAnnotationDefault convert default value text to the parameter type
+func parseAnnotationDefault(parameterType string, value string) (interface{}, error) {
+	if IsSSMReference(value) {
+		return value, nil
+	}
+
+	switch parameterType {
+	case ParameterTypeInteger:
+		return strconv.Atoi(value)
+	case ParameterTypeBoolean:
+		return strconv.ParseBool(value)
+	case ParameterTypeStringList:
+		items := []interface{}{}
+		for _, item := range strings.Split(value, ",") {
+			items = append(items, item)
+		}
+		return items, nil
+	case ParameterTypeMapList, ParameterTypeStringMap:
+		var decoded interface{}
+		err := json.Unmarshal([]byte(value), &decoded)
+		return decoded, err
+	default:
+		return value, nil
+	}
+}
+
+// splitAnnotation split annotation text by spaces, double quoted text is kept together
+func splitAnnotation(text string) ([]annotationToken, error) {
+	tokens := []annotationToken{}
+	current := annotationToken{}
+	inToken := false
+	inQuote := false
+	escaped := false
+
+	for _, char := range text {
+		switch {
+		case escaped:
+			current.value += string(char)
+			escaped = false
+		case inQuote && char == '\\':
+			escaped = true
+		case char == '"':
+			if !inToken {
+				current.quoted = true
+			}
+			inQuote = !inQuote
+			inToken = true
+		case !inQuote && (char == ' ' || char == '\t'):
+			if inToken {
+				tokens = append(tokens, current)
+				current = annotationToken{}
+				inToken = false
+			}
+		default:
+			current.value += string(char)
+			inToken = true
+		}
+	}
+	if inQuote {
+		return nil, fmt.Errorf("unterminated quote in %q", strings.TrimSpace(text))
+	}
+	if inToken {
+		tokens = append(tokens, current)
+	}
+
+	return tokens, nil
+}
+
+// IsShellScript check if document content is generated from a shell script
+func (d *Document) IsShellScript() bool {
+	return len(d.File) > 0 && (d.Format == "SHELL" || strings.ToUpper(filepath.Ext(d.File)) == ".SH")
+}
+
+// LoadScriptParameters add parameters declared in script annotations, parameters
+// declared in configuration file take precedence. Return warnings about
+// placeholders without a declaration and declared parameters never used.
+func (d *Document) LoadScriptParameters(script string) ([]string, error) {
+	annotated, err := ParseScriptAnnotations(script)
+	if err != nil {
+		return nil, fmt.Errorf("[%s] Invalid script annotation at %s", d.Name, err)
+	}
+
+	// Merge parameters
+	if len(annotated) > 0 && d.Parameters == nil {
+		d.Parameters = map[string]Parameter{}
+	}
+	for name, parameter := range annotated {
+		if _, exist := d.Parameters[name]; !exist {
+			d.Parameters[name] = parameter
+		}
+	}
+
+	// Check placeholders usage, references like {{ssm:/path}} are not parameters
+	warnings := []string{}
+	used := map[string]bool{}
+	for _, name := range Placeholders(strings.Join([]string{script, d.WorkingDirectory, d.TimeoutSeconds}, "\n")) {
+		if strings.Contains(name, ":") {
+			continue
+		}
+		used[name] = true
+		if _, exist := d.Parameters[name]; !exist {
+			warnings = append(warnings, fmt.Sprintf("Placeholder {{ %s }} has no parameter declaration", name))
+		}
+	}
+	names := []string{}
+	for name := range d.Parameters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if !used[name] {
+			warnings = append(warnings, fmt.Sprintf("Parameter %s is declared but never used", name))
+		}
+	}
+
+	return warnings, nil
+}
@@ -0,0 +1,77 @@
+package document
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseScriptAnnotations(t *testing.T) {
+	script := `#!/bin/bash
+# @param Message String "Greeting \"message\"" default="Hello World" maxChars=20
+# @param Retries Integer default=3 allowedValues=1,3,5
+#   @param Packages StringList default=curl,jq
+# @param Tags StringMap default="{\"Team\":\"platform\"}"
+# @param Token String default="{{ssm:/app/token}}"
+
+echo "{{ Message }}"
+# @param Ignored String
+`
+
+	parameters, err := ParseScriptAnnotations(script)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := map[string]Parameter{
+		"Message":  {Type: "String", Description: `Greeting "message"`, Default: "Hello World", MaxChars: 20},
+		"Retries":  {Type: "Integer", Default: 3, AllowedValues: []string{"1", "3", "5"}},
+		"Packages": {Type: "StringList", Default: []interface{}{"curl", "jq"}},
+		"Tags":     {Type: "StringMap", Default: map[string]interface{}{"Team": "platform"}},
+		"Token":    {Type: "String", Default: "{{ssm:/app/token}}"},
+	}
+	if !reflect.DeepEqual(parameters, expected) {
+		t.Errorf("unexpected parameters:\n%#v", parameters)
+	}
+
+	_, err = ParseScriptAnnotations("# @param Count Integer default=three\n")
+	if err == nil || !strings.Contains(err.Error(), "line 1") {
+		t.Errorf("expected invalid default error, got %v", err)
+	}
+	_, err = ParseScriptAnnotations("# @param Message String \"unterminated\n")
+	if err == nil {
+		t.Error("expected unterminated quote error")
+	}
+}
+
+func TestLoadScriptParameters(t *testing.T) {
+	d := &Document{
+		Name: "Test",
+		Parameters: map[string]Parameter{
+			"Message": {Type: "String", Default: "from config"},
+		},
+	}
+
+	warnings, err := d.LoadScriptParameters(`# @param Message String default="from script"
+# @param Unused String
+echo {{ Message }} {{ Undeclared }} {{ssm:/app/value}}
+`)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if d.Parameters["Message"].Default != "from config" {
+		t.Errorf("configuration parameter should take precedence, got %v", d.Parameters["Message"].Default)
+	}
+	if _, ok := d.Parameters["Unused"]; !ok {
+		t.Error("annotated parameter not added")
+	}
+
+	expected := []string{
+		"Placeholder {{ Undeclared }} has no parameter declaration",
+		"Parameter Unused is declared but never used",
+	}
+	if !reflect.DeepEqual(warnings, expected) {
+		t.Errorf("unexpected warnings: %v", warnings)
+	}
+}
@@ -0,0 +1,341 @@
+package document
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ssm"
+)
+
+// complianceSeverities are the allowed association compliance severities
+var complianceSeverities = []string{
+	ssm.AssociationComplianceSeverityCritical,
+	ssm.AssociationComplianceSeverityHigh,
+	ssm.AssociationComplianceSeverityMedium,
+	ssm.AssociationComplianceSeverityLow,
+	ssm.AssociationComplianceSeverityUnspecified,
+}
+
+// Target select the instances an association or a maintenance window task run on
+type Target struct {
+	Key    string     `yaml:"key" json:"key"`
+	Values StringList `yaml:"values" json:"values"`
+}
+
+// AssociationOutputLocation is the S3 location where commands output is stored
+type AssociationOutputLocation struct {
+	Bucket string `yaml:"bucket" json:"bucket"`
+	Prefix string `yaml:"prefix,omitempty" json:"prefix,omitempty"`
+	Region string `yaml:"region,omitempty" json:"region,omitempty"`
+}
+
+// Association is a State Manager association that run the document, tracked by name.
+// The deployed association name has the ManagedNamePrefix, associations without it are not managed.
+type Association struct {
+	Name               string                     `yaml:"name" json:"name"`
+	DocumentVersion    string                     `yaml:"documentVersion,omitempty" json:"documentVersion,omitempty"`
+	Targets            []Target                   `yaml:"targets" json:"targets"`
+	ScheduleExpression string                     `yaml:"scheduleExpression,omitempty" json:"scheduleExpression,omitempty"`
+	Parameters         map[string]StringList      `yaml:"parameters,omitempty" json:"parameters,omitempty"`
+	OutputLocation     *AssociationOutputLocation `yaml:"outputLocation,omitempty" json:"outputLocation,omitempty"`
+	ComplianceSeverity string                     `yaml:"complianceSeverity,omitempty" json:"complianceSeverity,omitempty"`
+	MaxConcurrency     string                     `yaml:"maxConcurrency,omitempty" json:"maxConcurrency,omitempty"`
+	MaxErrors          string                     `yaml:"maxErrors,omitempty" json:"maxErrors,omitempty"`
+}
+
+// Validate check association configuration
+func (a *Association) Validate() error {
+	if len(a.Name) == 0 {
+		return errors.New("name is required")
+	}
+	if len(a.Targets) == 0 {
+		return errors.New("at least one target is required")
+	}
+	for _, target := range a.Targets {
+		if len(target.Key) == 0 || len(target.Values) == 0 {
+			return errors.New("targets require key and values")
+		}
+	}
+	if a.OutputLocation != nil && len(a.OutputLocation.Bucket) == 0 {
+		return errors.New("outputLocation bucket is required")
+	}
+	if len(a.ComplianceSeverity) > 0 && !contains(complianceSeverities, a.ComplianceSeverity) {
+		return fmt.Errorf("complianceSeverity %s is not valid", a.ComplianceSeverity)
+	}
+	return nil
+}
+
+// normalized return the association with SSM defaults applied, used to detect changes
+func (a Association) normalized() Association {
+	if len(a.DocumentVersion) == 0 {
+		a.DocumentVersion = "$DEFAULT"
+	}
+	if len(a.Parameters) == 0 {
+		a.Parameters = nil
+	}
+	return a
+}
+
+// targets return the association targets in SSM format
+func (a *Association) targets() []*ssm.Target {
+	targets := []*ssm.Target{}
+	for _, target := range a.Targets {
+		targets = append(targets, &ssm.Target{
+			Key:    aws.String(target.Key),
+			Values: aws.StringSlice(target.Values),
+		})
+	}
+	return targets
+}
+
+// parameters return the association parameters in SSM format
+func (a *Association) parameters() map[string][]*string {
+	if len(a.Parameters) == 0 {
+		return nil
+	}
+	parameters := map[string][]*string{}
+	for name, values := range a.Parameters {
+		parameters[name] = aws.StringSlice(values)
+	}
+	return parameters
+}
+
+// outputLocation return the association output location in SSM format
+func (a *Association) outputLocation() *ssm.InstanceAssociationOutputLocation {
+	if a.OutputLocation == nil {
+		return nil
+	}
+	location := &ssm.S3OutputLocation{
+		OutputS3BucketName: aws.String(a.OutputLocation.Bucket),
+	}
+	if len(a.OutputLocation.Prefix) > 0 {
+		location.OutputS3KeyPrefix = aws.String(a.OutputLocation.Prefix)
+	}
+	if len(a.OutputLocation.Region) > 0 {
+		location.OutputS3Region = aws.String(a.OutputLocation.Region)
+	}
+	return &ssm.InstanceAssociationOutputLocation{S3Location: location}
+}
+
+// optionalString return nil for empty values
+func optionalString(value string) *string {
+	if len(value) == 0 {
+		return nil
+	}
+	return aws.String(value)
+}
+
+// associationFromDescription convert a deployed association to configuration
+func associationFromDescription(description *ssm.AssociationDescription) Association {
+	association := Association{
+		Name:               strings.TrimPrefix(aws.StringValue(description.AssociationName), ManagedNamePrefix),
+		DocumentVersion:    aws.StringValue(description.DocumentVersion),
+		ScheduleExpression: aws.StringValue(description.ScheduleExpression),
+		ComplianceSeverity: aws.StringValue(description.ComplianceSeverity),
+		MaxConcurrency:     aws.StringValue(description.MaxConcurrency),
+		MaxErrors:          aws.StringValue(description.MaxErrors),
+		Targets:            []Target{},
+	}
+	for _, target := range description.Targets {
+		association.Targets = append(association.Targets, Target{
+			Key:    aws.StringValue(target.Key),
+			Values: aws.StringValueSlice(target.Values),
+		})
+	}
+	for name, values := range description.Parameters {
+		if association.Parameters == nil {
+			association.Parameters = map[string]StringList{}
+		}
+		association.Parameters[name] = aws.StringValueSlice(values)
+	}
+	if description.OutputLocation != nil && description.OutputLocation.S3Location != nil {
+		association.OutputLocation = &AssociationOutputLocation{
+			Bucket: aws.StringValue(description.OutputLocation.S3Location.OutputS3BucketName),
+			Prefix: aws.StringValue(description.OutputLocation.S3Location.OutputS3KeyPrefix),
+			Region: aws.StringValue(description.OutputLocation.S3Location.OutputS3Region),
+		}
+	}
+	return association
+}
+
+// ValidateAssociations check associations configuration
+func (d *Document) ValidateAssociations() error {
+	if len(d.Associations) == 0 {
+		return nil
+	}
+	if !contains([]string{TypeCommand, TypePolicy, TypeAutomation}, d.Type) {
+		return fmt.Errorf("[%s] Associations are not supported for %s documents", d.Name, d.Type)
+	}
+
+	names := map[string]bool{}
+	for index, association := range d.Associations {
+		err := association.Validate()
+		if err != nil {
+			return fmt.Errorf("[%s] Association %d %s", d.Name, index+1, err)
+		}
+		if names[association.Name] {
+			return fmt.Errorf("[%s] Association %s is declared more than once", d.Name, association.Name)
+		}
+		names[association.Name] = true
+	}
+	return nil
+}
+
+// getAssociations return the associations of the document created by this tool, indexed by configured name
+func (d *Document) getAssociations(ctx context.Context) (map[string]*ssm.AssociationDescription, error) {
+	associations := map[string]*ssm.AssociationDescription{}
+
+	input := &ssm.ListAssociationsInput{
+		AssociationFilterList: []*ssm.AssociationFilter{
+			{
+				Key:   aws.String(ssm.AssociationFilterKeyName),
+				Value: aws.String(d.Name),
+			},
+		},
+	}
+	for {
+		res, err := d.clients.ssm.ListAssociationsWithContext(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, association := range res.Associations {
+			// Associations without name or prefix are not created by this tool
+			name := aws.StringValue(association.AssociationName)
+			if !strings.HasPrefix(name, ManagedNamePrefix) {
+				continue
+			}
+
+			// Load full association configuration
+			descriptionRes, err := d.clients.ssm.DescribeAssociationWithContext(ctx, &ssm.DescribeAssociationInput{
+				AssociationId: association.AssociationId,
+			})
+			if err != nil {
+				return nil, err
+			}
+			associations[strings.TrimPrefix(name, ManagedNamePrefix)] = descriptionRes.AssociationDescription
+		}
+
+		if res.NextToken == nil {
+			break
+		}
+		input.NextToken = res.NextToken
+	}
+
+	return associations, nil
+}
+
+// DeployAssociations reconcile document associations by name, changes are recorded into res.
+// When associations are not configured the deployed ones are left untouched,
+// associations not created by this tool are never updated or deleted.
+func (d *Document) DeployAssociations(ctx context.Context, res *Result) error {
+	if d.Associations == nil {
+		return nil
+	}
+
+	// Get deployed associations
+	current, err := d.getAssociations(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, association := range d.Associations {
+		deployed, ok := current[association.Name]
+		delete(current, association.Name)
+
+		// Create missing association
+		if !ok {
+			_, err = d.clients.ssm.CreateAssociationWithContext(ctx, &ssm.CreateAssociationInput{
+				Name:               &d.Name,
+				AssociationName:    aws.String(ManagedNamePrefix + association.Name),
+				DocumentVersion:    optionalString(association.DocumentVersion),
+				Targets:            association.targets(),
+				ScheduleExpression: optionalString(association.ScheduleExpression),
+				Parameters:         association.parameters(),
+				OutputLocation:     association.outputLocation(),
+				ComplianceSeverity: optionalString(association.ComplianceSeverity),
+				MaxConcurrency:     optionalString(association.MaxConcurrency),
+				MaxErrors:          optionalString(association.MaxErrors),
+			})
+			if err != nil {
+				return fmt.Errorf("[%s] Cannot create association %s: %w", d.Name, association.Name, err)
+			}
+			res.AssociationsCreated = append(res.AssociationsCreated, association.Name)
+			continue
+		}
+
+		// Skip unchanged association
+		if reflect.DeepEqual(association.normalized(), associationFromDescription(deployed).normalized()) {
+			continue
+		}
+
+		// Update association, all fields are sent since missing ones are reset
+		_, err = d.clients.ssm.UpdateAssociationWithContext(ctx, &ssm.UpdateAssociationInput{
+			AssociationId:      deployed.AssociationId,
+			Name:               &d.Name,
+			AssociationName:    aws.String(ManagedNamePrefix + association.Name),
+			DocumentVersion:    optionalString(association.DocumentVersion),
+			Targets:            association.targets(),
+			ScheduleExpression: optionalString(association.ScheduleExpression),
+			Parameters:         association.parameters(),
+			OutputLocation:     association.outputLocation(),
+			ComplianceSeverity: optionalString(association.ComplianceSeverity),
+			MaxConcurrency:     optionalString(association.MaxConcurrency),
+			MaxErrors:          optionalString(association.MaxErrors),
+		})
+		if err != nil {
+			return fmt.Errorf("[%s] Cannot update association %s: %w", d.Name, association.Name, err)
+		}
+		res.AssociationsUpdated = append(res.AssociationsUpdated, association.Name)
+	}
+
+	// Delete associations no more configured
+	for _, name := range sortedKeys(current) {
+		_, err = d.clients.ssm.DeleteAssociationWithContext(ctx, &ssm.DeleteAssociationInput{
+			AssociationId: current[name].AssociationId,
+		})
+		if err != nil {
+			return fmt.Errorf("[%s] Cannot delete association %s: %w", d.Name, name, err)
+		}
+		res.AssociationsRemoved = append(res.AssociationsRemoved, name)
+	}
+
+	return nil
+}
+
+// RemoveAssociations delete all the associations managed by this CLI for the document,
+// also the ones no more configured, changes are recorded into res
+func (d *Document) RemoveAssociations(ctx context.Context, res *Result) error {
+	// Get deployed associations
+	current, err := d.getAssociations(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range sortedKeys(current) {
+		_, err = d.clients.ssm.DeleteAssociationWithContext(ctx, &ssm.DeleteAssociationInput{
+			AssociationId: current[name].AssociationId,
+		})
+		if err != nil {
+			return fmt.Errorf("[%s] Cannot delete association %s: %w", d.Name, name, err)
+		}
+		res.AssociationsRemoved = append(res.AssociationsRemoved, name)
+	}
+
+	return nil
+}
+
+// sortedKeys return associations names in alphabetical order
+func sortedKeys(associations map[string]*ssm.AssociationDescription) []string {
+	names := []string{}
+	for name := range associations {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,167 @@
+package document
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/daaru00/aws-ssm-document-cli/pkg/ssmfake"
+	"gopkg.in/yaml.v2"
+)
+
+func deployAssociations(t *testing.T, d *Document) *Result {
+	t.Helper()
+
+	res := deploy(t, d)
+	err := d.DeployAssociations(context.Background(), res)
+	if err != nil {
+		t.Fatalf("unexpected associations deploy error: %s", err)
+	}
+	return res
+}
+
+func TestAssociationUnmarshal(t *testing.T) {
+	association := Association{}
+	err := yaml.Unmarshal([]byte(`
+name: nightly
+targets:
+  - key: tag:Env
+    values: prod
+parameters:
+  Message: hello
+maxConcurrency: 10
+maxErrors: 10%
+`), &association)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if association.MaxConcurrency != "10" || association.MaxErrors != "10%" {
+		t.Errorf("unexpected limits: %+v", association)
+	}
+	if !reflect.DeepEqual(association.Targets[0].Values, StringList{"prod"}) || !reflect.DeepEqual(association.Parameters["Message"], StringList{"hello"}) {
+		t.Errorf("unexpected lists: %+v", association)
+	}
+}
+
+func TestValidateAssociations(t *testing.T) {
+	cases := map[string][]Association{
+		"missing name":    {{Targets: []Target{{Key: "InstanceIds", Values: StringList{"*"}}}}},
+		"missing targets": {{Name: "a"}},
+		"duplicated":      {{Name: "a", Targets: []Target{{Key: "InstanceIds", Values: StringList{"*"}}}}, {Name: "a", Targets: []Target{{Key: "InstanceIds", Values: StringList{"*"}}}}},
+		"severity":        {{Name: "a", Targets: []Target{{Key: "InstanceIds", Values: StringList{"*"}}}, ComplianceSeverity: "URGENT"}},
+	}
+	for name, associations := range cases {
+		d := newTestDocument(ssmfake.New(), "Test", "echo 1")
+		d.Associations = associations
+		if d.ValidateAssociations() == nil {
+			t.Errorf("%s: expected validation error", name)
+		}
+	}
+}
+
+func TestDeployAssociations(t *testing.T) {
+	fake := ssmfake.New()
+	d := newTestDocument(fake, "Test", "echo 1")
+	d.Associations = []Association{
+		{
+			Name:               "nightly",
+			Targets:            []Target{{Key: "tag:Env", Values: StringList{"prod"}}},
+			ScheduleExpression: "cron(0 2 ? * * *)",
+			OutputLocation:     &AssociationOutputLocation{Bucket: "logs", Prefix: "ssm/"},
+			ComplianceSeverity: "HIGH",
+			MaxConcurrency:     "10%",
+			MaxErrors:          "1",
+		},
+		{
+			Name:    "hourly",
+			Targets: []Target{{Key: "InstanceIds", Values: StringList{"*"}}},
+		},
+	}
+
+	res := deployAssociations(t, d)
+	if !reflect.DeepEqual(res.AssociationsCreated, []string{"nightly", "hourly"}) {
+		t.Errorf("unexpected created associations: %v", res.AssociationsCreated)
+	}
+	stored := fake.Association(ManagedNamePrefix + "nightly")
+	if stored == nil || stored.DocumentName != "Test" || stored.OutputLocation.Bucket != "logs" || stored.MaxConcurrency != "10%" {
+		t.Fatalf("unexpected association: %+v", stored)
+	}
+
+	// Unchanged associations are not updated
+	res = deployAssociations(t, d)
+	if len(res.AssociationsCreated) > 0 || len(res.AssociationsUpdated) > 0 || len(res.AssociationsRemoved) > 0 {
+		t.Errorf("unexpected changes: %+v", res)
+	}
+	if fake.Calls("UpdateAssociation") != 0 {
+		t.Error("unchanged associations should not be updated")
+	}
+
+	// Changed associations are updated, missing ones deleted
+	d.Associations = d.Associations[:1]
+	d.Associations[0].ScheduleExpression = "rate(1 day)"
+	res = deployAssociations(t, d)
+	if !reflect.DeepEqual(res.AssociationsUpdated, []string{"nightly"}) || !reflect.DeepEqual(res.AssociationsRemoved, []string{"hourly"}) {
+		t.Errorf("unexpected changes: %+v", res)
+	}
+	if fake.Association(ManagedNamePrefix+"nightly").Version != 2 || fake.Association(ManagedNamePrefix+"hourly") != nil {
+		t.Error("associations not reconciled")
+	}
+}
+
+func TestDeployAssociationsNotConfigured(t *testing.T) {
+	fake := ssmfake.New()
+	d := newTestDocument(fake, "Test", "echo 1")
+	d.Associations = []Association{{Name: "nightly", Targets: []Target{{Key: "InstanceIds", Values: StringList{"*"}}}}}
+	deployAssociations(t, d)
+
+	// Associations managed elsewhere are left untouched
+	d.Associations = nil
+	deployAssociations(t, d)
+	if fake.Association(ManagedNamePrefix+"nightly") == nil {
+		t.Error("association should not be deleted")
+	}
+}
+
+func TestDeployAssociationsUnmanaged(t *testing.T) {
+	fake := ssmfake.New()
+	d := newTestDocument(fake, "Test", "echo 1")
+	deployAssociations(t, d)
+
+	// Associations created outside this tool are never deleted
+	_, err := fake.CreateAssociation(&ssm.CreateAssociationInput{
+		Name:            aws.String("Test"),
+		AssociationName: aws.String("manual"),
+		Targets:         []*ssm.Target{{Key: aws.String("InstanceIds"), Values: aws.StringSlice([]string{"*"})}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	d.Associations = []Association{}
+	res := deployAssociations(t, d)
+	if len(res.AssociationsRemoved) > 0 || fake.Association("manual") == nil {
+		t.Errorf("unmanaged association should not be deleted: %+v", res)
+	}
+}
+
+func TestRemoveAssociations(t *testing.T) {
+	fake := ssmfake.New()
+	d := newTestDocument(fake, "Test", "echo 1")
+	d.Associations = []Association{{Name: "nightly", Targets: []Target{{Key: "InstanceIds", Values: StringList{"*"}}}}}
+	deployAssociations(t, d)
+
+	// Managed associations are removed also when no more configured
+	d = newTestDocument(fake, "Test", "echo 1")
+	res := d.NewResult()
+	err := d.Remove(context.Background(), res)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if res.Action != ActionRemoved || !reflect.DeepEqual(res.AssociationsRemoved, []string{"nightly"}) {
+		t.Errorf("unexpected result: %+v", res)
+	}
+	if fake.Association(ManagedNamePrefix+"nightly") != nil || fake.Document("Test") != nil {
+		t.Error("association and document should be deleted")
+	}
+}
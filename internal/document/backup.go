@@ -0,0 +1,462 @@
+package document
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/aws/aws-sdk-go/service/ssm"
+)
+
+const (
+	// snapshotFile is the archive entry that hold the snapshot metadata
+	snapshotFile = "snapshot.json"
+	// snapshotVersionsDir is the archive directory that hold versions content
+	snapshotVersionsDir = "versions"
+	// backupsPrefix is the S3 key prefix of backups
+	backupsPrefix = "backups"
+)
+
+// BackupOptions configure where snapshots are stored, Bucket take precedence over Dir
+type BackupOptions struct {
+	Dir    string
+	Bucket string
+}
+
+// SnapshotVersion is a document version stored in a snapshot
+type SnapshotVersion struct {
+	Version     string        `json:"version"`
+	VersionName string        `json:"versionName,omitempty"`
+	Format      string        `json:"format"`
+	Hash        string        `json:"hash"`
+	Requires    []Requirement `json:"requires,omitempty"`
+	Content     string        `json:"-"`
+}
+
+// Snapshot is the deployed state of a document
+type Snapshot struct {
+	Name           string            `json:"name"`
+	Type           string            `json:"type"`
+	DefaultVersion string            `json:"defaultVersion"`
+	Tags           map[string]string `json:"tags"`
+	AccountIDs     []string          `json:"accountIds"`
+	Versions       []SnapshotVersion `json:"versions"`
+	Date           time.Time         `json:"date"`
+}
+
+// TakeSnapshot read all versions content, tags and share permissions of the deployed document
+func (d *Document) TakeSnapshot(ctx context.Context) (*Snapshot, error) {
+	describeRes, err := d.clients.ssm.DescribeDocumentWithContext(ctx, &ssm.DescribeDocumentInput{
+		Name: &d.Name,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := &Snapshot{
+		Name:           d.Name,
+		Type:           aws.StringValue(describeRes.Document.DocumentType),
+		DefaultVersion: aws.StringValue(describeRes.Document.DefaultVersion),
+		Tags:           map[string]string{},
+		AccountIDs:     []string{},
+		Date:           time.Now().UTC(),
+	}
+
+	// Read versions content
+	input := &ssm.ListDocumentVersionsInput{
+		Name: &d.Name,
+	}
+	for {
+		versionsRes, err := d.clients.ssm.ListDocumentVersionsWithContext(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+		for _, info := range versionsRes.DocumentVersions {
+			getRes, err := d.clients.ssm.GetDocumentWithContext(ctx, &ssm.GetDocumentInput{
+				Name:            &d.Name,
+				DocumentVersion: info.DocumentVersion,
+			})
+			if err != nil {
+				return nil, err
+			}
+
+			content := aws.StringValue(getRes.Content)
+			checksum := sha256.Sum256([]byte(content))
+			version := SnapshotVersion{
+				Version:     aws.StringValue(getRes.DocumentVersion),
+				VersionName: aws.StringValue(getRes.VersionName),
+				Format:      aws.StringValue(getRes.DocumentFormat),
+				Hash:        hex.EncodeToString(checksum[:]),
+				Content:     content,
+			}
+			for _, requires := range getRes.Requires {
+				version.Requires = append(version.Requires, Requirement{
+					Name:    aws.StringValue(requires.Name),
+					Version: aws.StringValue(requires.Version),
+				})
+			}
+			snapshot.Versions = append(snapshot.Versions, version)
+		}
+
+		if versionsRes.NextToken == nil {
+			break
+		}
+		input.NextToken = versionsRes.NextToken
+	}
+
+	// Versions are restored in order, listing order is not guaranteed
+	sort.SliceStable(snapshot.Versions, func(i, j int) bool {
+		first, _ := strconv.Atoi(snapshot.Versions[i].Version)
+		second, _ := strconv.Atoi(snapshot.Versions[j].Version)
+		return first < second
+	})
+
+	// Read tags
+	tagsRes, err := d.clients.ssm.ListTagsForResourceWithContext(ctx, &ssm.ListTagsForResourceInput{
+		ResourceId:   &d.Name,
+		ResourceType: aws.String("Document"),
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, tag := range tagsRes.TagList {
+		snapshot.Tags[aws.StringValue(tag.Key)] = aws.StringValue(tag.Value)
+	}
+
+	// Read share permissions
+	permInput := &ssm.DescribeDocumentPermissionInput{
+		Name:           &d.Name,
+		PermissionType: aws.String("Share"),
+	}
+	for {
+		permRes, err := d.clients.ssm.DescribeDocumentPermissionWithContext(ctx, permInput)
+		if err != nil {
+			return nil, err
+		}
+		snapshot.AccountIDs = append(snapshot.AccountIDs, aws.StringValueSlice(permRes.AccountIds)...)
+
+		if permRes.NextToken == nil {
+			break
+		}
+		permInput.NextToken = permRes.NextToken
+	}
+
+	return snapshot, nil
+}
+
+// versionFile return the archive entry of a version content
+func versionFile(version SnapshotVersion) string {
+	return path.Join(snapshotVersionsDir, version.Version+"."+strings.ToLower(version.Format))
+}
+
+// Archive encode the snapshot as a tar.gz archive, versions content is stored in separate files
+func (s *Snapshot) Archive() ([]byte, error) {
+	buffer := &bytes.Buffer{}
+	gzipWriter := gzip.NewWriter(buffer)
+	tarWriter := tar.NewWriter(gzipWriter)
+
+	metadata, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	files := []struct {
+		name    string
+		content []byte
+	}{
+		{snapshotFile, metadata},
+	}
+	for _, version := range s.Versions {
+		files = append(files, struct {
+			name    string
+			content []byte
+		}{versionFile(version), []byte(version.Content)})
+	}
+
+	for _, file := range files {
+		err = tarWriter.WriteHeader(&tar.Header{
+			Name:    file.name,
+			Mode:    0644,
+			Size:    int64(len(file.content)),
+			ModTime: s.Date,
+		})
+		if err != nil {
+			return nil, err
+		}
+		_, err = tarWriter.Write(file.content)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = tarWriter.Close()
+	if err != nil {
+		return nil, err
+	}
+	err = gzipWriter.Close()
+	if err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// ReadSnapshot decode a snapshot archive, versions content is checked against the stored hash
+func ReadSnapshot(archive []byte) (*Snapshot, error) {
+	gzipReader, err := gzip.NewReader(bytes.NewReader(archive))
+	if err != nil {
+		return nil, fmt.Errorf("Invalid snapshot archive: %s", err)
+	}
+	tarReader := tar.NewReader(gzipReader)
+
+	files := map[string][]byte{}
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("Invalid snapshot archive: %s", err)
+		}
+		content, err := ioutil.ReadAll(tarReader)
+		if err != nil {
+			return nil, err
+		}
+		files[header.Name] = content
+	}
+
+	metadata, ok := files[snapshotFile]
+	if !ok {
+		return nil, fmt.Errorf("Invalid snapshot archive: %s not found", snapshotFile)
+	}
+	snapshot := &Snapshot{}
+	err = json.Unmarshal(metadata, snapshot)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid snapshot archive: %s", err)
+	}
+	if len(snapshot.Name) == 0 || len(snapshot.Versions) == 0 {
+		return nil, errors.New("Invalid snapshot archive: name and versions are required")
+	}
+
+	for index, version := range snapshot.Versions {
+		content, ok := files[versionFile(version)]
+		if !ok {
+			return nil, fmt.Errorf("Invalid snapshot archive: content of version %s not found", version.Version)
+		}
+		checksum := sha256.Sum256(content)
+		if hex.EncodeToString(checksum[:]) != version.Hash {
+			return nil, fmt.Errorf("Invalid snapshot archive: content of version %s does not match its hash", version.Version)
+		}
+		snapshot.Versions[index].Content = string(content)
+	}
+
+	return snapshot, nil
+}
+
+// Backup snapshot the deployed document and store the archive, return the archive location
+func (d *Document) Backup(ctx context.Context, opts BackupOptions) (string, error) {
+	snapshot, err := d.TakeSnapshot(ctx)
+	if err != nil {
+		return "", err
+	}
+	archive, err := snapshot.Archive()
+	if err != nil {
+		return "", err
+	}
+	fileName := fmt.Sprintf("%s-%s.tar.gz", d.Name, snapshot.Date.Format("20060102T150405.000Z"))
+
+	// Upload to S3
+	if len(opts.Bucket) > 0 {
+		if d.clients.s3 == nil {
+			return "", errors.New("S3 client is not configured")
+		}
+		key := path.Join(backupsPrefix, d.Name, fileName)
+		_, err = d.clients.s3.PutObjectWithContext(ctx, &s3.PutObjectInput{
+			Bucket: aws.String(opts.Bucket),
+			Key:    aws.String(key),
+			Body:   bytes.NewReader(archive),
+		})
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("s3://%s/%s", opts.Bucket, key), nil
+	}
+
+	// Write to local directory
+	dir := filepath.Join(opts.Dir, d.Name)
+	err = os.MkdirAll(dir, 0755)
+	if err != nil {
+		return "", err
+	}
+	location := filepath.Join(dir, fileName)
+	return location, ioutil.WriteFile(location, archive, 0644)
+}
+
+// LoadSnapshot read a snapshot archive from a local path or an s3://bucket/key URL
+func LoadSnapshot(ctx context.Context, client s3iface.S3API, location string) (*Snapshot, error) {
+	if !strings.HasPrefix(location, "s3://") {
+		archive, err := ioutil.ReadFile(location)
+		if err != nil {
+			return nil, err
+		}
+		return ReadSnapshot(archive)
+	}
+
+	parts := strings.SplitN(strings.TrimPrefix(location, "s3://"), "/", 2)
+	if len(parts) != 2 || len(parts[0]) == 0 || len(parts[1]) == 0 {
+		return nil, fmt.Errorf("Invalid S3 location %s, use s3://bucket/key", location)
+	}
+	res, err := client.GetObjectWithContext(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(parts[0]),
+		Key:    aws.String(parts[1]),
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	archive, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	return ReadSnapshot(archive)
+}
+
+// IsContentChanged check if the generated content differ from the latest deployed version
+func (d *Document) IsContentChanged(ctx context.Context) (bool, error) {
+	_, content, err := d.GetContent()
+	if err != nil {
+		return false, err
+	}
+
+	res, err := d.clients.ssm.DescribeDocumentWithContext(ctx, &ssm.DescribeDocumentInput{
+		Name:            &d.Name,
+		DocumentVersion: aws.String("$LATEST"),
+	})
+	if err != nil {
+		return false, err
+	}
+
+	checksum := sha256.Sum256([]byte(*content))
+	return hex.EncodeToString(checksum[:]) != aws.StringValue(res.Document.Hash), nil
+}
+
+// Restore re-create the document from a snapshot, versions are created in order and the
+// snapshot default version is set as default. Changes are recorded into res.
+func (d *Document) Restore(ctx context.Context, snapshot *Snapshot, res *Result) error {
+	if d.IsDeployed(ctx) {
+		return fmt.Errorf("[%s] Document already exists, remove it before restoring", d.Name)
+	}
+
+	defaultVersion := ""
+	for index, version := range snapshot.Versions {
+		var created *ssm.DocumentDescription
+
+		requires := []*ssm.DocumentRequires{}
+		for _, requirement := range version.Requires {
+			requires = append(requires, &ssm.DocumentRequires{
+				Name:    aws.String(requirement.Name),
+				Version: requirement.GetVersion(),
+			})
+		}
+
+		if index == 0 {
+			// Create document with first version and tags
+			input := &ssm.CreateDocumentInput{
+				Name:           &d.Name,
+				DocumentType:   aws.String(snapshot.Type),
+				DocumentFormat: aws.String(version.Format),
+				Content:        aws.String(version.Content),
+				VersionName:    optionalString(version.VersionName),
+			}
+			if len(requires) > 0 {
+				input.Requires = requires
+			}
+			for key, value := range snapshot.Tags {
+				// Reserved tags are managed by AWS and cannot be set
+				if strings.HasPrefix(key, reservedTagPrefix) {
+					continue
+				}
+				input.Tags = append(input.Tags, &ssm.Tag{
+					Key:   aws.String(key),
+					Value: aws.String(value),
+				})
+			}
+			createRes, err := d.clients.ssm.CreateDocumentWithContext(ctx, input)
+			if err != nil {
+				return err
+			}
+			created = createRes.DocumentDescription
+			res.Action = ActionRestored
+		} else {
+			// Add next version
+			updateRes, err := d.clients.ssm.UpdateDocumentWithContext(ctx, &ssm.UpdateDocumentInput{
+				Name:            &d.Name,
+				DocumentFormat:  aws.String(version.Format),
+				Content:         aws.String(version.Content),
+				VersionName:     optionalString(version.VersionName),
+				DocumentVersion: aws.String("$LATEST"),
+			})
+			if err != nil {
+				return err
+			}
+			created = updateRes.DocumentDescription
+		}
+
+		// Versions numbers may differ from the snapshot ones
+		res.Version = aws.StringValue(created.DocumentVersion)
+		if version.Version == snapshot.DefaultVersion {
+			defaultVersion = res.Version
+		}
+	}
+
+	// Set default version
+	if len(defaultVersion) > 0 && defaultVersion != "1" {
+		_, err := d.clients.ssm.UpdateDocumentDefaultVersionWithContext(ctx, &ssm.UpdateDocumentDefaultVersionInput{
+			Name:            &d.Name,
+			DocumentVersion: aws.String(defaultVersion),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if len(defaultVersion) > 0 {
+		res.Version = defaultVersion
+	}
+
+	// Restore share permissions
+	for i := 0; i < len(snapshot.AccountIDs); i += permissionsChunkSize {
+		end := i + permissionsChunkSize
+		if end > len(snapshot.AccountIDs) {
+			end = len(snapshot.AccountIDs)
+		}
+
+		_, err := d.clients.ssm.ModifyDocumentPermissionWithContext(ctx, &ssm.ModifyDocumentPermissionInput{
+			Name:            &d.Name,
+			PermissionType:  aws.String("Share"),
+			AccountIdsToAdd: aws.StringSlice(snapshot.AccountIDs[i:end]),
+		})
+		if err != nil {
+			return err
+		}
+		res.AccountsAdded = append(res.AccountsAdded, snapshot.AccountIDs[i:end]...)
+	}
+
+	return nil
+}
@@ -0,0 +1,196 @@
+package document
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/daaru00/aws-ssm-document-cli/pkg/ssmfake"
+)
+
+func TestSnapshotRestore(t *testing.T) {
+	fake := ssmfake.New()
+	d := newTestDocument(fake, "Test", "echo 1")
+	d.Tags = map[string]string{"Team": "ops"}
+	d.AccountIDs = accountIDs(25)
+	deploy(t, d)
+	d = newTestDocument(fake, "Test", "echo 2")
+	d.Tags = map[string]string{"Team": "ops"}
+	d.AccountIDs = accountIDs(25)
+	deploy(t, d)
+
+	// Roll back default version
+	_, err := fake.UpdateDocumentDefaultVersion(&ssm.UpdateDocumentDefaultVersionInput{
+		Name:            aws.String("Test"),
+		DocumentVersion: aws.String("1"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	original := *fake.Document("Test")
+
+	// Reserved tags are set by AWS and cannot be restored
+	fake.Document("Test").Tags["aws:cloudformation:stack-name"] = "stack"
+
+	snapshot, err := d.TakeSnapshot(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected snapshot error: %s", err)
+	}
+	archive, err := snapshot.Archive()
+	if err != nil {
+		t.Fatalf("unexpected archive error: %s", err)
+	}
+	snapshot, err = ReadSnapshot(archive)
+	if err != nil {
+		t.Fatalf("unexpected read error: %s", err)
+	}
+	if len(snapshot.Versions) != 2 || snapshot.DefaultVersion != "1" || !strings.Contains(snapshot.Versions[1].Content, "echo 2") {
+		t.Errorf("unexpected snapshot: %+v", snapshot)
+	}
+
+	err = d.Remove(context.Background(), d.NewResult())
+	if err != nil {
+		t.Fatalf("unexpected remove error: %s", err)
+	}
+
+	res := d.NewResult()
+	err = d.Restore(context.Background(), snapshot, res)
+	if err != nil {
+		t.Fatalf("unexpected restore error: %s", err)
+	}
+
+	restored := fake.Document("Test")
+	if res.Action != ActionRestored || res.Version != "1" {
+		t.Errorf("unexpected result: %+v", res)
+	}
+	if restored.DefaultVersion != "1" || len(restored.Versions) != 2 {
+		t.Errorf("unexpected versions: %+v", restored)
+	}
+	for index, version := range restored.Versions {
+		if version.Content != original.Versions[index].Content {
+			t.Errorf("unexpected content of version %s: %s", version.Version, version.Content)
+		}
+	}
+	if !reflect.DeepEqual(restored.Tags, map[string]string{"Team": "ops", ManagedByTagKey: ManagedByTagValue}) {
+		t.Errorf("unexpected tags: %v", restored.Tags)
+	}
+	if len(restored.AccountIDs) != 25 {
+		t.Errorf("expected 25 shared accounts, got %d", len(restored.AccountIDs))
+	}
+}
+
+func TestSnapshotVersionsOrder(t *testing.T) {
+	fake := ssmfake.New()
+	d := newTestDocument(fake, "Test", "echo 1")
+	for index := 1; index <= 11; index++ {
+		d = newTestDocument(fake, "Test", fmt.Sprintf("echo %d", index))
+		deploy(t, d)
+	}
+
+	// SSM list newest versions first
+	stored := fake.Document("Test")
+	for i, j := 0, len(stored.Versions)-1; i < j; i, j = i+1, j-1 {
+		stored.Versions[i], stored.Versions[j] = stored.Versions[j], stored.Versions[i]
+	}
+
+	snapshot, err := d.TakeSnapshot(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	versions := []string{}
+	for _, version := range snapshot.Versions {
+		versions = append(versions, version.Version)
+	}
+	expected := []string{"1", "2", "3", "4", "5", "6", "7", "8", "9", "10", "11"}
+	if !reflect.DeepEqual(versions, expected) {
+		t.Errorf("unexpected versions order: %v", versions)
+	}
+}
+
+func TestRestoreExisting(t *testing.T) {
+	fake := ssmfake.New()
+	d := newTestDocument(fake, "Test", "echo 1")
+	deploy(t, d)
+
+	snapshot, err := d.TakeSnapshot(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = d.Restore(context.Background(), snapshot, d.NewResult())
+	if err == nil {
+		t.Error("expected error restoring a deployed document")
+	}
+}
+
+func TestBackupDir(t *testing.T) {
+	fake := ssmfake.New()
+	d := newTestDocument(fake, "Test", "echo 1")
+	deploy(t, d)
+	dir := t.TempDir()
+
+	location, err := d.Backup(context.Background(), BackupOptions{Dir: dir})
+	if err != nil {
+		t.Fatalf("unexpected backup error: %s", err)
+	}
+	if filepath.Dir(location) != filepath.Join(dir, "Test") {
+		t.Errorf("unexpected location: %s", location)
+	}
+
+	snapshot, err := LoadSnapshot(context.Background(), nil, location)
+	if err != nil {
+		t.Fatalf("unexpected load error: %s", err)
+	}
+	if snapshot.Name != "Test" || len(snapshot.Versions) != 1 {
+		t.Errorf("unexpected snapshot: %+v", snapshot)
+	}
+}
+
+func TestReadSnapshotTampered(t *testing.T) {
+	snapshot := &Snapshot{
+		Name:     "Test",
+		Versions: []SnapshotVersion{{Version: "1", Format: "JSON", Hash: "invalid", Content: "{}"}},
+	}
+	archive, err := snapshot.Archive()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = ReadSnapshot(archive)
+	if err == nil || !strings.Contains(err.Error(), "hash") {
+		t.Errorf("expected hash error, got %v", err)
+	}
+
+	archive, err = (&Snapshot{Name: "Test"}).Archive()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = ReadSnapshot(archive)
+	if err == nil {
+		t.Error("expected error for snapshot without versions")
+	}
+
+	_, err = ReadSnapshot([]byte("not an archive"))
+	if err == nil {
+		t.Error("expected invalid archive error")
+	}
+}
+
+func TestIsContentChanged(t *testing.T) {
+	fake := ssmfake.New()
+	deploy(t, newTestDocument(fake, "Test", "echo 1"))
+
+	changed, err := newTestDocument(fake, "Test", "echo 1").IsContentChanged(context.Background())
+	if err != nil || changed {
+		t.Errorf("expected unchanged content, got %t %v", changed, err)
+	}
+	changed, err = newTestDocument(fake, "Test", "echo 2").IsContentChanged(context.Background())
+	if err != nil || !changed {
+		t.Errorf("expected changed content, got %t %v", changed, err)
+	}
+}
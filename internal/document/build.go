@@ -0,0 +1,198 @@
+package document
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/ssm"
+)
+
+// BuildOptions configure how script files are included in the document
+type BuildOptions struct {
+	// Upload scripts as attachments instead of inlining them
+	Upload bool
+	// Bucket where attachments are uploaded
+	Bucket string
+}
+
+// Build resolve steps script files, scripts are inlined in step inputs
+// or uploaded to S3 and attached to the document
+func (d *Document) Build(ctx context.Context, opts BuildOptions) error {
+	if opts.Upload && len(opts.Bucket) == 0 {
+		return fmt.Errorf("[%s] A sources bucket is required to upload scripts", d.Name)
+	}
+
+	steps, attachments, err := d.buildSteps(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	d.Content.MainSteps = steps
+	d.attachments = attachments
+	return nil
+}
+
+// UnresolvedScriptFile return the name of the first step that reference a script file not yet built
+func (d *Document) UnresolvedScriptFile() string {
+	for _, step := range d.Content.MainSteps {
+		if step.Action != ActionExecuteScript {
+			continue
+		}
+		inputs, err := step.DecodeInputs()
+		if err == nil && len(inputs.(*ExecuteScriptInput).ScriptFile) > 0 {
+			return step.Name
+		}
+	}
+	return ""
+}
+
+// buildSteps return a copy of content steps with script files resolved
+func (d *Document) buildSteps(ctx context.Context, opts BuildOptions) ([]MainStep, []*ssm.AttachmentsSource, error) {
+	steps := []MainStep{}
+	attachments := []*ssm.AttachmentsSource{}
+
+	for _, step := range d.Content.MainSteps {
+		if step.Action != ActionExecuteScript {
+			steps = append(steps, step)
+			continue
+		}
+
+		decoded, err := step.DecodeInputs()
+		if err != nil {
+			return nil, nil, fmt.Errorf("[%s] Step %s %s", d.Name, step.Name, err)
+		}
+		inputs := *decoded.(*ExecuteScriptInput)
+		if len(inputs.ScriptFile) == 0 {
+			steps = append(steps, step)
+			continue
+		}
+
+		// Load script
+		scriptPath := inputs.ScriptFile
+		if !filepath.IsAbs(scriptPath) {
+			scriptPath = filepath.Join(d.Dir, scriptPath)
+		}
+		script, err := ioutil.ReadFile(scriptPath)
+		if err != nil {
+			return nil, nil, fmt.Errorf("[%s] Step %s: %s", d.Name, step.Name, err)
+		}
+		err = checkScriptHandler(inputs.Runtime, inputs.Handler, filepath.Ext(scriptPath), string(script))
+		if err != nil {
+			return nil, nil, fmt.Errorf("[%s] Step %s: %s", d.Name, step.Name, err)
+		}
+
+		// Map document parameters as script input
+		if len(inputs.InputPayload) == 0 {
+			inputs.InputPayload = d.defaultInputPayload()
+		}
+
+		// Include script
+		inputs.ScriptFile = ""
+		if opts.Upload {
+			attachment, err := d.uploadScript(ctx, opts.Bucket, filepath.Base(scriptPath), script)
+			if err != nil {
+				return nil, nil, fmt.Errorf("[%s] Step %s: %s", d.Name, step.Name, err)
+			}
+			attachments = append(attachments, attachment)
+			inputs.Attachment = aws.StringValue(attachment.Name)
+			if isPythonRuntime(inputs.Runtime) {
+				module := strings.TrimSuffix(filepath.Base(scriptPath), filepath.Ext(scriptPath))
+				inputs.Handler = module + "." + inputs.Handler
+			}
+		} else {
+			inputs.Script = string(script)
+		}
+
+		step.Inputs = &inputs
+		steps = append(steps, step)
+	}
+
+	return steps, attachments, nil
+}
+
+// defaultInputPayload map each document parameter to the script input with the same name
+func (d *Document) defaultInputPayload() map[string]interface{} {
+	names := []string{}
+	for name := range d.Content.Parameters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	payload := map[string]interface{}{}
+	for _, name := range names {
+		if d.Content.AssumeRole == "{{ "+name+" }}" || d.Content.AssumeRole == "{{"+name+"}}" {
+			continue
+		}
+		payload[name] = "{{ " + name + " }}"
+	}
+	if len(payload) == 0 {
+		return nil
+	}
+	return payload
+}
+
+// uploadScript upload the script to the bucket, key contains the content hash so each version is kept
+func (d *Document) uploadScript(ctx context.Context, bucket string, name string, script []byte) (*ssm.AttachmentsSource, error) {
+	if d.clients.s3 == nil {
+		return nil, fmt.Errorf("S3 client is not configured")
+	}
+
+	checksum := sha256.Sum256(script)
+	key := fmt.Sprintf("%s/%s/%s", d.Name, hex.EncodeToString(checksum[:])[:16], name)
+	_, err := d.clients.s3.PutObjectWithContext(ctx, &s3.PutObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+		Body:   bytes.NewReader(script),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &ssm.AttachmentsSource{
+		Key:    aws.String(ssm.AttachmentsSourceKeyS3fileUrl),
+		Name:   aws.String(name),
+		Values: aws.StringSlice([]string{fmt.Sprintf("s3://%s/%s", bucket, key)}),
+	}, nil
+}
+
+// isPythonRuntime check if runtime is a Python one
+func isPythonRuntime(runtime string) bool {
+	return strings.HasPrefix(strings.ToLower(runtime), "python")
+}
+
+// checkScriptHandler check that script match the runtime and declare the handler function
+func checkScriptHandler(runtime string, handler string, extension string, script string) error {
+	var definition *regexp.Regexp
+	switch strings.ToLower(extension) {
+	case ".py":
+		if !isPythonRuntime(runtime) {
+			return fmt.Errorf("Python script requires a Python runtime, got %q", runtime)
+		}
+		definition = regexp.MustCompile(`(?m)^def\s+` + regexp.QuoteMeta(handler) + `\s*\(`)
+	case ".ps1":
+		if !strings.HasPrefix(strings.ToLower(runtime), "powershell") {
+			return fmt.Errorf("PowerShell script requires a PowerShell runtime, got %q", runtime)
+		}
+		if len(handler) == 0 {
+			return nil
+		}
+		definition = regexp.MustCompile(`(?mi)^\s*function\s+` + regexp.QuoteMeta(handler) + `\b`)
+	default:
+		return fmt.Errorf("Script file extension %s is not supported, use .py or .ps1", extension)
+	}
+
+	if !definition.MatchString(script) {
+		return fmt.Errorf("Handler function %s not found in script", handler)
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package document
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"gopkg.in/yaml.v2"
+)
+
+type fakeS3 struct {
+	s3iface.S3API
+	keys []string
+}
+
+func (f *fakeS3) PutObjectWithContext(ctx aws.Context, input *s3.PutObjectInput, opts ...request.Option) (*s3.PutObjectOutput, error) {
+	f.keys = append(f.keys, *input.Bucket+"/"+*input.Key)
+	return &s3.PutObjectOutput{}, nil
+}
+
+func newScriptDocument(t *testing.T, script string, handler string) *Document {
+	dir, err := ioutil.TempDir("", "build")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	ioutil.WriteFile(filepath.Join(dir, "handler.py"), []byte(script), 0644)
+
+	d := NewWithClient(nil, aws.String("us-east-1"), "Script")
+	d.Type = TypeAutomation
+	d.Dir = dir
+	err = yaml.Unmarshal([]byte(`
+schemaVersion: "0.3"
+assumeRole: "{{ AutomationAssumeRole }}"
+parameters:
+  AutomationAssumeRole: {type: String, default: ""}
+  InstanceId: {type: String}
+mainSteps:
+  - action: aws:executeScript
+    name: run
+    inputs:
+      Runtime: python3.8
+      Handler: `+handler+`
+      scriptFile: ./handler.py
+`), &d.Content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+func TestBuildInline(t *testing.T) {
+	d := newScriptDocument(t, "def handler(events, context):\n    return events\n", "handler")
+	if d.UnresolvedScriptFile() != "run" {
+		t.Fatal("expected unresolved script file")
+	}
+
+	err := d.Build(context.Background(), BuildOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(d.UnresolvedScriptFile()) > 0 {
+		t.Error("script file not resolved")
+	}
+
+	_, content, err := d.GetContent()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !strings.Contains(*content, `"Script":"def handler(events, context):\n    return events\n"`) ||
+		!strings.Contains(*content, `"InputPayload":{"InstanceId":"{{ InstanceId }}"}`) ||
+		strings.Contains(*content, "scriptFile") {
+		t.Errorf("unexpected content: %s", *content)
+	}
+}
+
+func TestBuildUpload(t *testing.T) {
+	d := newScriptDocument(t, "def handler(events, context):\n    return events\n", "handler")
+	client := &fakeS3{}
+	d.clients.s3 = client
+
+	err := d.Build(context.Background(), BuildOptions{Upload: true, Bucket: "sources"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(client.keys) != 1 || !strings.HasPrefix(client.keys[0], "sources/Script/") || len(d.attachments) != 1 {
+		t.Fatalf("script not uploaded: %v", client.keys)
+	}
+	inputs, _ := d.Content.MainSteps[0].DecodeInputs()
+	script := inputs.(*ExecuteScriptInput)
+	if script.Attachment != "handler.py" || script.Handler != "handler.handler" || len(script.Script) > 0 {
+		t.Errorf("unexpected inputs: %+v", script)
+	}
+}
+
+func TestBuildMissingHandler(t *testing.T) {
+	d := newScriptDocument(t, "def other(events, context):\n    return events\n", "handler")
+
+	err := d.Build(context.Background(), BuildOptions{})
+	if err == nil || !strings.Contains(err.Error(), "Handler function handler not found") {
+		t.Errorf("expected missing handler error, got %v", err)
+	}
+}
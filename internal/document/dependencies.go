@@ -0,0 +1,103 @@
+package document
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"gopkg.in/yaml.v2"
+)
+
+// Requirement of another document
+type Requirement struct {
+	Name    string `yaml:"name" json:"name"`
+	Version string `yaml:"version,omitempty" json:"version,omitempty"`
+}
+
+// GetVersion return the required version, nil if not set
+func (r *Requirement) GetVersion() *string {
+	if len(r.Version) == 0 {
+		return nil
+	}
+	return aws.String(r.Version)
+}
+
+// referenceContent is the minimal content structure needed to find references
+type referenceContent struct {
+	MainSteps []struct {
+		Action string                 `yaml:"action"`
+		Inputs map[string]interface{} `yaml:"inputs"`
+	} `yaml:"mainSteps"`
+}
+
+// GetDependencies return the names of documents this document depends on,
+// collected from dependsOn, requires and steps that execute other documents
+func (d *Document) GetDependencies() ([]string, error) {
+	dependencies := map[string]bool{}
+
+	// Add explicit dependencies
+	for _, name := range d.DependsOn {
+		dependencies[name] = true
+	}
+	for _, requirement := range d.Requires {
+		dependencies[documentNameFromReference(requirement.Name)] = true
+	}
+
+	// Parse content searching for steps that execute other documents
+	format, content, err := d.GetContent()
+	if err != nil {
+		return nil, err
+	}
+	if *format != "TEXT" {
+		parsed := referenceContent{}
+		err = yaml.Unmarshal([]byte(*content), &parsed)
+		if err != nil {
+			return nil, fmt.Errorf("[%s] Cannot parse content: %s", d.Name, err)
+		}
+
+		for _, step := range parsed.MainSteps {
+			var reference interface{}
+			switch step.Action {
+			case "aws:runDocument":
+				if documentType, ok := step.Inputs["documentType"]; ok && documentType != "SSMDocument" {
+					continue
+				}
+				reference = step.Inputs["documentPath"]
+			case "aws:executeAutomation":
+				reference = step.Inputs["DocumentName"]
+			default:
+				continue
+			}
+
+			// Skip references resolved at runtime
+			name, ok := reference.(string)
+			if !ok || len(name) == 0 || strings.Contains(name, "{{") {
+				continue
+			}
+			dependencies[documentNameFromReference(name)] = true
+		}
+	}
+
+	// Return sorted names, without self reference
+	names := []string{}
+	for name := range dependencies {
+		if name != d.Name {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	return names, nil
+}
+
+// documentNameFromReference extract the document name from an ARN
+func documentNameFromReference(reference string) string {
+	if strings.HasPrefix(reference, "arn:") {
+		index := strings.LastIndex(reference, "document/")
+		if index != -1 {
+			return reference[index+len("document/"):]
+		}
+	}
+	return reference
+}
@@ -0,0 +1,34 @@
+package document
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGetDependencies(t *testing.T) {
+	d := &Document{
+		Name:      "Main",
+		DependsOn: []string{"Explicit"},
+		Requires:  []Requirement{{Name: "arn:aws:ssm:us-east-1:123456789012:document/Required"}},
+		Content: Content{
+			SchemaVersion: "2.2",
+			MainSteps: []MainStep{
+				{Action: "aws:runDocument", Name: "run", Inputs: map[string]interface{}{"documentType": "SSMDocument", "documentPath": "Child"}},
+				{Action: "aws:runDocument", Name: "remote", Inputs: map[string]interface{}{"documentType": "LocalPath", "documentPath": "./script"}},
+				{Action: "aws:executeAutomation", Name: "automation", Inputs: map[string]interface{}{"DocumentName": "Automation"}},
+				{Action: "aws:executeAutomation", Name: "dynamic", Inputs: map[string]interface{}{"DocumentName": "{{ DocumentName }}"}},
+				{Action: "aws:runDocument", Name: "self", Inputs: map[string]interface{}{"documentPath": "Main"}},
+			},
+		},
+	}
+
+	dependencies, err := d.GetDependencies()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := []string{"Automation", "Child", "Explicit", "Required"}
+	if !reflect.DeepEqual(dependencies, expected) {
+		t.Errorf("expected %v, got %v", expected, dependencies)
+	}
+}
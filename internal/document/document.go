@@ -1,6 +1,7 @@
 package document
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io/ioutil"
@@ -11,26 +12,47 @@ import (
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
 	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/aws/aws-sdk-go/service/ssm/ssmiface"
 	jsoniter "github.com/json-iterator/go"
 )
 
 type clients struct {
-	ssm *ssm.SSM
+	ssm ssmiface.SSMAPI
+	s3  s3iface.S3API
 }
 
-// ShellInput content for shell document
+// ShellInput content for shell document, inputs of aws:runShellScript and aws:runPowerShellScript
 type ShellInput struct {
-	WorkingDirectory string   `yaml:"workingDirectory" json:"workingDirectory"`
-	RunCommand       []string `yaml:"runCommand" json:"runCommand"`
-	TimeoutSeconds   string   `yaml:"timeoutSeconds" json:"timeoutSeconds"`
+	WorkingDirectory string     `yaml:"workingDirectory" json:"workingDirectory"`
+	RunCommand       StringList `yaml:"runCommand" json:"runCommand"`
+	TimeoutSeconds   string     `yaml:"timeoutSeconds" json:"timeoutSeconds"`
 }
 
-// MainStep content for document
+// MainStep content for document, inputs are kept as they are, use DecodeInputs to get the typed action inputs
 type MainStep struct {
-	Action string      `yaml:"action" json:"action"`
-	Name   string      `yaml:"name" json:"name"`
-	Inputs interface{} `yaml:"inputs,omitempty" json:"inputs,omitempty"`
+	Action         string       `yaml:"action" json:"action"`
+	Name           string       `yaml:"name" json:"name"`
+	Description    string       `yaml:"description,omitempty" json:"description,omitempty"`
+	Precondition   interface{}  `yaml:"precondition,omitempty" json:"precondition,omitempty"`
+	OnFailure      string       `yaml:"onFailure,omitempty" json:"onFailure,omitempty"`
+	OnCancel       string       `yaml:"onCancel,omitempty" json:"onCancel,omitempty"`
+	IsEnd          bool         `yaml:"isEnd,omitempty" json:"isEnd,omitempty"`
+	IsCritical     *bool        `yaml:"isCritical,omitempty" json:"isCritical,omitempty"`
+	NextStep       string       `yaml:"nextStep,omitempty" json:"nextStep,omitempty"`
+	MaxAttempts    int          `yaml:"maxAttempts,omitempty" json:"maxAttempts,omitempty"`
+	TimeoutSeconds int          `yaml:"timeoutSeconds,omitempty" json:"timeoutSeconds,omitempty"`
+	Inputs         interface{}  `yaml:"inputs,omitempty" json:"inputs,omitempty"`
+	Outputs        []StepOutput `yaml:"outputs,omitempty" json:"outputs,omitempty"`
+}
+
+// StepOutput is an automation step output
+type StepOutput struct {
+	Name     string `yaml:"Name" json:"Name"`
+	Selector string `yaml:"Selector" json:"Selector"`
+	Type     string `yaml:"Type" json:"Type"`
 }
 
 // Content document
@@ -39,42 +61,79 @@ type Content struct {
 	Description   string               `yaml:"description" json:"description"`
 	Parameters    map[string]Parameter `yaml:"parameters" json:"parameters"`
 	MainSteps     []MainStep           `yaml:"mainSteps" json:"mainSteps"`
-}
 
-// Parameter configuration
-type Parameter struct {
-	Type        string `yaml:"type" json:"type"`
-	Description string `yaml:"description,omitempty" json:"description,omitempty"`
-	Default     string `yaml:"default,omitempty" json:"default,omitempty"`
+	// Automation documents
+	AssumeRole string   `yaml:"assumeRole,omitempty" json:"assumeRole,omitempty"`
+	Outputs    []string `yaml:"outputs,omitempty" json:"outputs,omitempty"`
+
+	// Session documents
+	SessionType string             `yaml:"sessionType,omitempty" json:"sessionType,omitempty"`
+	Inputs      *SessionInputs     `yaml:"inputs,omitempty" json:"inputs,omitempty"`
+	Properties  *SessionProperties `yaml:"properties,omitempty" json:"properties,omitempty"`
 }
 
 // Document structure
 type Document struct {
-	clients *clients
-	region  *string
-
-	Name             string               `yaml:"name" json:"name"`
-	Description      string               `yaml:"description" json:"description"`
-	Type             string               `yaml:"type" json:"type"`
-	AccountIDs       []string             `yaml:"accountIds" json:"accountIds"`
-	Tags             map[string]string    `yaml:"tags" json:"tags"`
-	Content          Content              `yaml:"content,omitempty" json:"content,omitempty"`
-	Parameters       map[string]Parameter `yaml:"parameters,omitempty" json:"parameters,omitempty"`
-	WorkingDirectory string               `yaml:"workingDirectory" json:"workingDirectory"`
-	TimeoutSeconds   string               `yaml:"timeoutSeconds,omitempty" json:"timeoutSeconds,omitempty"`
-	Format           string               `yaml:"format" json:"format"`
-	File             string               `yaml:"file" json:"file"`
+	clients     *clients
+	region      *string
+	attachments []*ssm.AttachmentsSource
+
+	Name               string                  `yaml:"name" json:"name"`
+	Description        string                  `yaml:"description" json:"description"`
+	Type               string                  `yaml:"type" json:"type"`
+	AccountIDs         []string                `yaml:"accountIds" json:"accountIds"`
+	Tags               map[string]string       `yaml:"tags" json:"tags"`
+	Content            Content                 `yaml:"content,omitempty" json:"content,omitempty"`
+	Parameters         map[string]Parameter    `yaml:"parameters,omitempty" json:"parameters,omitempty"`
+	WorkingDirectory   string                  `yaml:"workingDirectory" json:"workingDirectory"`
+	TimeoutSeconds     string                  `yaml:"timeoutSeconds,omitempty" json:"timeoutSeconds,omitempty"`
+	Format             string                  `yaml:"format" json:"format"`
+	File               string                  `yaml:"file" json:"file"`
+	Requires           []Requirement           `yaml:"requires,omitempty" json:"requires,omitempty"`
+	DependsOn          []string                `yaml:"dependsOn,omitempty" json:"dependsOn,omitempty"`
+	Tests              []TestCase              `yaml:"tests,omitempty" json:"tests,omitempty"`
+	Package            *Package                `yaml:"package,omitempty" json:"package,omitempty"`
+	RequireApproval    bool                    `yaml:"requireApproval,omitempty" json:"requireApproval,omitempty"`
+	Protected          bool                    `yaml:"protected,omitempty" json:"protected,omitempty"`
+	Associations       []Association           `yaml:"associations,omitempty" json:"associations,omitempty"`
+	MaintenanceWindows []MaintenanceWindowTask `yaml:"maintenanceWindows,omitempty" json:"maintenanceWindows,omitempty"`
+
+	// Warnings found loading the document
+	Warnings []string `yaml:"-" json:"-"`
+	// Dir is the configuration file directory, relative paths are resolved from it
+	Dir string `yaml:"-" json:"-"`
+	// ConfigFile is the path of the configuration file
+	ConfigFile string `yaml:"-" json:"-"`
+	// ReviewComment is attached to versions submitted for review
+	ReviewComment string `yaml:"-" json:"-"`
+	// Project is written in the ownership marker tag
+	Project string `yaml:"-" json:"-"`
+	// Adopt allow to update and remove documents without the ownership marker
+	Adopt bool `yaml:"-" json:"-"`
+	// KeepTagPrefixes are the prefixes of deployed tags not managed by configuration
+	KeepTagPrefixes []string `yaml:"-" json:"-"`
+	// KeepShared only unshare shared documents on remove, without deleting them
+	KeepShared bool `yaml:"-" json:"-"`
+	// ForceProtected allow to remove and unshare protected documents
+	ForceProtected bool `yaml:"-" json:"-"`
 }
 
 // New creates a new Document
 func New(ses *session.Session, name string) *Document {
+	d := NewWithClient(ssm.New(ses), ses.Config.Region, name)
+	d.clients.s3 = s3.New(ses)
+	return d
+}
+
+// NewWithClient creates a new Document that use the provided SSM client
+func NewWithClient(client ssmiface.SSMAPI, region *string, name string) *Document {
 	clients := &clients{
-		ssm: ssm.New(ses),
+		ssm: client,
 	}
 
 	return &Document{
 		clients: clients,
-		region:  ses.Config.Region,
+		region:  region,
 
 		Name: name,
 		Type: "Command",
@@ -82,8 +141,8 @@ func New(ses *session.Session, name string) *Document {
 }
 
 // IsDeployed check if document name is present in current AWS account
-func (d *Document) IsDeployed() bool {
-	_, err := d.clients.ssm.GetDocument(&ssm.GetDocumentInput{
+func (d *Document) IsDeployed(ctx context.Context) bool {
+	_, err := d.clients.ssm.GetDocumentWithContext(ctx, &ssm.GetDocumentInput{
 		Name: &d.Name,
 	})
 	return err == nil
@@ -147,10 +206,24 @@ func (d *Document) GetContent() (*string, *string, error) {
 		return &format, &content, nil
 	}
 
+	// Check for package configuration
+	if d.Package != nil {
+		format := "JSON"
+		content, err := d.GetPackageContent()
+		if err != nil {
+			return &format, nil, err
+		}
+		return &format, &content, nil
+	}
+
 	// Check for provided content
 	if len(d.Content.SchemaVersion) > 0 {
 		format := "JSON"
-		marshal, err := jsoniter.Marshal(d.Content)
+		typedContent, err := d.getTypedContent()
+		if err != nil {
+			return &format, nil, err
+		}
+		marshal, err := jsoniter.Marshal(typedContent)
 		if err != nil {
 			return &format, nil, err
 		}
@@ -166,11 +239,10 @@ func (d *Document) GetContent() (*string, *string, error) {
 		// Check extension
 		fileExtension := filepath.Ext(d.File)
 		switch strings.ToUpper(fileExtension) {
-		case ".TXT":
+		case ".TXT", ".ICS":
 			format = "TEXT"
 			break
-		case ".YML":
-		case ".YAML":
+		case ".YML", ".YAML":
 			format = "YAML"
 			break
 		case ".JSON":
@@ -239,8 +311,13 @@ func (d *Document) GetExplodedAccountIDs() []string {
 	return uniqueAccountIDs
 }
 
-// Deploy document
-func (d *Document) Deploy() error {
+// Deploy document, changes are recorded into res
+func (d *Document) Deploy(ctx context.Context, res *Result) error {
+
+	// Check script files
+	if step := d.UnresolvedScriptFile(); len(step) > 0 {
+		return fmt.Errorf("[%s] Step %s reference a scriptFile, deploy with --build or --upload", d.Name, step)
+	}
 
 	// Get content
 	format, content, err := d.GetContent()
@@ -249,7 +326,7 @@ func (d *Document) Deploy() error {
 	}
 
 	// Check if Document is already deployed
-	if d.IsDeployed() == false {
+	if d.IsDeployed(ctx) == false {
 		input := &ssm.CreateDocumentInput{
 			Name:           &d.Name,
 			DocumentFormat: format,
@@ -257,8 +334,23 @@ func (d *Document) Deploy() error {
 			Content:        content,
 		}
 
+		// Attach package files and scripts
+		if d.Package != nil {
+			input.VersionName = aws.String(d.Package.Version)
+			input.Attachments = d.Package.GetAttachments()
+		}
+		input.Attachments = append(input.Attachments, d.attachments...)
+
+		// Parse requirements
+		for _, requirement := range d.Requires {
+			input.Requires = append(input.Requires, &ssm.DocumentRequires{
+				Name:    aws.String(requirement.Name),
+				Version: requirement.GetVersion(),
+			})
+		}
+
 		// Parse tag
-		for key, value := range d.Tags {
+		for key, value := range d.GetTags() {
 			input.Tags = append(input.Tags, &ssm.Tag{
 				Key:   aws.String(key),
 				Value: aws.String(value),
@@ -266,11 +358,20 @@ func (d *Document) Deploy() error {
 		}
 
 		// Create document
-		_, err := d.clients.ssm.CreateDocument(input)
+		createRes, err := d.clients.ssm.CreateDocumentWithContext(ctx, input)
 		if err != nil {
 			return err
 		}
+		res.Action = ActionCreated
+		res.Version = aws.StringValue(createRes.DocumentDescription.DocumentVersion)
+		res.Hash = aws.StringValue(createRes.DocumentDescription.Hash)
 	} else {
+		// Check document ownership
+		err = d.CheckOwnership(ctx)
+		if err != nil {
+			return err
+		}
+
 		input := &ssm.UpdateDocumentInput{
 			Name:            &d.Name,
 			DocumentFormat:  format,
@@ -278,23 +379,43 @@ func (d *Document) Deploy() error {
 			DocumentVersion: aws.String("$LATEST"),
 		}
 
+		// Attach package files and scripts
+		if d.Package != nil {
+			input.VersionName = aws.String(d.Package.Version)
+			input.Attachments = d.Package.GetAttachments()
+		}
+		input.Attachments = append(input.Attachments, d.attachments...)
+
 		// Update document
-		res, err := d.clients.ssm.UpdateDocument(input)
+		updateRes, err := d.clients.ssm.UpdateDocumentWithContext(ctx, input)
 		if err != nil {
 			if awsErr, ok := err.(awserr.Error); ok {
 				if awsErr.Code() != ssm.ErrCodeDuplicateDocumentContent {
 					return err
 				}
 			}
+			res.Action = ActionUnchanged
+		} else if d.RequireApproval {
+			// Submit new version for review, default version is updated on approval
+			err = d.SubmitForReview(ctx, aws.StringValue(updateRes.DocumentDescription.DocumentVersion), d.ReviewComment)
+			if err != nil {
+				return err
+			}
+			res.Action = ActionSubmittedForReview
+			res.Version = aws.StringValue(updateRes.DocumentDescription.DocumentVersion)
+			res.Hash = aws.StringValue(updateRes.DocumentDescription.Hash)
 		} else {
 			// Update latest document version
-			_, err = d.clients.ssm.UpdateDocumentDefaultVersion(&ssm.UpdateDocumentDefaultVersionInput{
+			_, err = d.clients.ssm.UpdateDocumentDefaultVersionWithContext(ctx, &ssm.UpdateDocumentDefaultVersionInput{
 				Name:            &d.Name,
-				DocumentVersion: res.DocumentDescription.DocumentVersion,
+				DocumentVersion: updateRes.DocumentDescription.DocumentVersion,
 			})
 			if err != nil {
 				return err
 			}
+			res.Action = ActionUpdated
+			res.Version = aws.StringValue(updateRes.DocumentDescription.DocumentVersion)
+			res.Hash = aws.StringValue(updateRes.DocumentDescription.Hash)
 		}
 	}
 
@@ -308,7 +429,7 @@ func (d *Document) Deploy() error {
 	}
 
 	// Retrieve current document permissions
-	permRes, err := d.clients.ssm.DescribeDocumentPermission(&ssm.DescribeDocumentPermissionInput{
+	permRes, err := d.clients.ssm.DescribeDocumentPermissionWithContext(ctx, &ssm.DescribeDocumentPermissionInput{
 		Name:           &d.Name,
 		PermissionType: aws.String("Share"),
 	})
@@ -357,12 +478,10 @@ func (d *Document) Deploy() error {
 	// Update permissions if needed
 	if len(accountsToAdd) > 0 || len(accountsToRemove) > 0 {
 
-		chunkSize := 20
-
 		// Elaborate account ids to add
 		if len(accountsToAdd) > 0 {
-			for i := 0; i < len(accountsToAdd); i += chunkSize {
-				end := i + chunkSize
+			for i := 0; i < len(accountsToAdd); i += permissionsChunkSize {
+				end := i + permissionsChunkSize
 				if end > len(accountsToAdd) {
 					end = len(accountsToAdd)
 				}
@@ -375,44 +494,28 @@ func (d *Document) Deploy() error {
 				}
 
 				// Execute update
-				_, err = d.clients.ssm.ModifyDocumentPermission(updateInput)
+				_, err = d.clients.ssm.ModifyDocumentPermissionWithContext(ctx, updateInput)
 				if err != nil {
 					return err
 				}
+				res.AccountsAdded = append(res.AccountsAdded, accountsToAdd[i:end]...)
 			}
 		}
 
 		// Elaborate account ids to remove
-		if len(accountsToRemove) > 0 {
-			for i := 0; i < len(accountsToRemove); i += chunkSize {
-				end := i + chunkSize
-				if end > len(accountsToRemove) {
-					end = len(accountsToRemove)
-				}
-
-				// Build update input
-				updateInput := &ssm.ModifyDocumentPermissionInput{
-					Name:               &d.Name,
-					PermissionType:     aws.String("Share"),
-					AccountIdsToRemove: aws.StringSlice(accountsToRemove[i:end]),
-				}
-
-				// Execute update
-				_, err = d.clients.ssm.ModifyDocumentPermission(updateInput)
-				if err != nil {
-					return err
-				}
-			}
+		err = d.unshare(ctx, accountsToRemove, res)
+		if err != nil {
+			return err
 		}
 	}
 
 	return nil
 }
 
-// UpdateTags update canary tags
-func (d *Document) UpdateTags() error {
+// UpdateTags update document tags, changes are recorded into res
+func (d *Document) UpdateTags(ctx context.Context, res *Result) error {
 	// Get current tags
-	resTags, err := d.clients.ssm.ListTagsForResource(&ssm.ListTagsForResourceInput{
+	resTags, err := d.clients.ssm.ListTagsForResourceWithContext(ctx, &ssm.ListTagsForResourceInput{
 		ResourceId:   &d.Name,
 		ResourceType: aws.String("Document"),
 	})
@@ -422,7 +525,7 @@ func (d *Document) UpdateTags() error {
 
 	// Parse document tags
 	tags := []*ssm.Tag{}
-	for key, value := range d.Tags {
+	for key, value := range d.GetTags() {
 		tags = append(tags, &ssm.Tag{
 			Key:   aws.String(key),
 			Value: aws.String(value),
@@ -452,7 +555,7 @@ func (d *Document) UpdateTags() error {
 
 	// Add missing tags
 	if len(tagsToAdd) > 0 {
-		_, err = d.clients.ssm.AddTagsToResource(&ssm.AddTagsToResourceInput{
+		_, err = d.clients.ssm.AddTagsToResourceWithContext(ctx, &ssm.AddTagsToResourceInput{
 			ResourceId:   &d.Name,
 			ResourceType: aws.String("Document"),
 			Tags:         tagsToAdd,
@@ -460,11 +563,18 @@ func (d *Document) UpdateTags() error {
 		if err != nil {
 			return err
 		}
+		for _, tag := range tagsToAdd {
+			res.TagsAdded = append(res.TagsAdded, *tag.Key)
+		}
 	}
 
-	// Check accounts ids to remove
+	// Check tags to remove, reserved and foreign tags are left untouched
 	tagsKeysToRemove := []*string{}
 	for _, currentTag := range resTags.TagList {
+		if d.isKeptTag(*currentTag.Key) {
+			continue
+		}
+
 		var foundTagKey *string
 		for _, tag := range tags {
 			if *tag.Key == *currentTag.Key {
@@ -480,43 +590,16 @@ func (d *Document) UpdateTags() error {
 
 	// Remove unused tags
 	if len(tagsKeysToRemove) > 0 {
-		_, err = d.clients.ssm.RemoveTagsFromResource(&ssm.RemoveTagsFromResourceInput{
+		_, err = d.clients.ssm.RemoveTagsFromResourceWithContext(ctx, &ssm.RemoveTagsFromResourceInput{
 			ResourceId:   &d.Name,
 			ResourceType: aws.String("Document"),
 			TagKeys:      tagsKeysToRemove,
 		})
+		if err != nil {
+			return err
+		}
+		res.TagsRemoved = aws.StringValueSlice(tagsKeysToRemove)
 	}
 
 	return err
 }
-
-// Remove document
-func (d *Document) Remove() error {
-	// Retrieve current document permissions
-	permRes, err := d.clients.ssm.DescribeDocumentPermission(&ssm.DescribeDocumentPermissionInput{
-		Name:           &d.Name,
-		PermissionType: aws.String("Share"),
-	})
-	if err != nil {
-		return err
-	}
-
-	// Remove all permissions
-	if len(permRes.AccountIds) > 0 {
-		d.clients.ssm.ModifyDocumentPermission(&ssm.ModifyDocumentPermissionInput{
-			Name:               &d.Name,
-			AccountIdsToRemove: permRes.AccountIds,
-		})
-		return nil
-	}
-
-	// Delete document
-	_, err = d.clients.ssm.DeleteDocument(&ssm.DeleteDocumentInput{
-		Name: &d.Name,
-	})
-	if err != nil {
-		return err
-	}
-
-	return nil
-}
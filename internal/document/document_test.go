@@ -0,0 +1,233 @@
+package document
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/daaru00/aws-ssm-document-cli/pkg/ssmfake"
+)
+
+func newTestDocument(fake *ssmfake.Fake, name string, command string) *Document {
+	d := NewWithClient(fake, aws.String("us-east-1"), name)
+	d.Content = Content{
+		SchemaVersion: "2.2",
+		Description:   "Test document",
+		MainSteps: []MainStep{
+			{
+				Action: "aws:runShellScript",
+				Name:   "run",
+				Inputs: ShellInput{
+					RunCommand: []string{command},
+				},
+			},
+		},
+	}
+	return d
+}
+
+func deploy(t *testing.T, d *Document) *Result {
+	t.Helper()
+
+	res := d.NewResult()
+	isAlreadyDeployed := d.IsDeployed(context.Background())
+	err := d.Deploy(context.Background(), res)
+	if err != nil {
+		t.Fatalf("unexpected deploy error: %s", err)
+	}
+	if isAlreadyDeployed {
+		err = d.UpdateTags(context.Background(), res)
+		if err != nil {
+			t.Fatalf("unexpected tags update error: %s", err)
+		}
+	}
+	return res
+}
+
+func accountIDs(count int) []string {
+	ids := []string{}
+	for i := 0; i < count; i++ {
+		ids = append(ids, fmt.Sprintf("1000000000%02d", i))
+	}
+	return ids
+}
+
+func TestDeployCreate(t *testing.T) {
+	fake := ssmfake.New()
+	d := newTestDocument(fake, "Test", "echo 1")
+	d.Tags = map[string]string{"Team": "ops"}
+	d.AccountIDs = []string{"111111111111, 222222222222"}
+
+	res := deploy(t, d)
+
+	if res.Action != ActionCreated || res.Version != "1" || len(res.Hash) == 0 {
+		t.Errorf("unexpected result: %+v", res)
+	}
+	stored := fake.Document("Test")
+	if stored == nil {
+		t.Fatal("document not created")
+	}
+	if stored.Type != "Command" || stored.DefaultVersion != "1" {
+		t.Errorf("unexpected document: %+v", stored)
+	}
+	if !reflect.DeepEqual(stored.Tags, map[string]string{"Team": "ops", ManagedByTagKey: ManagedByTagValue}) {
+		t.Errorf("unexpected tags: %v", stored.Tags)
+	}
+	if !reflect.DeepEqual(stored.AccountIDs, []string{"111111111111", "222222222222"}) {
+		t.Errorf("unexpected accounts: %v", stored.AccountIDs)
+	}
+	if !reflect.DeepEqual(res.AccountsAdded, []string{"111111111111", "222222222222"}) {
+		t.Errorf("unexpected accounts added: %v", res.AccountsAdded)
+	}
+}
+
+func TestDeployUpdate(t *testing.T) {
+	fake := ssmfake.New()
+	deploy(t, newTestDocument(fake, "Test", "echo 1"))
+
+	res := deploy(t, newTestDocument(fake, "Test", "echo 2"))
+
+	if res.Action != ActionUpdated || res.Version != "2" {
+		t.Errorf("unexpected result: %+v", res)
+	}
+	stored := fake.Document("Test")
+	if len(stored.Versions) != 2 || stored.DefaultVersion != "2" {
+		t.Errorf("default version not updated: %+v", stored)
+	}
+}
+
+func TestDeployUnchanged(t *testing.T) {
+	fake := ssmfake.New()
+	deploy(t, newTestDocument(fake, "Test", "echo 1"))
+
+	res := deploy(t, newTestDocument(fake, "Test", "echo 1"))
+
+	if res.Action != ActionUnchanged {
+		t.Errorf("unexpected action: %s", res.Action)
+	}
+	if len(fake.Document("Test").Versions) != 1 {
+		t.Error("unexpected new version")
+	}
+	if fake.Calls("UpdateDocumentDefaultVersion") != 0 {
+		t.Error("default version should not be updated")
+	}
+}
+
+func TestDeployPermissionsInChunks(t *testing.T) {
+	fake := ssmfake.New()
+	d := newTestDocument(fake, "Test", "echo 1")
+	d.AccountIDs = accountIDs(45)
+
+	res := deploy(t, d)
+
+	if len(fake.Document("Test").AccountIDs) != 45 || len(res.AccountsAdded) != 45 {
+		t.Errorf("expected 45 shared accounts, got %d", len(fake.Document("Test").AccountIDs))
+	}
+	if calls := fake.Calls("ModifyDocumentPermission"); calls != 3 {
+		t.Errorf("expected 3 permission calls, got %d", calls)
+	}
+
+	// Remove part of accounts and add a new one
+	d = newTestDocument(fake, "Test", "echo 1")
+	d.AccountIDs = append(accountIDs(10), "999999999999")
+
+	res = deploy(t, d)
+
+	if len(res.AccountsRemoved) != 35 || !reflect.DeepEqual(res.AccountsAdded, []string{"999999999999"}) {
+		t.Errorf("unexpected permission changes: added %v removed %v", res.AccountsAdded, res.AccountsRemoved)
+	}
+	if len(fake.Document("Test").AccountIDs) != 11 {
+		t.Errorf("expected 11 shared accounts, got %v", fake.Document("Test").AccountIDs)
+	}
+}
+
+func TestDeployPermissionsUnchanged(t *testing.T) {
+	fake := ssmfake.New()
+	d := newTestDocument(fake, "Test", "echo 1")
+	d.AccountIDs = []string{"111111111111"}
+	deploy(t, d)
+
+	res := deploy(t, d)
+
+	if len(res.AccountsAdded) != 0 || len(res.AccountsRemoved) != 0 {
+		t.Errorf("unexpected permission changes: %+v", res)
+	}
+	if calls := fake.Calls("ModifyDocumentPermission"); calls != 1 {
+		t.Errorf("expected 1 permission call, got %d", calls)
+	}
+}
+
+func TestUpdateTags(t *testing.T) {
+	fake := ssmfake.New()
+	d := newTestDocument(fake, "Test", "echo 1")
+	d.Tags = map[string]string{"Team": "ops", "Env": "dev", "Old": "value"}
+	deploy(t, d)
+
+	d = newTestDocument(fake, "Test", "echo 1")
+	d.Tags = map[string]string{"Team": "ops", "Env": "prod", "New": "value"}
+	res := deploy(t, d)
+
+	sort.Strings(res.TagsAdded)
+	if !reflect.DeepEqual(res.TagsAdded, []string{"Env", "New"}) {
+		t.Errorf("unexpected tags added: %v", res.TagsAdded)
+	}
+	if !reflect.DeepEqual(res.TagsRemoved, []string{"Old"}) {
+		t.Errorf("unexpected tags removed: %v", res.TagsRemoved)
+	}
+	if !reflect.DeepEqual(fake.Document("Test").Tags, d.GetTags()) {
+		t.Errorf("unexpected tags: %v", fake.Document("Test").Tags)
+	}
+}
+
+func TestUpdateTagsThrottled(t *testing.T) {
+	fake := ssmfake.New()
+	d := newTestDocument(fake, "Test", "echo 1")
+	deploy(t, d)
+	fake.Throttle(1)
+
+	err := d.UpdateTags(context.Background(), d.NewResult())
+
+	awsErr, ok := err.(awserr.Error)
+	if !ok || awsErr.Code() != ssmfake.ErrCodeThrottling {
+		t.Errorf("expected throttling error, got %v", err)
+	}
+}
+
+func TestRemove(t *testing.T) {
+	fake := ssmfake.New()
+	d := newTestDocument(fake, "Test", "echo 1")
+	deploy(t, d)
+
+	res := d.NewResult()
+	err := d.Remove(context.Background(), res)
+	if err != nil {
+		t.Fatalf("unexpected remove error: %s", err)
+	}
+
+	if res.Action != ActionRemoved {
+		t.Errorf("unexpected action: %s", res.Action)
+	}
+	if fake.Document("Test") != nil {
+		t.Error("document not removed")
+	}
+	if d.IsDeployed(context.Background()) {
+		t.Error("document still deployed")
+	}
+}
+
+func TestGetExplodedAccountIDs(t *testing.T) {
+	d := &Document{
+		AccountIDs: []string{"111111111111,222222222222, 333333333333", " 111111111111 ", "", "444444444444"},
+	}
+
+	ids := d.GetExplodedAccountIDs()
+
+	expected := []string{"111111111111", "222222222222", "333333333333", "444444444444"}
+	if !reflect.DeepEqual(ids, expected) {
+		t.Errorf("expected %v, got %v", expected, ids)
+	}
+}
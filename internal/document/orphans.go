@@ -0,0 +1,88 @@
+package document
+
+import (
+	"context"
+	"sort"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/aws/aws-sdk-go/service/ssm/ssmiface"
+)
+
+// ListProjectDocuments return the names of the documents owned by the caller
+// that carry the ownership marker of project
+func ListProjectDocuments(ctx context.Context, client ssmiface.SSMAPI, project string) ([]string, error) {
+	filters := []*ssm.DocumentKeyValuesFilter{
+		{
+			Key:    aws.String("Owner"),
+			Values: aws.StringSlice([]string{"Self"}),
+		},
+		{
+			Key:    aws.String("tag:" + ManagedByTagKey),
+			Values: aws.StringSlice([]string{ManagedByTagValue}),
+		},
+	}
+	if len(project) > 0 {
+		filters = append(filters, &ssm.DocumentKeyValuesFilter{
+			Key:    aws.String("tag:" + ProjectTagKey),
+			Values: aws.StringSlice([]string{project}),
+		})
+	}
+
+	names := []string{}
+	input := &ssm.ListDocumentsInput{
+		Filters: filters,
+	}
+	for {
+		res, err := client.ListDocumentsWithContext(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+		for _, identifier := range res.DocumentIdentifiers {
+			// Documents without project tag belong to no project
+			if len(project) == 0 && hasTag(identifier.Tags, ProjectTagKey) {
+				continue
+			}
+			names = append(names, aws.StringValue(identifier.Name))
+		}
+
+		if res.NextToken == nil {
+			break
+		}
+		input.NextToken = res.NextToken
+	}
+
+	sort.Strings(names)
+	return names, nil
+}
+
+// FindOrphans return the names of the project documents deployed but not found in documents
+func FindOrphans(ctx context.Context, client ssmiface.SSMAPI, project string, documents []*Document) ([]string, error) {
+	deployed, err := ListProjectDocuments(ctx, client, project)
+	if err != nil {
+		return nil, err
+	}
+
+	local := map[string]bool{}
+	for _, d := range documents {
+		local[d.Name] = true
+	}
+
+	orphans := []string{}
+	for _, name := range deployed {
+		if !local[name] {
+			orphans = append(orphans, name)
+		}
+	}
+	return orphans, nil
+}
+
+// hasTag check if tags contain key
+func hasTag(tags []*ssm.Tag, key string) bool {
+	for _, tag := range tags {
+		if aws.StringValue(tag.Key) == key {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,35 @@
+package document
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/daaru00/aws-ssm-document-cli/pkg/ssmfake"
+)
+
+func TestFindOrphans(t *testing.T) {
+	fake := ssmfake.New()
+	for _, name := range []string{"Kept", "Deleted", "Other"} {
+		d := newTestDocument(fake, name, "echo 1")
+		d.Project = "infra"
+		if name == "Other" {
+			d.Project = "apps"
+		}
+		deploy(t, d)
+	}
+	fake.PutDocument(&ssmfake.Document{
+		Name:     "Unmanaged",
+		Owner:    fake.AccountID,
+		Versions: []*ssmfake.Version{{Version: "1"}},
+	})
+
+	local := newTestDocument(fake, "Kept", "echo 1")
+	orphans, err := FindOrphans(context.Background(), fake, "infra", []*Document{local})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !reflect.DeepEqual(orphans, []string{"Deleted"}) {
+		t.Errorf("unexpected orphans: %v", orphans)
+	}
+}
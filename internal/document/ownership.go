@@ -0,0 +1,86 @@
+package document
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ssm"
+)
+
+const (
+	// ManagedByTagKey is the tag that mark documents deployed by this tool
+	ManagedByTagKey = "managed-by"
+	// ManagedByTagValue is the value of the ownership marker tag
+	ManagedByTagValue = "aws-ssm-document"
+	// ProjectTagKey is the tag that identify the project that deployed the document
+	ProjectTagKey = "aws-ssm-document:project"
+
+	// ManagedNamePrefix is prepended to the names of associations and maintenance window tasks
+	// created by this tool, resources without it are never updated or deleted
+	ManagedNamePrefix = "aws-ssm-document-"
+
+	// reservedTagPrefix is the prefix of tags managed by AWS
+	reservedTagPrefix = "aws:"
+)
+
+// GetTags return the configured tags with the ownership marker tags
+func (d *Document) GetTags() map[string]string {
+	tags := map[string]string{}
+	for key, value := range d.Tags {
+		tags[key] = value
+	}
+	tags[ManagedByTagKey] = ManagedByTagValue
+	if len(d.Project) > 0 {
+		tags[ProjectTagKey] = d.Project
+	}
+	return tags
+}
+
+// IsManaged check if tags contain the ownership marker of the document project
+func (d *Document) IsManaged(tags map[string]string) bool {
+	return tags[ManagedByTagKey] == ManagedByTagValue && tags[ProjectTagKey] == d.Project
+}
+
+// CheckOwnership return an error if the deployed document has not the ownership marker
+// of the document project, unless the document is adopted
+func (d *Document) CheckOwnership(ctx context.Context) error {
+	if d.Adopt {
+		return nil
+	}
+
+	res, err := d.clients.ssm.ListTagsForResourceWithContext(ctx, &ssm.ListTagsForResourceInput{
+		ResourceId:   &d.Name,
+		ResourceType: aws.String("Document"),
+	})
+	if err != nil {
+		return err
+	}
+
+	tags := map[string]string{}
+	for _, tag := range res.TagList {
+		tags[aws.StringValue(tag.Key)] = aws.StringValue(tag.Value)
+	}
+	if d.IsManaged(tags) {
+		return nil
+	}
+
+	if tags[ManagedByTagKey] == ManagedByTagValue {
+		return fmt.Errorf("[%s] Document is managed by project %s, use --adopt to take ownership", d.Name, tags[ProjectTagKey])
+	}
+	return fmt.Errorf("[%s] Document is not managed by this tool, use --adopt to take ownership", d.Name)
+}
+
+// isKeptTag check if a deployed tag must be left untouched, reserved and foreign prefixes are not reconciled
+func (d *Document) isKeptTag(key string) bool {
+	if strings.HasPrefix(key, reservedTagPrefix) {
+		return true
+	}
+	for _, prefix := range d.KeepTagPrefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,87 @@
+package document
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/daaru00/aws-ssm-document-cli/pkg/ssmfake"
+)
+
+func TestDeployOwnershipTags(t *testing.T) {
+	fake := ssmfake.New()
+	d := newTestDocument(fake, "Test", "echo 1")
+	d.Project = "infra"
+	deploy(t, d)
+
+	expected := map[string]string{ManagedByTagKey: ManagedByTagValue, ProjectTagKey: "infra"}
+	if !reflect.DeepEqual(fake.Document("Test").Tags, expected) {
+		t.Errorf("unexpected tags: %v", fake.Document("Test").Tags)
+	}
+}
+
+func TestDeployUnmanaged(t *testing.T) {
+	fake := ssmfake.New()
+	fake.PutDocument(&ssmfake.Document{
+		Name:           "Test",
+		Type:           "Command",
+		DefaultVersion: "1",
+		Versions:       []*ssmfake.Version{{Version: "1", Content: "{}", Format: "JSON"}},
+		Tags:           map[string]string{"Team": "other"},
+	})
+
+	d := newTestDocument(fake, "Test", "echo 1")
+	err := d.Deploy(context.Background(), d.NewResult())
+	if err == nil {
+		t.Fatal("expected ownership error")
+	}
+	if len(fake.Document("Test").Versions) != 1 {
+		t.Error("unmanaged document should not be updated")
+	}
+
+	err = d.Remove(context.Background(), d.NewResult())
+	if err == nil || fake.Document("Test") == nil {
+		t.Error("unmanaged document should not be removed")
+	}
+
+	// Adopt the document
+	d.Adopt = true
+	deploy(t, d)
+	if fake.Document("Test").Tags[ManagedByTagKey] != ManagedByTagValue {
+		t.Errorf("ownership marker not added: %v", fake.Document("Test").Tags)
+	}
+}
+
+func TestDeployOtherProject(t *testing.T) {
+	fake := ssmfake.New()
+	d := newTestDocument(fake, "Test", "echo 1")
+	d.Project = "infra"
+	deploy(t, d)
+
+	d = newTestDocument(fake, "Test", "echo 2")
+	d.Project = "apps"
+	err := d.Deploy(context.Background(), d.NewResult())
+	if err == nil {
+		t.Error("expected ownership error")
+	}
+}
+
+func TestUpdateTagsKeepPrefixes(t *testing.T) {
+	fake := ssmfake.New()
+	d := newTestDocument(fake, "Test", "echo 1")
+	deploy(t, d)
+	fake.Document("Test").Tags["aws:cloudformation:stack-name"] = "stack"
+	fake.Document("Test").Tags["billing:owner"] = "finance"
+	fake.Document("Test").Tags["Old"] = "value"
+
+	d.KeepTagPrefixes = []string{"billing:"}
+	res := deploy(t, d)
+
+	if !reflect.DeepEqual(res.TagsRemoved, []string{"Old"}) {
+		t.Errorf("unexpected tags removed: %v", res.TagsRemoved)
+	}
+	tags := fake.Document("Test").Tags
+	if tags["aws:cloudformation:stack-name"] != "stack" || tags["billing:owner"] != "finance" {
+		t.Errorf("reserved and foreign tags should be kept: %v", tags)
+	}
+}
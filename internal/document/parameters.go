@@ -0,0 +1,316 @@
+package document
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	// ParameterTypeString is a single string value
+	ParameterTypeString = "String"
+	// ParameterTypeStringList is a list of strings
+	ParameterTypeStringList = "StringList"
+	// ParameterTypeInteger is an integer number
+	ParameterTypeInteger = "Integer"
+	// ParameterTypeBoolean is true or false
+	ParameterTypeBoolean = "Boolean"
+	// ParameterTypeMapList is a list of maps
+	ParameterTypeMapList = "MapList"
+	// ParameterTypeStringMap is a map of string keys
+	ParameterTypeStringMap = "StringMap"
+)
+
+// automationParameterTypes are the AWS resource types supported only by Automation documents,
+// each one can also be used as list, like List<AWS::EC2::Instance::Id>
+var automationParameterTypes = []string{
+	"AWS::EC2::Instance::Id",
+	"AWS::IAM::Role::Arn",
+	"AWS::S3::Bucket::Name",
+}
+
+// Parameter configuration
+type Parameter struct {
+	Type           string      `yaml:"type" json:"type"`
+	Description    string      `yaml:"description,omitempty" json:"description,omitempty"`
+	Default        interface{} `yaml:"default,omitempty" json:"default,omitempty"`
+	AllowedValues  []string    `yaml:"allowedValues,omitempty" json:"allowedValues,omitempty"`
+	AllowedPattern string      `yaml:"allowedPattern,omitempty" json:"allowedPattern,omitempty"`
+	DisplayType    string      `yaml:"displayType,omitempty" json:"displayType,omitempty"`
+	MinItems       int         `yaml:"minItems,omitempty" json:"minItems,omitempty"`
+	MaxItems       int         `yaml:"maxItems,omitempty" json:"maxItems,omitempty"`
+	MinChars       int         `yaml:"minChars,omitempty" json:"minChars,omitempty"`
+	MaxChars       int         `yaml:"maxChars,omitempty" json:"maxChars,omitempty"`
+}
+
+// UnmarshalYAML parse parameter converting YAML maps in default value to string keyed maps,
+// so content can be marshalled to JSON
+func (p *Parameter) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	type rawParameter Parameter
+	raw := rawParameter{}
+	err := unmarshal(&raw)
+	if err != nil {
+		return err
+	}
+
+	*p = Parameter(raw)
+	p.Default = NormalizeValue(p.Default)
+
+	// Keep unquoted scalars, like 8080, valid for String parameters
+	switch value := p.Default.(type) {
+	case int, float64, bool:
+		if p.Type == ParameterTypeString {
+			p.Default = fmt.Sprintf("%v", value)
+		}
+	}
+	return nil
+}
+
+// NormalizeValue convert YAML maps, with interface keys, into string keyed maps
+func NormalizeValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		normalized := map[string]interface{}{}
+		for key, item := range v {
+			normalized[fmt.Sprintf("%v", key)] = NormalizeValue(item)
+		}
+		return normalized
+	case map[string]interface{}:
+		normalized := map[string]interface{}{}
+		for key, item := range v {
+			normalized[key] = NormalizeValue(item)
+		}
+		return normalized
+	case []interface{}:
+		normalized := make([]interface{}, len(v))
+		for index, item := range v {
+			normalized[index] = NormalizeValue(item)
+		}
+		return normalized
+	default:
+		return value
+	}
+}
+
+// IsSSMReference check if value is a Parameter Store reference, like {{ssm:/path/to/parameter}},
+// resolved by SSM at execution time
+func IsSSMReference(value interface{}) bool {
+	str, ok := value.(string)
+	if !ok {
+		return false
+	}
+	str = strings.TrimSpace(str)
+	return strings.HasPrefix(str, "{{ssm:") && strings.HasSuffix(str, "}}")
+}
+
+// Validate check parameter definition and default value against its constraints, the document
+// type enable the Automation only types. Return warnings about constraints that cannot be checked locally.
+func (p *Parameter) Validate(documentType string) ([]string, error) {
+	warnings := []string{}
+
+	// Check type
+	kind, err := p.kind(documentType)
+	if err != nil {
+		return warnings, err
+	}
+
+	// Check display type
+	if len(p.DisplayType) > 0 && p.DisplayType != "textfield" && p.DisplayType != "textarea" {
+		return warnings, fmt.Errorf("displayType %q is not valid, valid values are textfield, textarea", p.DisplayType)
+	}
+
+	// Check constraints compatibility
+	isList := kind == ParameterTypeStringList || kind == ParameterTypeMapList
+	isText := kind == ParameterTypeString || kind == ParameterTypeStringList
+	if (p.MinItems > 0 || p.MaxItems > 0) && !isList {
+		return warnings, fmt.Errorf("minItems and maxItems are not supported by type %s", p.Type)
+	}
+	if (p.MinChars > 0 || p.MaxChars > 0) && !isText {
+		return warnings, fmt.Errorf("minChars and maxChars are not supported by type %s", p.Type)
+	}
+	if len(p.DisplayType) > 0 && !isText {
+		return warnings, fmt.Errorf("displayType is not supported by type %s", p.Type)
+	}
+	if p.MaxItems > 0 && p.MinItems > p.MaxItems {
+		return warnings, fmt.Errorf("minItems %d is greater than maxItems %d", p.MinItems, p.MaxItems)
+	}
+	if p.MaxChars > 0 && p.MinChars > p.MaxChars {
+		return warnings, fmt.Errorf("minChars %d is greater than maxChars %d", p.MinChars, p.MaxChars)
+	}
+	var pattern *regexp.Regexp
+	if len(p.AllowedPattern) > 0 {
+		pattern, err = regexp.Compile(p.AllowedPattern)
+		if err != nil {
+			// SSM use Java regular expressions, lookarounds and backreferences are not supported by Go
+			warnings = append(warnings, fmt.Sprintf("allowedPattern %q cannot be checked locally: %s", p.AllowedPattern, err))
+		}
+	}
+
+	// Default value is optional, references are resolved at execution time
+	if p.Default == nil || IsSSMReference(p.Default) {
+		return warnings, nil
+	}
+
+	// Check default value type
+	switch kind {
+	case ParameterTypeString:
+		value, ok := p.Default.(string)
+		if !ok {
+			return warnings, fmt.Errorf("default %v is not a string", p.Default)
+		}
+		return warnings, p.validateText(value, pattern)
+	case ParameterTypeStringList:
+		items, ok := p.Default.([]interface{})
+		if !ok {
+			return warnings, fmt.Errorf("default %v is not a list", p.Default)
+		}
+		err := p.validateItems(len(items))
+		if err != nil {
+			return warnings, err
+		}
+		for _, item := range items {
+			value, ok := item.(string)
+			if !ok {
+				return warnings, fmt.Errorf("default item %v is not a string", item)
+			}
+			err = p.validateText(value, pattern)
+			if err != nil {
+				return warnings, err
+			}
+		}
+	case ParameterTypeInteger:
+		switch value := p.Default.(type) {
+		case int, int64:
+			return warnings, p.validateAllowed(fmt.Sprintf("%d", value))
+		case float64:
+			if value != float64(int64(value)) {
+				return warnings, fmt.Errorf("default %v is not an integer", p.Default)
+			}
+			return warnings, p.validateAllowed(fmt.Sprintf("%d", int64(value)))
+		default:
+			return warnings, fmt.Errorf("default %v is not an integer", p.Default)
+		}
+	case ParameterTypeBoolean:
+		value, ok := p.Default.(bool)
+		if !ok {
+			return warnings, fmt.Errorf("default %v is not a boolean", p.Default)
+		}
+		return warnings, p.validateAllowed(fmt.Sprintf("%t", value))
+	case ParameterTypeMapList:
+		items, ok := p.Default.([]interface{})
+		if !ok {
+			return warnings, fmt.Errorf("default %v is not a list", p.Default)
+		}
+		err := p.validateItems(len(items))
+		if err != nil {
+			return warnings, err
+		}
+		for _, item := range items {
+			if _, ok := item.(map[string]interface{}); !ok {
+				return warnings, fmt.Errorf("default item %v is not a map", item)
+			}
+		}
+	case ParameterTypeStringMap:
+		if _, ok := p.Default.(map[string]interface{}); !ok {
+			return warnings, fmt.Errorf("default %v is not a map", p.Default)
+		}
+	}
+
+	return warnings, nil
+}
+
+// kind return the built in type used to check constraints and default value,
+// Automation resource types are checked as strings
+func (p *Parameter) kind(documentType string) (string, error) {
+	valid := []string{
+		ParameterTypeString, ParameterTypeStringList, ParameterTypeInteger, ParameterTypeBoolean, ParameterTypeMapList, ParameterTypeStringMap,
+	}
+	if contains(valid, p.Type) {
+		return p.Type, nil
+	}
+
+	if documentType == TypeAutomation || documentType == TypeChangeTemplate {
+		for _, automationType := range automationParameterTypes {
+			if p.Type == automationType {
+				return ParameterTypeString, nil
+			}
+			if p.Type == fmt.Sprintf("List<%s>", automationType) {
+				return ParameterTypeStringList, nil
+			}
+		}
+	} else if strings.HasPrefix(p.Type, "AWS::") || strings.HasPrefix(p.Type, "List<AWS::") {
+		return "", fmt.Errorf("type %q is supported only by %s documents", p.Type, TypeAutomation)
+	}
+
+	for _, automationType := range automationParameterTypes {
+		valid = append(valid, automationType, fmt.Sprintf("List<%s>", automationType))
+	}
+	return "", fmt.Errorf("type %q is not valid, valid values are %s", p.Type, strings.Join(valid, ", "))
+}
+
+// validateText check a string value against allowed values, pattern and length
+func (p *Parameter) validateText(value string, pattern *regexp.Regexp) error {
+	err := p.validateAllowed(value)
+	if err != nil {
+		return err
+	}
+	if pattern != nil && !pattern.MatchString(value) {
+		return fmt.Errorf("default %q does not match allowedPattern %q", value, p.AllowedPattern)
+	}
+	if p.MinChars > 0 && len(value) < p.MinChars {
+		return fmt.Errorf("default %q is shorter than minChars %d", value, p.MinChars)
+	}
+	if p.MaxChars > 0 && len(value) > p.MaxChars {
+		return fmt.Errorf("default %q is longer than maxChars %d", value, p.MaxChars)
+	}
+	return nil
+}
+
+// validateAllowed check value is one of the allowed values, if any
+func (p *Parameter) validateAllowed(value string) error {
+	if len(p.AllowedValues) == 0 {
+		return nil
+	}
+	for _, allowed := range p.AllowedValues {
+		if allowed == value {
+			return nil
+		}
+	}
+	return fmt.Errorf("default %q is not one of allowedValues %s", value, strings.Join(p.AllowedValues, ", "))
+}
+
+// validateItems check list length
+func (p *Parameter) validateItems(count int) error {
+	if p.MinItems > 0 && count < p.MinItems {
+		return fmt.Errorf("default has %d items, less than minItems %d", count, p.MinItems)
+	}
+	if p.MaxItems > 0 && count > p.MaxItems {
+		return fmt.Errorf("default has %d items, more than maxItems %d", count, p.MaxItems)
+	}
+	return nil
+}
+
+// ValidateParameters check document parameters definitions, return warnings about constraints that cannot be checked locally
+func (d *Document) ValidateParameters() ([]string, error) {
+	warnings := []string{}
+	for _, parameters := range []map[string]Parameter{d.Parameters, d.Content.Parameters} {
+		names := []string{}
+		for name := range parameters {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			parameter := parameters[name]
+			parameterWarnings, err := parameter.Validate(d.Type)
+			if err != nil {
+				return warnings, fmt.Errorf("[%s] Parameter %s %s", d.Name, name, err)
+			}
+			for _, warning := range parameterWarnings {
+				warnings = append(warnings, fmt.Sprintf("Parameter %s %s", name, warning))
+			}
+		}
+	}
+	return warnings, nil
+}
@@ -0,0 +1,98 @@
+package document
+
+import (
+	"strings"
+	"testing"
+
+	jsoniter "github.com/json-iterator/go"
+	"gopkg.in/yaml.v2"
+)
+
+func TestParameterValidate(t *testing.T) {
+	cases := []struct {
+		name         string
+		documentType string
+		definition   string
+		err          string
+		warning      string
+	}{
+		{"string", "", `{type: String, default: dev, allowedValues: [dev, prod]}`, "", ""},
+		{"string not allowed", "", `{type: String, default: test, allowedValues: [dev, prod]}`, "not one of allowedValues", ""},
+		{"string pattern", "", `{type: String, default: "abc", allowedPattern: "^[0-9]+$"}`, "does not match allowedPattern", ""},
+		{"string chars", "", `{type: String, default: "abc", maxChars: 2}`, "longer than maxChars", ""},
+		{"unquoted scalar string", "", `{type: String, default: 8080}`, "", ""},
+		{"string list", "", `{type: StringList, default: [a, b], minItems: 1, maxItems: 2}`, "", ""},
+		{"string list items", "", `{type: StringList, default: [a, b, c], maxItems: 2}`, "more than maxItems", ""},
+		{"integer", "", `{type: Integer, default: 3, allowedValues: ["1", "3"]}`, "", ""},
+		{"integer invalid", "", `{type: Integer, default: "three"}`, "not an integer", ""},
+		{"boolean", "", `{type: Boolean, default: true}`, "", ""},
+		{"boolean invalid", "", `{type: Boolean, default: [true]}`, "not a boolean", ""},
+		{"map list", "", `{type: MapList, default: [{Key: a}, {Key: b}]}`, "", ""},
+		{"string map", "", `{type: StringMap, default: {Key: a}}`, "", ""},
+		{"string map invalid", "", `{type: StringMap, default: [a]}`, "not a map", ""},
+		{"ssm reference", "", `{type: Integer, default: "{{ssm:/app/port}}"}`, "", ""},
+		{"invalid type", "", `{type: Number}`, "is not valid", ""},
+		{"invalid display type", "", `{type: String, displayType: checkbox}`, "displayType", ""},
+		{"items on string", "", `{type: String, minItems: 1}`, "not supported by type String", ""},
+		{"unsupported pattern", "", `{type: String, default: abc, allowedPattern: "^(?!admin).*$"}`, "", "cannot be checked locally"},
+		{"automation instance", TypeAutomation, `{type: "AWS::EC2::Instance::Id", default: i-0123456789abcdef0}`, "", ""},
+		{"automation instance list", TypeAutomation, `{type: "List<AWS::EC2::Instance::Id>", default: [i-0123456789abcdef0], maxItems: 1}`, "", ""},
+		{"automation role on command", TypeCommand, `{type: "AWS::IAM::Role::Arn"}`, "supported only by Automation", ""},
+		{"automation unknown type", TypeAutomation, `{type: "AWS::EC2::Image::Id"}`, "is not valid", ""},
+	}
+
+	for _, c := range cases {
+		parameter := Parameter{}
+		err := yaml.Unmarshal([]byte(c.definition), &parameter)
+		if err != nil {
+			t.Fatalf("%s: cannot parse definition: %s", c.name, err)
+		}
+
+		warnings, err := parameter.Validate(c.documentType)
+		if len(c.err) == 0 && err != nil {
+			t.Errorf("%s: unexpected error: %s", c.name, err)
+		}
+		if len(c.err) > 0 && (err == nil || !strings.Contains(err.Error(), c.err)) {
+			t.Errorf("%s: expected error %q, got %v", c.name, c.err, err)
+		}
+		if len(c.warning) == 0 && len(warnings) > 0 {
+			t.Errorf("%s: unexpected warnings: %v", c.name, warnings)
+		}
+		if len(c.warning) > 0 && (len(warnings) != 1 || !strings.Contains(warnings[0], c.warning)) {
+			t.Errorf("%s: expected warning %q, got %v", c.name, c.warning, warnings)
+		}
+	}
+}
+
+func TestParameterTypedDefaultContent(t *testing.T) {
+	d := &Document{Name: "Test"}
+	err := yaml.Unmarshal([]byte(`
+format: SHELL
+parameters:
+  Tags:
+    type: StringMap
+    default:
+      Environment: dev
+  Retries:
+    type: Integer
+    default: 3
+`), d)
+	if err != nil {
+		t.Fatalf("cannot parse document: %s", err)
+	}
+
+	_, err = d.ValidateParameters()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// Typed defaults must survive JSON content generation
+	content := Content{SchemaVersion: "2.2", Parameters: d.Parameters}
+	marshal, err := jsoniter.Marshal(content)
+	if err != nil {
+		t.Fatalf("cannot marshal content: %s", err)
+	}
+	if !strings.Contains(string(marshal), `"default":{"Environment":"dev"}`) || !strings.Contains(string(marshal), `"default":3`) {
+		t.Errorf("unexpected content: %s", marshal)
+	}
+}
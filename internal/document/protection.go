@@ -0,0 +1,25 @@
+package document
+
+import "fmt"
+
+// ProtectionRules mark as protected all documents deployed to a stage or an account
+type ProtectionRules struct {
+	Stages   []string
+	Accounts []string
+}
+
+// Match check if the rules protect documents deployed to the stage and account
+func (r ProtectionRules) Match(stage string, accountID string) bool {
+	if len(stage) > 0 && contains(r.Stages, stage) {
+		return true
+	}
+	return contains(r.Accounts, accountID)
+}
+
+// CheckProtection refuse the operation on protected documents, unless ForceProtected is set
+func (d *Document) CheckProtection(operation string) error {
+	if !d.Protected || d.ForceProtected {
+		return nil
+	}
+	return fmt.Errorf("[%s] Document is protected, %s refused, use --force-protected to proceed", d.Name, operation)
+}
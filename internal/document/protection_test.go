@@ -0,0 +1,95 @@
+package document
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/daaru00/aws-ssm-document-cli/pkg/ssmfake"
+)
+
+func TestProtectionRulesMatch(t *testing.T) {
+	rules := ProtectionRules{
+		Stages:   []string{"prod"},
+		Accounts: []string{"111111111111"},
+	}
+
+	cases := []struct {
+		stage     string
+		accountID string
+		expected  bool
+	}{
+		{"prod", "222222222222", true},
+		{"", "111111111111", true},
+		{"dev", "222222222222", false},
+		{"", "222222222222", false},
+	}
+	for _, tc := range cases {
+		if rules.Match(tc.stage, tc.accountID) != tc.expected {
+			t.Errorf("unexpected match for stage %q and account %s", tc.stage, tc.accountID)
+		}
+	}
+}
+
+func TestRemoveProtected(t *testing.T) {
+	fake := ssmfake.New()
+	d := newTestDocument(fake, "Test", "echo 1")
+	deploy(t, d)
+	d.Protected = true
+
+	err := d.Remove(context.Background(), d.NewResult())
+	if err == nil || !strings.Contains(err.Error(), "protected") {
+		t.Errorf("expected protection error, got %v", err)
+	}
+	if fake.Document("Test") == nil {
+		t.Fatal("protected document removed")
+	}
+
+	d.ForceProtected = true
+	err = d.Remove(context.Background(), d.NewResult())
+	if err != nil {
+		t.Fatalf("unexpected remove error: %s", err)
+	}
+	if fake.Document("Test") != nil {
+		t.Error("document not removed")
+	}
+}
+
+func TestDeployProtectedUnshare(t *testing.T) {
+	fake := ssmfake.New()
+	d := newTestDocument(fake, "Test", "echo 1")
+	d.AccountIDs = []string{"111111111111", "222222222222"}
+	deploy(t, d)
+
+	// Adding accounts is allowed
+	d.Protected = true
+	d.AccountIDs = []string{"111111111111", "222222222222", "333333333333"}
+	res := d.NewResult()
+	err := d.Deploy(context.Background(), res)
+	if err != nil {
+		t.Fatalf("unexpected deploy error: %s", err)
+	}
+	if len(res.AccountsAdded) != 1 {
+		t.Errorf("unexpected accounts added: %v", res.AccountsAdded)
+	}
+
+	// Removing accounts is refused
+	d.AccountIDs = []string{"111111111111"}
+	err = d.Deploy(context.Background(), d.NewResult())
+	if err == nil || !strings.Contains(err.Error(), "protected") {
+		t.Errorf("expected protection error, got %v", err)
+	}
+	if len(fake.Document("Test").AccountIDs) != 3 {
+		t.Errorf("unexpected shared accounts: %v", fake.Document("Test").AccountIDs)
+	}
+
+	d.ForceProtected = true
+	res = d.NewResult()
+	err = d.Deploy(context.Background(), res)
+	if err != nil {
+		t.Fatalf("unexpected deploy error: %s", err)
+	}
+	if len(res.AccountsRemoved) != 2 {
+		t.Errorf("unexpected accounts removed: %v", res.AccountsRemoved)
+	}
+}
@@ -0,0 +1,150 @@
+package document
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ssm"
+)
+
+// permissionsChunkSize is the max number of accounts changed by a single permission request
+const permissionsChunkSize = 20
+
+// forceDeleteTypes are the document types that require Force to be deleted
+var forceDeleteTypes = []string{ssm.DocumentTypeApplicationConfigurationSchema}
+
+// Remove document, changes are recorded into res.
+// Removal happens in phases: share permissions are removed, then associations and maintenance
+// window tasks that run the document and at the end the document is deleted with all its versions.
+// When KeepShared is set shared documents are only unshared and kept.
+func (d *Document) Remove(ctx context.Context, res *Result) error {
+	// Check document protection
+	err := d.CheckProtection("remove")
+	if err != nil {
+		return err
+	}
+
+	// Check document ownership
+	err = d.CheckOwnership(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Retrieve current document permissions
+	permRes, err := d.clients.ssm.DescribeDocumentPermissionWithContext(ctx, &ssm.DescribeDocumentPermissionInput{
+		Name:           &d.Name,
+		PermissionType: aws.String("Share"),
+	})
+	if err != nil {
+		return err
+	}
+
+	// Remove all permissions
+	accountIDs := aws.StringValueSlice(permRes.AccountIds)
+	err = d.unshare(ctx, accountIDs, res)
+	if err != nil {
+		return err
+	}
+	if d.KeepShared && len(accountIDs) > 0 {
+		res.Action = ActionUnshared
+		return nil
+	}
+
+	// Delete associations that run the document, associated documents cannot be deleted
+	err = d.RemoveAssociations(ctx, res)
+	if err != nil {
+		return err
+	}
+
+	// Deregister maintenance window tasks that run the document
+	err = d.RemoveMaintenanceWindows(ctx, res)
+	if err != nil {
+		return err
+	}
+
+	// Delete document with all its versions
+	err = d.delete(ctx, res)
+	if err != nil {
+		return err
+	}
+	res.Action = ActionRemoved
+	return nil
+}
+
+// unshare remove share permissions of accounts in batches, removed accounts are recorded into res
+func (d *Document) unshare(ctx context.Context, accountIDs []string, res *Result) error {
+	if len(accountIDs) == 0 {
+		return nil
+	}
+
+	// Check document protection
+	err := d.CheckProtection(fmt.Sprintf("removing share permissions of %d accounts", len(accountIDs)))
+	if err != nil {
+		return err
+	}
+
+	for i := 0; i < len(accountIDs); i += permissionsChunkSize {
+		end := i + permissionsChunkSize
+		if end > len(accountIDs) {
+			end = len(accountIDs)
+		}
+
+		_, err := d.clients.ssm.ModifyDocumentPermissionWithContext(ctx, &ssm.ModifyDocumentPermissionInput{
+			Name:               &d.Name,
+			PermissionType:     aws.String("Share"),
+			AccountIdsToRemove: aws.StringSlice(accountIDs[i:end]),
+		})
+		if err != nil {
+			return err
+		}
+		res.AccountsRemoved = append(res.AccountsRemoved, accountIDs[i:end]...)
+	}
+	return nil
+}
+
+// delete the document and all its versions, deleted versions are recorded into res
+func (d *Document) delete(ctx context.Context, res *Result) error {
+	// Retrieve document type
+	describeRes, err := d.clients.ssm.DescribeDocumentWithContext(ctx, &ssm.DescribeDocumentInput{
+		Name: &d.Name,
+	})
+	if err != nil {
+		return err
+	}
+
+	// Retrieve versions to delete
+	versions := []string{}
+	input := &ssm.ListDocumentVersionsInput{
+		Name: &d.Name,
+	}
+	for {
+		versionsRes, err := d.clients.ssm.ListDocumentVersionsWithContext(ctx, input)
+		if err != nil {
+			return err
+		}
+		for _, version := range versionsRes.DocumentVersions {
+			versions = append(versions, aws.StringValue(version.DocumentVersion))
+		}
+
+		if versionsRes.NextToken == nil {
+			break
+		}
+		input.NextToken = versionsRes.NextToken
+	}
+
+	// Delete document
+	deleteInput := &ssm.DeleteDocumentInput{
+		Name: &d.Name,
+	}
+	if contains(forceDeleteTypes, aws.StringValue(describeRes.Document.DocumentType)) {
+		deleteInput.Force = aws.Bool(true)
+	}
+	_, err = d.clients.ssm.DeleteDocumentWithContext(ctx, deleteInput)
+	if err != nil {
+		return err
+	}
+	res.VersionsRemoved = versions
+
+	return nil
+}
@@ -0,0 +1,120 @@
+package document
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/daaru00/aws-ssm-document-cli/pkg/ssmfake"
+)
+
+// failingUnshareClient fail permission removals after the first one
+type failingUnshareClient struct {
+	*ssmfake.Fake
+	calls int
+}
+
+func (c *failingUnshareClient) ModifyDocumentPermissionWithContext(ctx aws.Context, input *ssm.ModifyDocumentPermissionInput, opts ...request.Option) (*ssm.ModifyDocumentPermissionOutput, error) {
+	if len(input.AccountIdsToRemove) > 0 {
+		c.calls++
+		if c.calls > 1 {
+			return nil, errors.New("unshare failed")
+		}
+	}
+	return c.Fake.ModifyDocumentPermissionWithContext(ctx, input, opts...)
+}
+
+func TestRemoveShared(t *testing.T) {
+	fake := ssmfake.New()
+	d := newTestDocument(fake, "Test", "echo 1")
+	d.AccountIDs = accountIDs(25)
+	deploy(t, d)
+	updated := newTestDocument(fake, "Test", "echo 2")
+	updated.AccountIDs = d.AccountIDs
+	deploy(t, updated)
+	calls := fake.Calls("ModifyDocumentPermission")
+
+	res := d.NewResult()
+	err := d.Remove(context.Background(), res)
+	if err != nil {
+		t.Fatalf("unexpected remove error: %s", err)
+	}
+
+	if res.Action != ActionRemoved || len(res.AccountsRemoved) != 25 || !reflect.DeepEqual(res.VersionsRemoved, []string{"1", "2"}) {
+		t.Errorf("unexpected result: %+v", res)
+	}
+	if fake.Calls("ModifyDocumentPermission")-calls != 2 {
+		t.Errorf("expected 2 unshare calls, got %d", fake.Calls("ModifyDocumentPermission")-calls)
+	}
+	if fake.Document("Test") != nil {
+		t.Error("shared document not removed")
+	}
+}
+
+func TestRemoveKeepShared(t *testing.T) {
+	fake := ssmfake.New()
+	d := newTestDocument(fake, "Test", "echo 1")
+	d.AccountIDs = []string{"111111111111"}
+	deploy(t, d)
+
+	d.KeepShared = true
+	res := d.NewResult()
+	err := d.Remove(context.Background(), res)
+	if err != nil {
+		t.Fatalf("unexpected remove error: %s", err)
+	}
+
+	if res.Action != ActionUnshared || !reflect.DeepEqual(res.AccountsRemoved, []string{"111111111111"}) {
+		t.Errorf("unexpected result: %+v", res)
+	}
+	stored := fake.Document("Test")
+	if stored == nil || len(stored.AccountIDs) != 0 {
+		t.Errorf("document should be kept and unshared: %+v", stored)
+	}
+}
+
+func TestRemoveUnshareError(t *testing.T) {
+	fake := ssmfake.New()
+	d := newTestDocument(fake, "Test", "echo 1")
+	d.AccountIDs = accountIDs(25)
+	deploy(t, d)
+
+	d = NewWithClient(&failingUnshareClient{Fake: fake}, aws.String("us-east-1"), "Test")
+	res := d.NewResult()
+	err := d.Remove(context.Background(), res)
+	if err == nil {
+		t.Fatal("expected unshare error")
+	}
+
+	if res.Action == ActionRemoved || len(res.AccountsRemoved) != 20 {
+		t.Errorf("unexpected result: %+v", res)
+	}
+	if fake.Document("Test") == nil {
+		t.Error("document should not be deleted")
+	}
+}
+
+func TestRemoveForce(t *testing.T) {
+	fake := ssmfake.New()
+	fake.PutDocument(&ssmfake.Document{
+		Name:           "Schema",
+		Type:           ssm.DocumentTypeApplicationConfigurationSchema,
+		DefaultVersion: "1",
+		Versions:       []*ssmfake.Version{{Version: "1", Content: "{}", Format: "JSON"}},
+		Tags:           map[string]string{ManagedByTagKey: ManagedByTagValue},
+	})
+
+	d := NewWithClient(fake, aws.String("us-east-1"), "Schema")
+	res := d.NewResult()
+	err := d.Remove(context.Background(), res)
+	if err != nil {
+		t.Fatalf("unexpected remove error: %s", err)
+	}
+	if fake.Document("Schema") != nil {
+		t.Error("document not removed")
+	}
+}
@@ -0,0 +1,71 @@
+package document
+
+const (
+	// ActionCreated document was created
+	ActionCreated = "created"
+	// ActionUpdated document content was updated
+	ActionUpdated = "updated"
+	// ActionUnchanged document content was already up to date
+	ActionUnchanged = "unchanged"
+	// ActionRemoved document was removed
+	ActionRemoved = "removed"
+	// ActionNotDeployed document to remove was not deployed
+	ActionNotDeployed = "not-deployed"
+	// ActionValidated document passed validation
+	ActionValidated = "validated"
+	// ActionSkipped document was not processed
+	ActionSkipped = "skipped"
+	// ActionNotStarted document was not processed because of an interrupt
+	ActionNotStarted = "not-started"
+	// ActionSubmittedForReview document new version is waiting for approval
+	ActionSubmittedForReview = "submitted-for-review"
+	// ActionApproved document pending version was approved and set as default
+	ActionApproved = "approved"
+	// ActionRejected document pending version was rejected
+	ActionRejected = "rejected"
+	// ActionOrphan document is deployed but no more configured locally
+	ActionOrphan = "orphan"
+	// ActionUnshared document share permissions were removed but the document was kept
+	ActionUnshared = "unshared"
+	// ActionRestored document was re-created from a snapshot
+	ActionRestored = "restored"
+)
+
+// Result of an operation on a document
+type Result struct {
+	Name                    string   `yaml:"name" json:"name"`
+	Region                  string   `yaml:"region" json:"region"`
+	Action                  string   `yaml:"action" json:"action"`
+	Version                 string   `yaml:"version,omitempty" json:"version,omitempty"`
+	Hash                    string   `yaml:"hash,omitempty" json:"hash,omitempty"`
+	TagsAdded               []string `yaml:"tagsAdded,omitempty" json:"tagsAdded,omitempty"`
+	TagsRemoved             []string `yaml:"tagsRemoved,omitempty" json:"tagsRemoved,omitempty"`
+	AccountsAdded           []string `yaml:"accountsAdded,omitempty" json:"accountsAdded,omitempty"`
+	AccountsRemoved         []string `yaml:"accountsRemoved,omitempty" json:"accountsRemoved,omitempty"`
+	VersionsRemoved         []string `yaml:"versionsRemoved,omitempty" json:"versionsRemoved,omitempty"`
+	AssociationsCreated     []string `yaml:"associationsCreated,omitempty" json:"associationsCreated,omitempty"`
+	AssociationsUpdated     []string `yaml:"associationsUpdated,omitempty" json:"associationsUpdated,omitempty"`
+	AssociationsRemoved     []string `yaml:"associationsRemoved,omitempty" json:"associationsRemoved,omitempty"`
+	WindowsCreated          []string `yaml:"windowsCreated,omitempty" json:"windowsCreated,omitempty"`
+	WindowTasksRegistered   []string `yaml:"windowTasksRegistered,omitempty" json:"windowTasksRegistered,omitempty"`
+	WindowTasksUpdated      []string `yaml:"windowTasksUpdated,omitempty" json:"windowTasksUpdated,omitempty"`
+	WindowTasksDeregistered []string `yaml:"windowTasksDeregistered,omitempty" json:"windowTasksDeregistered,omitempty"`
+	Backup                  string   `yaml:"backup,omitempty" json:"backup,omitempty"`
+	Error                   string   `yaml:"error,omitempty" json:"error,omitempty"`
+	ErrorCode               string   `yaml:"errorCode,omitempty" json:"errorCode,omitempty"`
+	Duration                float64  `yaml:"duration" json:"duration"`
+}
+
+// NewResult creates a new Result for the document
+func (d *Document) NewResult() *Result {
+	region := ""
+	if d.region != nil {
+		region = *d.region
+	}
+
+	return &Result{
+		Name:   d.Name,
+		Region: region,
+		Action: ActionSkipped,
+	}
+}
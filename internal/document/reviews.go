@@ -0,0 +1,153 @@
+package document
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ssm"
+)
+
+// Review is the review state of a deployed document
+type Review struct {
+	Name            string `yaml:"name" json:"name"`
+	DefaultVersion  string `yaml:"defaultVersion" json:"defaultVersion"`
+	PendingVersion  string `yaml:"pendingVersion,omitempty" json:"pendingVersion,omitempty"`
+	ApprovedVersion string `yaml:"approvedVersion,omitempty" json:"approvedVersion,omitempty"`
+	Status          string `yaml:"status,omitempty" json:"status,omitempty"`
+	Author          string `yaml:"author,omitempty" json:"author,omitempty"`
+	Comment         string `yaml:"comment,omitempty" json:"comment,omitempty"`
+}
+
+// IsPending check if a version is waiting for review
+func (r *Review) IsPending() bool {
+	return len(r.PendingVersion) > 0
+}
+
+// GetReview return the document review state, with the comment of the pending version submission
+func (d *Document) GetReview(ctx context.Context) (*Review, error) {
+	res, err := d.clients.ssm.DescribeDocumentWithContext(ctx, &ssm.DescribeDocumentInput{
+		Name: &d.Name,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	review := &Review{
+		Name:            d.Name,
+		DefaultVersion:  aws.StringValue(res.Document.DefaultVersion),
+		PendingVersion:  aws.StringValue(res.Document.PendingReviewVersion),
+		ApprovedVersion: aws.StringValue(res.Document.ApprovedVersion),
+		Status:          aws.StringValue(res.Document.ReviewStatus),
+	}
+	if !review.IsPending() {
+		return review, nil
+	}
+	review.Status = ssm.ReviewStatusPending
+
+	// Load pending version submission
+	history, err := d.clients.ssm.ListDocumentMetadataHistoryWithContext(ctx, &ssm.ListDocumentMetadataHistoryInput{
+		Name:            &d.Name,
+		DocumentVersion: res.Document.PendingReviewVersion,
+		Metadata:        aws.String(ssm.DocumentMetadataEnumDocumentReviews),
+	})
+	if err != nil {
+		return nil, err
+	}
+	review.Author = aws.StringValue(history.Author)
+	if history.Metadata != nil {
+		for _, response := range history.Metadata.ReviewerResponse {
+			for _, comment := range response.Comment {
+				review.Comment = aws.StringValue(comment.Content)
+			}
+		}
+	}
+
+	return review, nil
+}
+
+// ValidateReview check the document type support reviews
+func (d *Document) ValidateReview() error {
+	if d.RequireApproval && d.Type != TypeChangeTemplate {
+		return fmt.Errorf("[%s] Approval is required but reviews are supported only by %s documents, type is %s", d.Name, TypeChangeTemplate, d.Type)
+	}
+	return nil
+}
+
+// SubmitForReview send a document version for review, default version is not changed
+func (d *Document) SubmitForReview(ctx context.Context, version string, comment string) error {
+	return d.updateReview(ctx, version, ssm.DocumentReviewActionSendForReview, comment)
+}
+
+// Approve approve the pending version and set it as default, return the approved version.
+// The caller cannot approve a version submitted by itself.
+func (d *Document) Approve(ctx context.Context, caller string, comment string) (string, error) {
+	review, err := d.GetReview(ctx)
+	if err != nil {
+		return "", err
+	}
+	if !review.IsPending() {
+		return "", fmt.Errorf("[%s] No version pending review", d.Name)
+	}
+	version := review.PendingVersion
+	if review.Author == caller {
+		return version, fmt.Errorf("[%s] Version %s was submitted by %s, it must be approved by a different identity", d.Name, version, caller)
+	}
+
+	err = d.updateReview(ctx, version, ssm.DocumentReviewActionApprove, comment)
+	if err != nil {
+		return version, err
+	}
+
+	_, err = d.clients.ssm.UpdateDocumentDefaultVersionWithContext(ctx, &ssm.UpdateDocumentDefaultVersionInput{
+		Name:            &d.Name,
+		DocumentVersion: aws.String(version),
+	})
+	return version, err
+}
+
+// Reject reject the pending version, default version is not changed, return the rejected version
+func (d *Document) Reject(ctx context.Context, comment string) (string, error) {
+	version, err := d.getPendingVersion(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return version, d.updateReview(ctx, version, ssm.DocumentReviewActionReject, comment)
+}
+
+// getPendingVersion return the version waiting for review
+func (d *Document) getPendingVersion(ctx context.Context) (string, error) {
+	res, err := d.clients.ssm.DescribeDocumentWithContext(ctx, &ssm.DescribeDocumentInput{
+		Name: &d.Name,
+	})
+	if err != nil {
+		return "", err
+	}
+	if res.Document.PendingReviewVersion == nil {
+		return "", fmt.Errorf("[%s] No version pending review", d.Name)
+	}
+	return *res.Document.PendingReviewVersion, nil
+}
+
+// updateReview execute a review action on document version
+func (d *Document) updateReview(ctx context.Context, version string, action string, comment string) error {
+	reviews := &ssm.DocumentReviews{
+		Action: aws.String(action),
+	}
+	if len(comment) > 0 {
+		reviews.Comment = []*ssm.DocumentReviewCommentSource{
+			{
+				Type:    aws.String(ssm.DocumentReviewCommentTypeComment),
+				Content: aws.String(comment),
+			},
+		}
+	}
+
+	_, err := d.clients.ssm.UpdateDocumentMetadataWithContext(ctx, &ssm.UpdateDocumentMetadataInput{
+		Name:            &d.Name,
+		DocumentVersion: aws.String(version),
+		DocumentReviews: reviews,
+	})
+	return err
+}
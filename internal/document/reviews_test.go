@@ -0,0 +1,127 @@
+package document
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/daaru00/aws-ssm-document-cli/pkg/ssmfake"
+)
+
+func newTestChangeTemplate(fake *ssmfake.Fake, name string, duration string) *Document {
+	d := newTestDocument(fake, name, "")
+	d.Type = TypeChangeTemplate
+	d.Content = Content{
+		SchemaVersion: "0.3",
+		Description:   "Test change template",
+		MainSteps: []MainStep{
+			{
+				Action: "aws:sleep",
+				Name:   "wait",
+				Inputs: map[string]interface{}{"Duration": duration},
+			},
+		},
+	}
+	return d
+}
+
+func TestDeployRequireApproval(t *testing.T) {
+	fake := ssmfake.New()
+	deploy(t, newTestChangeTemplate(fake, "Test", "PT1M"))
+
+	d := newTestChangeTemplate(fake, "Test", "PT2M")
+	d.RequireApproval = true
+	d.ReviewComment = "new command"
+	res := deploy(t, d)
+
+	if res.Action != ActionSubmittedForReview || res.Version != "2" {
+		t.Errorf("unexpected result: %+v", res)
+	}
+	if fake.Document("Test").DefaultVersion != "1" {
+		t.Errorf("default version changed before approval")
+	}
+
+	review, err := d.GetReview(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !review.IsPending() || review.PendingVersion != "2" || review.Comment != "new command" {
+		t.Errorf("unexpected review: %+v", review)
+	}
+}
+
+func TestApprove(t *testing.T) {
+	fake := ssmfake.New()
+	deploy(t, newTestChangeTemplate(fake, "Test", "PT1M"))
+	d := newTestChangeTemplate(fake, "Test", "PT2M")
+	d.RequireApproval = true
+	deploy(t, d)
+
+	// Author cannot approve its own version
+	_, err := d.Approve(context.Background(), fake.CallerArn(), "")
+	if err == nil || !strings.Contains(err.Error(), "different identity") {
+		t.Errorf("expected self approval error, got %v", err)
+	}
+	if fake.Document("Test").DefaultVersion != "1" {
+		t.Errorf("default version changed on self approval")
+	}
+
+	fake.CallerName = "reviewer"
+	version, err := d.Approve(context.Background(), fake.CallerArn(), "looks good")
+	if err != nil || version != "2" {
+		t.Fatalf("unexpected approve result: %s %v", version, err)
+	}
+	if fake.Document("Test").DefaultVersion != "2" {
+		t.Errorf("default version not updated after approval")
+	}
+
+	_, err = d.Approve(context.Background(), fake.CallerArn(), "")
+	if err == nil {
+		t.Errorf("expected error approving without pending version")
+	}
+}
+
+func TestReject(t *testing.T) {
+	fake := ssmfake.New()
+	deploy(t, newTestChangeTemplate(fake, "Test", "PT1M"))
+	d := newTestChangeTemplate(fake, "Test", "PT2M")
+	d.RequireApproval = true
+	deploy(t, d)
+
+	version, err := d.Reject(context.Background(), "not now")
+	if err != nil || version != "2" {
+		t.Fatalf("unexpected reject result: %s %v", version, err)
+	}
+	if fake.Document("Test").DefaultVersion != "1" {
+		t.Errorf("default version changed after rejection")
+	}
+
+	review, err := d.GetReview(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if review.IsPending() {
+		t.Errorf("unexpected pending review: %+v", review)
+	}
+}
+
+func TestRequireApprovalUnsupportedType(t *testing.T) {
+	fake := ssmfake.New()
+	deploy(t, newTestDocument(fake, "Test", "echo 1"))
+	d := newTestDocument(fake, "Test", "echo 2")
+	d.RequireApproval = true
+
+	err := d.ValidateReview()
+	if err == nil {
+		t.Errorf("expected unsupported type validation error")
+	}
+
+	// SSM reject reviews of documents that are not change templates
+	err = d.Deploy(context.Background(), d.NewResult())
+	awsErr, ok := err.(awserr.Error)
+	if !ok || awsErr.Code() != ssm.ErrCodeInvalidDocumentOperation {
+		t.Errorf("expected invalid document operation error, got %v", err)
+	}
+}
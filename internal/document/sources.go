@@ -0,0 +1,43 @@
+package document
+
+import (
+	"path/filepath"
+)
+
+// GetSourceFiles return the local files the document is generated from: the configuration file,
+// the script file, package files and automation script files
+func (d *Document) GetSourceFiles() []string {
+	files := []string{}
+	add := func(file string) {
+		if len(file) > 0 {
+			files = append(files, filepath.Clean(file))
+		}
+	}
+
+	// Paths already resolved by the loader
+	add(d.ConfigFile)
+	add(d.File)
+	if d.Package != nil {
+		for _, file := range d.Package.Files {
+			add(file.File)
+		}
+	}
+
+	// Automation script files are relative to the configuration directory
+	for _, step := range d.Content.MainSteps {
+		if step.Action != ActionExecuteScript {
+			continue
+		}
+		decoded, err := step.DecodeInputs()
+		if err != nil {
+			continue
+		}
+		scriptFile := decoded.(*ExecuteScriptInput).ScriptFile
+		if len(scriptFile) > 0 && !filepath.IsAbs(scriptFile) {
+			scriptFile = filepath.Join(d.Dir, scriptFile)
+		}
+		add(scriptFile)
+	}
+
+	return files
+}
@@ -0,0 +1,32 @@
+package document
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGetSourceFiles(t *testing.T) {
+	d := &Document{
+		Dir:        "documents/patch",
+		ConfigFile: "documents/patch/document.yml",
+		File:       "documents/patch/script.sh",
+		Package: &Package{
+			Files: []PackageFile{{File: "/abs/agent.zip"}},
+		},
+		Content: Content{
+			MainSteps: []MainStep{
+				{Action: ActionExecuteScript, Name: "run", Inputs: map[interface{}]interface{}{"Runtime": "python3.8", "Handler": "handler", "scriptFile": "handler.py"}},
+			},
+		},
+	}
+
+	expected := []string{
+		"documents/patch/document.yml",
+		"documents/patch/script.sh",
+		"/abs/agent.zip",
+		"documents/patch/handler.py",
+	}
+	if files := d.GetSourceFiles(); !reflect.DeepEqual(files, expected) {
+		t.Errorf("expected %v, got %v", expected, files)
+	}
+}
@@ -0,0 +1,26 @@
+package document
+
+// Fixture is a file created in the sandbox before a test case is executed,
+// from a local source file or from inline content
+type Fixture struct {
+	Source  string `yaml:"source,omitempty" json:"source,omitempty"`
+	Content string `yaml:"content,omitempty" json:"content,omitempty"`
+	Target  string `yaml:"target" json:"target"`
+}
+
+// Expectation of a test case execution
+type Expectation struct {
+	ExitCode int      `yaml:"exitCode" json:"exitCode"`
+	Stdout   []string `yaml:"stdout,omitempty" json:"stdout,omitempty"`
+	Stderr   []string `yaml:"stderr,omitempty" json:"stderr,omitempty"`
+	Files    []string `yaml:"files,omitempty" json:"files,omitempty"`
+}
+
+// TestCase declared for a document
+type TestCase struct {
+	Name     string            `yaml:"name" json:"name"`
+	Params   map[string]string `yaml:"params,omitempty" json:"params,omitempty"`
+	Env      map[string]string `yaml:"env,omitempty" json:"env,omitempty"`
+	Fixtures []Fixture         `yaml:"fixtures,omitempty" json:"fixtures,omitempty"`
+	Expect   Expectation       `yaml:"expect" json:"expect"`
+}
@@ -0,0 +1,333 @@
+package document
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	jsoniter "github.com/json-iterator/go"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	// TypeCommand run commands on managed instances
+	TypeCommand = "Command"
+	// TypeSession configure Session Manager sessions
+	TypeSession = "Session"
+	// TypePackage is a Distributor package
+	TypePackage = "Package"
+	// TypeChangeCalendar is a Change Calendar built from an iCalendar file
+	TypeChangeCalendar = "ChangeCalendar"
+	// TypePolicy is used by State Manager to collect inventory
+	TypePolicy = "Policy"
+	// TypeAutomation run automation runbooks
+	TypeAutomation = "Automation"
+	// TypeChangeTemplate is a Change Manager template, the only type that support reviews
+	TypeChangeTemplate = "Automation.ChangeTemplate"
+)
+
+// supportedTypes are the document types managed by this CLI
+var supportedTypes = []string{TypeCommand, TypeSession, TypePackage, TypeChangeCalendar, TypePolicy, TypeAutomation, TypeChangeTemplate}
+
+// schemaVersions are the schema versions supported by each document type
+var schemaVersions = map[string][]string{
+	TypeCommand:        {"1.2", "2.0", "2.2"},
+	TypePolicy:         {"2.0"},
+	TypeAutomation:     {"0.3"},
+	TypeChangeTemplate: {"0.3"},
+	TypeSession:        {"1.0"},
+	TypePackage:        {"2.0"},
+}
+
+// sessionTypes are the supported Session document types
+var sessionTypes = []string{"Standard_Stream", "InteractiveCommands", "NonInteractiveCommands", "Port"}
+
+// ShellProfile commands executed at session start
+type ShellProfile struct {
+	Windows string `yaml:"windows,omitempty" json:"windows,omitempty"`
+	Linux   string `yaml:"linux,omitempty" json:"linux,omitempty"`
+}
+
+// SessionInputs are the Session Manager preferences
+type SessionInputs struct {
+	S3BucketName                string        `yaml:"s3BucketName,omitempty" json:"s3BucketName,omitempty"`
+	S3KeyPrefix                 string        `yaml:"s3KeyPrefix,omitempty" json:"s3KeyPrefix,omitempty"`
+	S3EncryptionEnabled         *bool         `yaml:"s3EncryptionEnabled,omitempty" json:"s3EncryptionEnabled,omitempty"`
+	CloudWatchLogGroupName      string        `yaml:"cloudWatchLogGroupName,omitempty" json:"cloudWatchLogGroupName,omitempty"`
+	CloudWatchEncryptionEnabled *bool         `yaml:"cloudWatchEncryptionEnabled,omitempty" json:"cloudWatchEncryptionEnabled,omitempty"`
+	CloudWatchStreamingEnabled  *bool         `yaml:"cloudWatchStreamingEnabled,omitempty" json:"cloudWatchStreamingEnabled,omitempty"`
+	KmsKeyID                    string        `yaml:"kmsKeyId,omitempty" json:"kmsKeyId,omitempty"`
+	RunAsEnabled                *bool         `yaml:"runAsEnabled,omitempty" json:"runAsEnabled,omitempty"`
+	RunAsDefaultUser            string        `yaml:"runAsDefaultUser,omitempty" json:"runAsDefaultUser,omitempty"`
+	IdleSessionTimeout          string        `yaml:"idleSessionTimeout,omitempty" json:"idleSessionTimeout,omitempty"`
+	MaxSessionDuration          string        `yaml:"maxSessionDuration,omitempty" json:"maxSessionDuration,omitempty"`
+	ShellProfile                *ShellProfile `yaml:"shellProfile,omitempty" json:"shellProfile,omitempty"`
+}
+
+// SessionCommand is the command executed by InteractiveCommands and NonInteractiveCommands sessions
+type SessionCommand struct {
+	Commands      interface{} `yaml:"commands" json:"commands"`
+	RunAsElevated *bool       `yaml:"runAsElevated,omitempty" json:"runAsElevated,omitempty"`
+}
+
+// SessionProperties configure InteractiveCommands, NonInteractiveCommands and Port sessions
+type SessionProperties struct {
+	Windows         *SessionCommand `yaml:"windows,omitempty" json:"windows,omitempty"`
+	Linux           *SessionCommand `yaml:"linux,omitempty" json:"linux,omitempty"`
+	MacOS           *SessionCommand `yaml:"macos,omitempty" json:"macos,omitempty"`
+	PortNumber      string          `yaml:"portNumber,omitempty" json:"portNumber,omitempty"`
+	Type            string          `yaml:"type,omitempty" json:"type,omitempty"`
+	Host            string          `yaml:"host,omitempty" json:"host,omitempty"`
+	LocalPortNumber string          `yaml:"localPortNumber,omitempty" json:"localPortNumber,omitempty"`
+}
+
+// sessionContent is the Session document content, without command fields
+type sessionContent struct {
+	SchemaVersion string               `json:"schemaVersion"`
+	Description   string               `json:"description,omitempty"`
+	SessionType   string               `json:"sessionType"`
+	Inputs        *SessionInputs       `json:"inputs,omitempty"`
+	Properties    *SessionProperties   `json:"properties,omitempty"`
+	Parameters    map[string]Parameter `json:"parameters,omitempty"`
+}
+
+// PackageFile is a package archive for a platform and architecture
+type PackageFile struct {
+	Platform        string `yaml:"platform" json:"platform"`
+	PlatformVersion string `yaml:"platformVersion,omitempty" json:"platformVersion,omitempty"`
+	Architecture    string `yaml:"architecture" json:"architecture"`
+	File            string `yaml:"file" json:"file"`
+}
+
+// Package configuration, used to generate the Distributor manifest
+type Package struct {
+	Version   string        `yaml:"version" json:"version"`
+	Publisher string        `yaml:"publisher,omitempty" json:"publisher,omitempty"`
+	SourceURL string        `yaml:"sourceUrl" json:"sourceUrl"`
+	Files     []PackageFile `yaml:"files" json:"files"`
+}
+
+// PackageFileRef reference a manifest file
+type PackageFileRef struct {
+	File string `yaml:"file" json:"file"`
+}
+
+// PackageChecksums of a manifest file
+type PackageChecksums struct {
+	Checksums map[string]string `yaml:"checksums" json:"checksums"`
+}
+
+// PackageManifest is the Distributor package manifest,
+// packages are indexed by platform, platform version and architecture
+type PackageManifest struct {
+	SchemaVersion string                                          `yaml:"schemaVersion" json:"schemaVersion"`
+	Version       string                                          `yaml:"version" json:"version"`
+	Publisher     string                                          `yaml:"publisher,omitempty" json:"publisher,omitempty"`
+	Packages      map[string]map[string]map[string]PackageFileRef `yaml:"packages" json:"packages"`
+	Files         map[string]PackageChecksums                     `yaml:"files" json:"files"`
+}
+
+// GetManifest generate the package manifest computing files checksums
+func (p *Package) GetManifest() (*PackageManifest, error) {
+	manifest := &PackageManifest{
+		SchemaVersion: "2.0",
+		Version:       p.Version,
+		Publisher:     p.Publisher,
+		Packages:      map[string]map[string]map[string]PackageFileRef{},
+		Files:         map[string]PackageChecksums{},
+	}
+
+	sources := map[string]string{}
+	for _, file := range p.Files {
+		if len(file.Platform) == 0 || len(file.Architecture) == 0 || len(file.File) == 0 {
+			return nil, fmt.Errorf("Package file requires platform, architecture and file")
+		}
+		platformVersion := file.PlatformVersion
+		if len(platformVersion) == 0 {
+			platformVersion = "_any"
+		}
+
+		// Files are referenced by name, attachments are flat
+		name := filepath.Base(file.File)
+		if source, exist := sources[name]; exist && source != file.File {
+			return nil, fmt.Errorf("Package files %s and %s have the same name", source, file.File)
+		}
+		sources[name] = file.File
+
+		// Compute checksum
+		content, err := ioutil.ReadFile(file.File)
+		if err != nil {
+			return nil, err
+		}
+		checksum := sha256.Sum256(content)
+		manifest.Files[name] = PackageChecksums{
+			Checksums: map[string]string{"sha256": hex.EncodeToString(checksum[:])},
+		}
+
+		// Add package reference
+		if _, exist := manifest.Packages[file.Platform]; !exist {
+			manifest.Packages[file.Platform] = map[string]map[string]PackageFileRef{}
+		}
+		if _, exist := manifest.Packages[file.Platform][platformVersion]; !exist {
+			manifest.Packages[file.Platform][platformVersion] = map[string]PackageFileRef{}
+		}
+		manifest.Packages[file.Platform][platformVersion][file.Architecture] = PackageFileRef{File: name}
+	}
+
+	return manifest, nil
+}
+
+// GetAttachments return the attachments source of package files
+func (p *Package) GetAttachments() []*ssm.AttachmentsSource {
+	return []*ssm.AttachmentsSource{
+		{
+			Key:    aws.String(ssm.AttachmentsSourceKeySourceUrl),
+			Values: aws.StringSlice([]string{p.SourceURL}),
+		},
+	}
+}
+
+// getTypedContent return the content to marshal for document type
+func (d *Document) getTypedContent() (interface{}, error) {
+	switch d.Type {
+	case TypeSession:
+		return sessionContent{
+			SchemaVersion: d.Content.SchemaVersion,
+			Description:   d.Content.Description,
+			SessionType:   d.Content.SessionType,
+			Inputs:        d.Content.Inputs,
+			Properties:    d.Content.Properties,
+			Parameters:    d.Content.Parameters,
+		}, nil
+	default:
+		return d.Content, nil
+	}
+}
+
+// GetPackageContent return the generated package manifest as JSON string
+func (d *Document) GetPackageContent() (string, error) {
+	manifest, err := d.Package.GetManifest()
+	if err != nil {
+		return "", err
+	}
+
+	marshal, err := jsoniter.Marshal(manifest)
+	if err != nil {
+		return "", err
+	}
+
+	return string(marshal), nil
+}
+
+// validationContent is the superset of all document types content
+type validationContent struct {
+	Content  `yaml:",inline"`
+	Version  string                                          `yaml:"version"`
+	Packages map[string]map[string]map[string]PackageFileRef `yaml:"packages"`
+	Files    map[string]PackageChecksums                     `yaml:"files"`
+}
+
+// ValidateType check generated content against the document type rules
+func (d *Document) ValidateType(format string, content string) error {
+	if !contains(supportedTypes, d.Type) {
+		return fmt.Errorf("type %s is not supported, valid values are %s", d.Type, strings.Join(supportedTypes, ", "))
+	}
+	if d.Package != nil && d.Type != TypePackage {
+		return fmt.Errorf("package configuration requires type %s", TypePackage)
+	}
+
+	// Change calendars are iCalendar text
+	if d.Type == TypeChangeCalendar {
+		if format != ssm.DocumentFormatText {
+			return fmt.Errorf("%s content must be an iCalendar (.ics) file", TypeChangeCalendar)
+		}
+		trimmed := strings.TrimSpace(content)
+		if !strings.HasPrefix(trimmed, "BEGIN:VCALENDAR") || !strings.HasSuffix(trimmed, "END:VCALENDAR") {
+			return fmt.Errorf("%s content is not a valid iCalendar, expected BEGIN:VCALENDAR and END:VCALENDAR", TypeChangeCalendar)
+		}
+		return nil
+	}
+	if format == ssm.DocumentFormatText {
+		return fmt.Errorf("TEXT format is only supported by %s documents", TypeChangeCalendar)
+	}
+
+	// Parse content
+	parsed := validationContent{}
+	err := yaml.Unmarshal([]byte(content), &parsed)
+	if err != nil {
+		return fmt.Errorf("Invalid %s content: %s", format, err)
+	}
+	if !contains(schemaVersions[d.Type], parsed.SchemaVersion) {
+		return fmt.Errorf("schemaVersion %q is not supported by %s documents, valid values are %s", parsed.SchemaVersion, d.Type, strings.Join(schemaVersions[d.Type], ", "))
+	}
+
+	switch d.Type {
+	case TypeCommand, TypePolicy, TypeAutomation, TypeChangeTemplate:
+		if parsed.SchemaVersion != "1.2" && len(parsed.MainSteps) == 0 {
+			return fmt.Errorf("%s documents require mainSteps", d.Type)
+		}
+		return ValidateSteps(parsed.MainSteps)
+	case TypeSession:
+		return validateSession(parsed.Content)
+	case TypePackage:
+		if len(parsed.Version) == 0 || len(parsed.Packages) == 0 || len(parsed.Files) == 0 {
+			return fmt.Errorf("%s manifest requires version, packages and files", TypePackage)
+		}
+		for platform, versions := range parsed.Packages {
+			for _, architectures := range versions {
+				for architecture, ref := range architectures {
+					if _, exist := parsed.Files[ref.File]; !exist {
+						return fmt.Errorf("%s manifest reference file %s for %s/%s not declared in files", TypePackage, ref.File, platform, architecture)
+					}
+				}
+			}
+		}
+		if d.Package != nil && len(d.Package.SourceURL) == 0 {
+			return fmt.Errorf("%s configuration requires sourceUrl", TypePackage)
+		}
+	}
+
+	return nil
+}
+
+// validateSession check session type requirements
+func validateSession(content Content) error {
+	if !contains(sessionTypes, content.SessionType) {
+		return fmt.Errorf("sessionType %q is not valid, valid values are %s", content.SessionType, strings.Join(sessionTypes, ", "))
+	}
+	if len(content.MainSteps) > 0 {
+		return fmt.Errorf("%s documents do not support mainSteps", TypeSession)
+	}
+
+	switch content.SessionType {
+	case "Standard_Stream":
+		if content.Inputs == nil {
+			return fmt.Errorf("sessionType Standard_Stream requires inputs")
+		}
+	case "InteractiveCommands", "NonInteractiveCommands":
+		if content.Properties == nil || (content.Properties.Linux == nil && content.Properties.Windows == nil && content.Properties.MacOS == nil) {
+			return fmt.Errorf("sessionType %s requires properties with linux, windows or macos commands", content.SessionType)
+		}
+	case "Port":
+		if content.Properties == nil || len(content.Properties.PortNumber) == 0 {
+			return fmt.Errorf("sessionType Port requires properties.portNumber")
+		}
+	}
+
+	return nil
+}
+
+// contains check if value is in list
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,124 @@
+package document
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+func TestSessionContent(t *testing.T) {
+	d := &Document{Name: "Session"}
+	err := yaml.Unmarshal([]byte(`
+type: Session
+content:
+  schemaVersion: "1.0"
+  sessionType: Port
+  properties:
+    portNumber: "8080"
+    type: LocalPortForwarding
+`), d)
+	if err != nil {
+		t.Fatalf("cannot parse document: %s", err)
+	}
+
+	format, content, err := d.GetContent()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if strings.Contains(*content, "mainSteps") || !strings.Contains(*content, `"sessionType":"Port"`) {
+		t.Errorf("unexpected session content: %s", *content)
+	}
+
+	err = d.ValidateType(*format, *content)
+	if err != nil {
+		t.Errorf("unexpected validation error: %s", err)
+	}
+
+	d.Content.Properties = nil
+	format, content, _ = d.GetContent()
+	err = d.ValidateType(*format, *content)
+	if err == nil || !strings.Contains(err.Error(), "portNumber") {
+		t.Errorf("expected portNumber error, got %v", err)
+	}
+}
+
+func TestPackageManifest(t *testing.T) {
+	dir, err := ioutil.TempDir("", "package")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "agent.zip")
+	ioutil.WriteFile(file, []byte("zip"), 0644)
+
+	d := &Document{
+		Name: "Package",
+		Type: TypePackage,
+		Package: &Package{
+			Version:   "1.0.0",
+			SourceURL: "s3://bucket/agent",
+			Files: []PackageFile{
+				{Platform: "amazon", Architecture: "x86_64", File: file},
+				{Platform: "ubuntu", PlatformVersion: "22.04", Architecture: "x86_64", File: file},
+			},
+		},
+	}
+
+	format, content, err := d.GetContent()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := []string{
+		`"amazon":{"_any":{"x86_64":{"file":"agent.zip"}}}`,
+		`"ubuntu":{"22.04":{"x86_64":{"file":"agent.zip"}}}`,
+		`"agent.zip":{"checksums":{"sha256":"4a70fe9aa6436e02c2dea340fbd1e352e4ef2d8ce6ca52ad25d4b95471fc8bf2"}}`,
+	}
+	for _, fragment := range expected {
+		if !strings.Contains(*content, fragment) {
+			t.Errorf("manifest does not contain %s: %s", fragment, *content)
+		}
+	}
+
+	err = d.ValidateType(*format, *content)
+	if err != nil {
+		t.Errorf("unexpected validation error: %s", err)
+	}
+	if *d.Package.GetAttachments()[0].Values[0] != "s3://bucket/agent" {
+		t.Error("unexpected attachments")
+	}
+}
+
+func TestValidateType(t *testing.T) {
+	cases := []struct {
+		name    string
+		docType string
+		format  string
+		content string
+		err     string
+	}{
+		{"command", TypeCommand, "JSON", `{"schemaVersion":"2.2","mainSteps":[{"action":"aws:runShellScript","name":"run","inputs":{"runCommand":"echo ok"}}]}`, ""},
+		{"command without steps", TypeCommand, "JSON", `{"schemaVersion":"2.2"}`, "require mainSteps"},
+		{"automation schema", TypeAutomation, "JSON", `{"schemaVersion":"2.2","mainSteps":[{"action":"aws:sleep","name":"wait"}]}`, "schemaVersion"},
+		{"policy", TypePolicy, "YAML", "schemaVersion: '2.0'\nmainSteps:\n  - action: aws:softwareInventory\n    name: inventory\n", ""},
+		{"session steps", TypeSession, "JSON", `{"schemaVersion":"1.0","sessionType":"Standard_Stream","inputs":{},"mainSteps":[{}]}`, "do not support mainSteps"},
+		{"calendar", TypeChangeCalendar, "TEXT", "BEGIN:VCALENDAR\nVERSION:2.0\nEND:VCALENDAR\n", ""},
+		{"calendar not ics", TypeChangeCalendar, "TEXT", "hello", "not a valid iCalendar"},
+		{"text command", TypeCommand, "TEXT", "hello", "only supported by ChangeCalendar"},
+		{"unknown type", "Unknown", "JSON", `{}`, "not supported"},
+	}
+
+	for _, c := range cases {
+		d := &Document{Name: c.name, Type: c.docType}
+		err := d.ValidateType(c.format, c.content)
+		if len(c.err) == 0 && err != nil {
+			t.Errorf("%s: unexpected error: %s", c.name, err)
+		}
+		if len(c.err) > 0 && (err == nil || !strings.Contains(err.Error(), c.err)) {
+			t.Errorf("%s: expected error %q, got %v", c.name, c.err, err)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package document
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Validate check document configuration and generated content without calling AWS
+func (d *Document) Validate() error {
+	// Check name
+	if len(d.Name) == 0 {
+		return errors.New("Document name is required")
+	}
+
+	// Check parameters
+	_, err := d.ValidateParameters()
+	if err != nil {
+		return err
+	}
+
+	// Check steps inputs
+	err = d.ValidateSteps()
+	if err != nil {
+		return err
+	}
+
+	// Check reviews support
+	err = d.ValidateReview()
+	if err != nil {
+		return err
+	}
+
+	// Check associations
+	err = d.ValidateAssociations()
+	if err != nil {
+		return err
+	}
+
+	// Check maintenance window tasks
+	err = d.ValidateMaintenanceWindows()
+	if err != nil {
+		return err
+	}
+
+	// Check content generation
+	format, content, err := d.GetContent()
+	if err != nil {
+		return fmt.Errorf("[%s] %s", d.Name, err)
+	}
+
+	// Check content syntax
+	if *format != "TEXT" {
+		parsed := map[string]interface{}{}
+		err = yaml.Unmarshal([]byte(*content), &parsed)
+		if err != nil {
+			return fmt.Errorf("[%s] Invalid %s content: %s", d.Name, *format, err)
+		}
+		if _, ok := parsed["schemaVersion"]; !ok {
+			return fmt.Errorf("[%s] Content has no schemaVersion", d.Name)
+		}
+	}
+
+	// Check document type rules
+	err = d.ValidateType(*format, *content)
+	if err != nil {
+		return fmt.Errorf("[%s] %s", d.Name, err)
+	}
+
+	// Check script files
+	_, _, err = d.buildSteps(context.Background(), BuildOptions{})
+	if err != nil {
+		return err
+	}
+
+	// Check dependencies
+	_, err = d.GetDependencies()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,514 @@
+package document
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ssm"
+)
+
+// windowTargetKeys are the target keys allowed for maintenance window tasks
+var windowTargetKeys = []string{"WindowTargetIds", "InstanceIds"}
+
+// windowsMu serialize maintenance windows lookup and creation, window names are not unique
+// so documents deployed in parallel must not create the same window twice
+var windowsMu sync.Mutex
+
+// MaintenanceWindowTask register the document as a task of a maintenance window,
+// tracked by window name and task name. The registered task name has the ManagedNamePrefix,
+// tasks without it are not managed.
+type MaintenanceWindowTask struct {
+	Window          string                `yaml:"window" json:"window"`
+	Name            string                `yaml:"name,omitempty" json:"name,omitempty"`
+	Type            string                `yaml:"type,omitempty" json:"type,omitempty"`
+	DocumentVersion string                `yaml:"documentVersion,omitempty" json:"documentVersion,omitempty"`
+	Priority        int64                 `yaml:"priority,omitempty" json:"priority,omitempty"`
+	MaxConcurrency  string                `yaml:"maxConcurrency,omitempty" json:"maxConcurrency,omitempty"`
+	MaxErrors       string                `yaml:"maxErrors,omitempty" json:"maxErrors,omitempty"`
+	Targets         []Target              `yaml:"targets,omitempty" json:"targets,omitempty"`
+	Parameters      map[string]StringList `yaml:"parameters,omitempty" json:"parameters,omitempty"`
+	ServiceRoleArn  string                `yaml:"serviceRoleArn,omitempty" json:"serviceRoleArn,omitempty"`
+
+	// Window creation, when create is set a missing window is created with schedule
+	Create   bool   `yaml:"create,omitempty" json:"create,omitempty"`
+	Schedule string `yaml:"schedule,omitempty" json:"schedule,omitempty"`
+	Duration int64  `yaml:"duration,omitempty" json:"duration,omitempty"`
+	Cutoff   int64  `yaml:"cutoff,omitempty" json:"cutoff,omitempty"`
+}
+
+// Validate check maintenance window task configuration
+func (t *MaintenanceWindowTask) Validate() error {
+	if len(t.Window) == 0 {
+		return errors.New("window is required")
+	}
+	if len(t.Type) > 0 && t.Type != ssm.MaintenanceWindowTaskTypeRunCommand && t.Type != ssm.MaintenanceWindowTaskTypeAutomation {
+		return fmt.Errorf("type %s is not valid, use %s or %s", t.Type, ssm.MaintenanceWindowTaskTypeRunCommand, ssm.MaintenanceWindowTaskTypeAutomation)
+	}
+	if t.Type == ssm.MaintenanceWindowTaskTypeRunCommand && len(t.Targets) == 0 {
+		return errors.New("targets are required for RUN_COMMAND tasks")
+	}
+	for _, target := range t.Targets {
+		if !contains(windowTargetKeys, target.Key) || len(target.Values) == 0 {
+			return errors.New("targets require values and key WindowTargetIds or InstanceIds")
+		}
+	}
+	if len(t.Targets) > 0 && (len(t.MaxConcurrency) == 0 || len(t.MaxErrors) == 0) {
+		return errors.New("maxConcurrency and maxErrors are required for tasks with targets")
+	}
+	if t.Priority < 0 {
+		return errors.New("priority must be greater or equal than 0")
+	}
+	if !t.Create && (len(t.Schedule) > 0 || t.Duration > 0 || t.Cutoff > 0) {
+		return errors.New("schedule, duration and cutoff require create to be set")
+	}
+	if t.Create && len(t.Schedule) == 0 {
+		return errors.New("schedule is required to create the window")
+	}
+	if t.Create && (t.Duration < 1 || t.Duration > 24) {
+		return errors.New("duration between 1 and 24 hours is required to create the window")
+	}
+	if t.Create && (t.Cutoff < 0 || t.Cutoff >= t.Duration) {
+		return errors.New("cutoff must be between 0 and duration")
+	}
+	return nil
+}
+
+// withDefaults return the task with name and type defaults applied
+func (t MaintenanceWindowTask) withDefaults(d *Document) MaintenanceWindowTask {
+	if len(t.Name) == 0 {
+		t.Name = d.Name
+	}
+	if len(t.Type) == 0 {
+		t.Type = d.windowTaskType()
+	}
+	return t
+}
+
+// normalized return the task with defaults applied and window creation fields removed, used to detect changes
+func (t MaintenanceWindowTask) normalized(d *Document) MaintenanceWindowTask {
+	t = t.withDefaults(d)
+	if len(t.Parameters) == 0 {
+		t.Parameters = nil
+	}
+	if len(t.Targets) == 0 {
+		t.Targets = nil
+	}
+	t.Create = false
+	t.Schedule = ""
+	t.Duration = 0
+	t.Cutoff = 0
+	return t
+}
+
+// targets return the task targets in SSM format
+func (t *MaintenanceWindowTask) targets() []*ssm.Target {
+	if len(t.Targets) == 0 {
+		return nil
+	}
+	targets := []*ssm.Target{}
+	for _, target := range t.Targets {
+		targets = append(targets, &ssm.Target{
+			Key:    aws.String(target.Key),
+			Values: aws.StringSlice(target.Values),
+		})
+	}
+	return targets
+}
+
+// invocation return the task invocation parameters in SSM format
+func (t *MaintenanceWindowTask) invocation() *ssm.MaintenanceWindowTaskInvocationParameters {
+	var parameters map[string][]*string
+	for name, values := range t.Parameters {
+		if parameters == nil {
+			parameters = map[string][]*string{}
+		}
+		parameters[name] = aws.StringSlice(values)
+	}
+
+	if t.Type == ssm.MaintenanceWindowTaskTypeAutomation {
+		return &ssm.MaintenanceWindowTaskInvocationParameters{
+			Automation: &ssm.MaintenanceWindowAutomationParameters{
+				DocumentVersion: optionalString(t.DocumentVersion),
+				Parameters:      parameters,
+			},
+		}
+	}
+	return &ssm.MaintenanceWindowTaskInvocationParameters{
+		RunCommand: &ssm.MaintenanceWindowRunCommandParameters{
+			DocumentVersion: optionalString(t.DocumentVersion),
+			Parameters:      parameters,
+		},
+	}
+}
+
+// windowTaskFromOutput convert a registered task to configuration
+func windowTaskFromOutput(window string, output *ssm.GetMaintenanceWindowTaskOutput) MaintenanceWindowTask {
+	task := MaintenanceWindowTask{
+		Window:         window,
+		Name:           strings.TrimPrefix(aws.StringValue(output.Name), ManagedNamePrefix),
+		Type:           aws.StringValue(output.TaskType),
+		Priority:       aws.Int64Value(output.Priority),
+		MaxConcurrency: aws.StringValue(output.MaxConcurrency),
+		MaxErrors:      aws.StringValue(output.MaxErrors),
+		ServiceRoleArn: aws.StringValue(output.ServiceRoleArn),
+	}
+	for _, target := range output.Targets {
+		task.Targets = append(task.Targets, Target{
+			Key:    aws.StringValue(target.Key),
+			Values: aws.StringValueSlice(target.Values),
+		})
+	}
+
+	var parameters map[string][]*string
+	if invocation := output.TaskInvocationParameters; invocation != nil {
+		if invocation.RunCommand != nil {
+			task.DocumentVersion = aws.StringValue(invocation.RunCommand.DocumentVersion)
+			parameters = invocation.RunCommand.Parameters
+		}
+		if invocation.Automation != nil {
+			task.DocumentVersion = aws.StringValue(invocation.Automation.DocumentVersion)
+			parameters = invocation.Automation.Parameters
+		}
+	}
+	for name, values := range parameters {
+		if task.Parameters == nil {
+			task.Parameters = map[string]StringList{}
+		}
+		task.Parameters[name] = aws.StringValueSlice(values)
+	}
+	return task
+}
+
+// windowTaskType return the maintenance window task type used to run the document
+func (d *Document) windowTaskType() string {
+	if d.Type == TypeAutomation {
+		return ssm.MaintenanceWindowTaskTypeAutomation
+	}
+	return ssm.MaintenanceWindowTaskTypeRunCommand
+}
+
+// ValidateMaintenanceWindows check maintenance window tasks configuration
+func (d *Document) ValidateMaintenanceWindows() error {
+	if len(d.MaintenanceWindows) == 0 {
+		return nil
+	}
+	if d.Type != TypeCommand && d.Type != TypeAutomation {
+		return fmt.Errorf("[%s] Maintenance window tasks are not supported for %s documents", d.Name, d.Type)
+	}
+
+	names := map[string]bool{}
+	for index, task := range d.MaintenanceWindows {
+		task = task.withDefaults(d)
+		err := task.Validate()
+		if err != nil {
+			return fmt.Errorf("[%s] Maintenance window task %d %s", d.Name, index+1, err)
+		}
+		if task.Type != d.windowTaskType() {
+			return fmt.Errorf("[%s] Maintenance window task %s type %s cannot run %s documents", d.Name, task.Name, task.Type, d.Type)
+		}
+		key := task.Window + "/" + task.Name
+		if names[key] {
+			return fmt.Errorf("[%s] Maintenance window task %s is declared more than once", d.Name, key)
+		}
+		names[key] = true
+	}
+	return nil
+}
+
+// windowIdentity is a maintenance window ID and name
+type windowIdentity struct {
+	id   string
+	name string
+}
+
+// listWindows return the maintenance windows with name, all windows when name is empty
+func (d *Document) listWindows(ctx context.Context, name string) ([]windowIdentity, error) {
+	windows := []windowIdentity{}
+
+	input := &ssm.DescribeMaintenanceWindowsInput{}
+	if len(name) > 0 {
+		input.Filters = []*ssm.MaintenanceWindowFilter{
+			{
+				Key:    aws.String("Name"),
+				Values: aws.StringSlice([]string{name}),
+			},
+		}
+	}
+	for {
+		res, err := d.clients.ssm.DescribeMaintenanceWindowsWithContext(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+		for _, window := range res.WindowIdentities {
+			windows = append(windows, windowIdentity{
+				id:   aws.StringValue(window.WindowId),
+				name: aws.StringValue(window.Name),
+			})
+		}
+		if res.NextToken == nil {
+			break
+		}
+		input.NextToken = res.NextToken
+	}
+
+	return windows, nil
+}
+
+// getWindowID search the maintenance window by name, when missing it is created if the task opt in with create.
+// Return the window ID, empty if not found and not created, and if it was created.
+func (d *Document) getWindowID(ctx context.Context, task MaintenanceWindowTask, create bool) (string, bool, error) {
+	windowsMu.Lock()
+	defer windowsMu.Unlock()
+
+	// Search window by name
+	windows, err := d.listWindows(ctx, task.Window)
+	if err != nil {
+		return "", false, err
+	}
+
+	switch {
+	case len(windows) > 1:
+		return "", false, fmt.Errorf("found %d maintenance windows named %s", len(windows), task.Window)
+	case len(windows) == 1:
+		return windows[0].id, false, nil
+	case !create || !task.Create:
+		return "", false, nil
+	}
+
+	// Create missing window
+	res, err := d.clients.ssm.CreateMaintenanceWindowWithContext(ctx, &ssm.CreateMaintenanceWindowInput{
+		Name:                     aws.String(task.Window),
+		Schedule:                 aws.String(task.Schedule),
+		Duration:                 aws.Int64(task.Duration),
+		Cutoff:                   aws.Int64(task.Cutoff),
+		AllowUnassociatedTargets: aws.Bool(false),
+	})
+	if err != nil {
+		return "", false, err
+	}
+	return aws.StringValue(res.WindowId), true, nil
+}
+
+// getWindowTasks return the tasks of the document registered by this tool with the window, indexed by configured name
+func (d *Document) getWindowTasks(ctx context.Context, windowID string) (map[string]*ssm.GetMaintenanceWindowTaskOutput, error) {
+	tasks := map[string]*ssm.GetMaintenanceWindowTaskOutput{}
+
+	input := &ssm.DescribeMaintenanceWindowTasksInput{
+		WindowId: aws.String(windowID),
+		Filters: []*ssm.MaintenanceWindowFilter{
+			{
+				Key:    aws.String("TaskArn"),
+				Values: aws.StringSlice([]string{d.Name}),
+			},
+		},
+	}
+	for {
+		res, err := d.clients.ssm.DescribeMaintenanceWindowTasksWithContext(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, task := range res.Tasks {
+			// Tasks without name or prefix are not registered by this tool
+			name := aws.StringValue(task.Name)
+			if !strings.HasPrefix(name, ManagedNamePrefix) {
+				continue
+			}
+
+			// Load full task configuration
+			taskRes, err := d.clients.ssm.GetMaintenanceWindowTaskWithContext(ctx, &ssm.GetMaintenanceWindowTaskInput{
+				WindowId:     aws.String(windowID),
+				WindowTaskId: task.WindowTaskId,
+			})
+			if err != nil {
+				return nil, err
+			}
+			tasks[strings.TrimPrefix(name, ManagedNamePrefix)] = taskRes
+		}
+
+		if res.NextToken == nil {
+			break
+		}
+		input.NextToken = res.NextToken
+	}
+
+	return tasks, nil
+}
+
+// windowsTasks group the configured tasks by window name, keeping declaration order
+func (d *Document) windowsTasks() ([]string, map[string][]MaintenanceWindowTask) {
+	windows := []string{}
+	tasks := map[string][]MaintenanceWindowTask{}
+	for _, task := range d.MaintenanceWindows {
+		task = task.withDefaults(d)
+		if _, ok := tasks[task.Window]; !ok {
+			windows = append(windows, task.Window)
+		}
+		tasks[task.Window] = append(tasks[task.Window], task)
+	}
+	return windows, tasks
+}
+
+// DeployMaintenanceWindows reconcile the document tasks of the configured maintenance windows and deregister
+// the ones of windows no more configured, changes are recorded into res. When not configured the registered
+// tasks are left untouched, tasks not registered by this tool are never updated or deregistered.
+func (d *Document) DeployMaintenanceWindows(ctx context.Context, res *Result) error {
+	if d.MaintenanceWindows == nil {
+		return nil
+	}
+
+	configured := map[string]bool{}
+	windows, windowsTasks := d.windowsTasks()
+	for _, window := range windows {
+		tasks := windowsTasks[window]
+
+		// Search window, create it if a task opt in
+		creation := tasks[0]
+		for _, task := range tasks {
+			if task.Create {
+				creation = task
+				break
+			}
+		}
+		windowID, created, err := d.getWindowID(ctx, creation, true)
+		if err != nil {
+			return fmt.Errorf("[%s] %w", d.Name, err)
+		}
+		if len(windowID) == 0 {
+			return fmt.Errorf("[%s] Maintenance window %s not found, set create and schedule to create it", d.Name, window)
+		}
+		configured[windowID] = true
+		if created {
+			res.WindowsCreated = append(res.WindowsCreated, window)
+		}
+
+		// Get registered tasks
+		current, err := d.getWindowTasks(ctx, windowID)
+		if err != nil {
+			return err
+		}
+
+		for _, task := range tasks {
+			key := window + "/" + task.Name
+			registered, ok := current[task.Name]
+			delete(current, task.Name)
+
+			// Register missing task
+			if !ok {
+				_, err = d.clients.ssm.RegisterTaskWithMaintenanceWindowWithContext(ctx, &ssm.RegisterTaskWithMaintenanceWindowInput{
+					WindowId:                 aws.String(windowID),
+					Name:                     aws.String(ManagedNamePrefix + task.Name),
+					TaskType:                 aws.String(task.Type),
+					TaskArn:                  aws.String(d.Name),
+					Priority:                 aws.Int64(task.Priority),
+					MaxConcurrency:           optionalString(task.MaxConcurrency),
+					MaxErrors:                optionalString(task.MaxErrors),
+					Targets:                  task.targets(),
+					ServiceRoleArn:           optionalString(task.ServiceRoleArn),
+					TaskInvocationParameters: task.invocation(),
+				})
+				if err != nil {
+					return fmt.Errorf("[%s] Cannot register maintenance window task %s: %w", d.Name, key, err)
+				}
+				res.WindowTasksRegistered = append(res.WindowTasksRegistered, key)
+				continue
+			}
+
+			// Skip unchanged task
+			if reflect.DeepEqual(task.normalized(d), windowTaskFromOutput(window, registered).normalized(d)) {
+				continue
+			}
+
+			// Update task replacing all fields
+			_, err = d.clients.ssm.UpdateMaintenanceWindowTaskWithContext(ctx, &ssm.UpdateMaintenanceWindowTaskInput{
+				WindowId:                 aws.String(windowID),
+				WindowTaskId:             registered.WindowTaskId,
+				Replace:                  aws.Bool(true),
+				Name:                     aws.String(ManagedNamePrefix + task.Name),
+				TaskArn:                  aws.String(d.Name),
+				Priority:                 aws.Int64(task.Priority),
+				MaxConcurrency:           optionalString(task.MaxConcurrency),
+				MaxErrors:                optionalString(task.MaxErrors),
+				Targets:                  task.targets(),
+				ServiceRoleArn:           optionalString(task.ServiceRoleArn),
+				TaskInvocationParameters: task.invocation(),
+			})
+			if err != nil {
+				return fmt.Errorf("[%s] Cannot update maintenance window task %s: %w", d.Name, key, err)
+			}
+			res.WindowTasksUpdated = append(res.WindowTasksUpdated, key)
+		}
+
+		// Deregister document tasks no more configured
+		for _, name := range sortedTaskNames(current) {
+			err = d.deregisterWindowTask(ctx, windowID, current[name].WindowTaskId)
+			if err != nil {
+				return fmt.Errorf("[%s] Cannot deregister maintenance window task %s/%s: %w", d.Name, window, name, err)
+			}
+			res.WindowTasksDeregistered = append(res.WindowTasksDeregistered, window+"/"+name)
+		}
+	}
+
+	// Deregister document tasks of windows no more configured
+	return d.deregisterWindowsTasks(ctx, configured, res)
+}
+
+// RemoveMaintenanceWindows deregister the tasks of the document registered by this tool in any window,
+// windows are not deleted. Changes are recorded into res.
+func (d *Document) RemoveMaintenanceWindows(ctx context.Context, res *Result) error {
+	return d.deregisterWindowsTasks(ctx, map[string]bool{}, res)
+}
+
+// deregisterWindowsTasks deregister the tasks of the document registered by this tool,
+// windows in skip are not checked. Changes are recorded into res.
+func (d *Document) deregisterWindowsTasks(ctx context.Context, skip map[string]bool, res *Result) error {
+	windows, err := d.listWindows(ctx, "")
+	if err != nil {
+		return fmt.Errorf("[%s] %w", d.Name, err)
+	}
+
+	for _, window := range windows {
+		if skip[window.id] {
+			continue
+		}
+
+		current, err := d.getWindowTasks(ctx, window.id)
+		if err != nil {
+			return err
+		}
+		for _, name := range sortedTaskNames(current) {
+			err = d.deregisterWindowTask(ctx, window.id, current[name].WindowTaskId)
+			if err != nil {
+				return fmt.Errorf("[%s] Cannot deregister maintenance window task %s/%s: %w", d.Name, window.name, name, err)
+			}
+			res.WindowTasksDeregistered = append(res.WindowTasksDeregistered, window.name+"/"+name)
+		}
+	}
+
+	return nil
+}
+
+// deregisterWindowTask remove a task from a maintenance window
+func (d *Document) deregisterWindowTask(ctx context.Context, windowID string, taskID *string) error {
+	_, err := d.clients.ssm.DeregisterTaskFromMaintenanceWindowWithContext(ctx, &ssm.DeregisterTaskFromMaintenanceWindowInput{
+		WindowId:     aws.String(windowID),
+		WindowTaskId: taskID,
+	})
+	return err
+}
+
+// sortedTaskNames return tasks names in alphabetical order
+func sortedTaskNames(tasks map[string]*ssm.GetMaintenanceWindowTaskOutput) []string {
+	names := []string{}
+	for name := range tasks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,184 @@
+package document
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/daaru00/aws-ssm-document-cli/pkg/ssmfake"
+)
+
+func deployMaintenanceWindows(t *testing.T, d *Document) (*Result, error) {
+	t.Helper()
+
+	res := deploy(t, d)
+	return res, d.DeployMaintenanceWindows(context.Background(), res)
+}
+
+func TestValidateMaintenanceWindows(t *testing.T) {
+	targets := []Target{{Key: "WindowTargetIds", Values: StringList{"abc"}}}
+	cases := map[string][]MaintenanceWindowTask{
+		"missing window":  {{Targets: targets, MaxConcurrency: "1", MaxErrors: "0"}},
+		"missing targets": {{Window: "nightly"}},
+		"missing limits":  {{Window: "nightly", Targets: targets}},
+		"target key":      {{Window: "nightly", Targets: []Target{{Key: "tag:Env", Values: StringList{"prod"}}}, MaxConcurrency: "1", MaxErrors: "0"}},
+		"wrong type":      {{Window: "nightly", Type: "AUTOMATION"}},
+		"duration":        {{Window: "nightly", Targets: targets, MaxConcurrency: "1", MaxErrors: "0", Create: true, Schedule: "rate(1 day)"}},
+		"schedule":        {{Window: "nightly", Targets: targets, MaxConcurrency: "1", MaxErrors: "0", Create: true, Duration: 2}},
+		"no create":       {{Window: "nightly", Targets: targets, MaxConcurrency: "1", MaxErrors: "0", Schedule: "rate(1 day)", Duration: 2}},
+		"duplicated":      {{Window: "nightly", Targets: targets, MaxConcurrency: "1", MaxErrors: "0"}, {Window: "nightly", Targets: targets, MaxConcurrency: "1", MaxErrors: "0"}},
+	}
+	for name, tasks := range cases {
+		d := newTestDocument(ssmfake.New(), "Test", "echo 1")
+		d.MaintenanceWindows = tasks
+		if d.ValidateMaintenanceWindows() == nil {
+			t.Errorf("%s: expected validation error", name)
+		}
+	}
+}
+
+func TestDeployMaintenanceWindows(t *testing.T) {
+	fake := ssmfake.New()
+	d := newTestDocument(fake, "Test", "echo 1")
+	d.MaintenanceWindows = []MaintenanceWindowTask{
+		{
+			Window:         "nightly",
+			Priority:       1,
+			MaxConcurrency: "10%",
+			MaxErrors:      "1",
+			Targets:        []Target{{Key: "WindowTargetIds", Values: StringList{"abc"}}},
+			Parameters:     map[string]StringList{"Message": {"hello"}},
+			Create:         true,
+			Schedule:       "cron(0 2 ? * * *)",
+			Duration:       3,
+			Cutoff:         1,
+		},
+	}
+
+	res, err := deployMaintenanceWindows(t, d)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !reflect.DeepEqual(res.WindowsCreated, []string{"nightly"}) || !reflect.DeepEqual(res.WindowTasksRegistered, []string{"nightly/Test"}) {
+		t.Errorf("unexpected result: %+v", res)
+	}
+	window := fake.MaintenanceWindow("nightly")
+	if window == nil || window.Schedule != "cron(0 2 ? * * *)" || len(window.Tasks) != 1 {
+		t.Fatalf("unexpected window: %+v", window)
+	}
+	for _, task := range window.Tasks {
+		if task.Name != ManagedNamePrefix+"Test" || task.TaskArn != "Test" || task.Type != "RUN_COMMAND" || !reflect.DeepEqual(task.Parameters["Message"], []string{"hello"}) {
+			t.Errorf("unexpected task: %+v", task)
+		}
+	}
+
+	// Unchanged tasks are not updated
+	res, err = deployMaintenanceWindows(t, d)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(res.WindowsCreated) > 0 || len(res.WindowTasksRegistered) > 0 || len(res.WindowTasksUpdated) > 0 {
+		t.Errorf("unexpected changes: %+v", res)
+	}
+
+	// Changed tasks are updated, missing ones deregistered
+	d.MaintenanceWindows[0].Priority = 2
+	d.MaintenanceWindows = append(d.MaintenanceWindows, MaintenanceWindowTask{
+		Window:         "nightly",
+		Name:           "other",
+		MaxConcurrency: "1",
+		MaxErrors:      "0",
+		Targets:        []Target{{Key: "InstanceIds", Values: StringList{"i-123"}}},
+	})
+	res, err = deployMaintenanceWindows(t, d)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !reflect.DeepEqual(res.WindowTasksUpdated, []string{"nightly/Test"}) || !reflect.DeepEqual(res.WindowTasksRegistered, []string{"nightly/other"}) {
+		t.Errorf("unexpected changes: %+v", res)
+	}
+
+	d.MaintenanceWindows = d.MaintenanceWindows[:1]
+	res, err = deployMaintenanceWindows(t, d)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !reflect.DeepEqual(res.WindowTasksDeregistered, []string{"nightly/other"}) {
+		t.Errorf("unexpected changes: %+v", res)
+	}
+
+	// Remove deregister tasks also when no more configured and keep the window
+	d = newTestDocument(fake, "Test", "echo 1")
+	res = d.NewResult()
+	err = d.Remove(context.Background(), res)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !reflect.DeepEqual(res.WindowTasksDeregistered, []string{"nightly/Test"}) || len(fake.MaintenanceWindow("nightly").Tasks) != 0 {
+		t.Errorf("unexpected remove result: %+v", res)
+	}
+}
+
+func TestDeployMaintenanceWindowsMissingWindow(t *testing.T) {
+	fake := ssmfake.New()
+	d := newTestDocument(fake, "Test", "echo 1")
+	d.MaintenanceWindows = []MaintenanceWindowTask{
+		{
+			Window:         "missing",
+			MaxConcurrency: "1",
+			MaxErrors:      "0",
+			Targets:        []Target{{Key: "WindowTargetIds", Values: StringList{"abc"}}},
+		},
+	}
+
+	_, err := deployMaintenanceWindows(t, d)
+	if err == nil || fake.MaintenanceWindow("missing") != nil {
+		t.Errorf("expected missing window error without creation, got %v", err)
+	}
+}
+
+func TestDeployMaintenanceWindowsMovedTask(t *testing.T) {
+	fake := ssmfake.New()
+	d := newTestDocument(fake, "Test", "echo 1")
+	task := MaintenanceWindowTask{
+		Window:         "nightly",
+		MaxConcurrency: "1",
+		MaxErrors:      "0",
+		Targets:        []Target{{Key: "InstanceIds", Values: StringList{"i-123"}}},
+		Create:         true,
+		Schedule:       "rate(1 day)",
+		Duration:       2,
+	}
+	d.MaintenanceWindows = []MaintenanceWindowTask{task}
+	_, err := deployMaintenanceWindows(t, d)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// Tasks registered by hand are left untouched
+	_, err = fake.RegisterTaskWithMaintenanceWindow(&ssm.RegisterTaskWithMaintenanceWindowInput{
+		WindowId: aws.String(fake.MaintenanceWindow("nightly").ID),
+		Name:     aws.String("manual"),
+		TaskType: aws.String("RUN_COMMAND"),
+		TaskArn:  aws.String("Test"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Task moved to another window is deregistered from the previous one
+	task.Window = "weekly"
+	d.MaintenanceWindows = []MaintenanceWindowTask{task}
+	res, err := deployMaintenanceWindows(t, d)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !reflect.DeepEqual(res.WindowTasksRegistered, []string{"weekly/Test"}) || !reflect.DeepEqual(res.WindowTasksDeregistered, []string{"nightly/Test"}) {
+		t.Errorf("unexpected changes: %+v", res)
+	}
+	if len(fake.MaintenanceWindow("nightly").Tasks) != 1 {
+		t.Error("task registered by hand should not be deregistered")
+	}
+}
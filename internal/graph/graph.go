@@ -0,0 +1,279 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/daaru00/aws-ssm-document-cli/internal/document"
+	"github.com/daaru00/aws-ssm-document-cli/internal/pool"
+)
+
+// Graph of dependencies between documents
+type Graph struct {
+	documents    []*document.Document
+	indexes      map[string]int
+	dependencies map[string][]string
+}
+
+// New creates a new Graph from documents
+func New(documents []*document.Document) (*Graph, error) {
+	g := &Graph{
+		documents:    documents,
+		indexes:      map[string]int{},
+		dependencies: map[string][]string{},
+	}
+
+	for index, document := range documents {
+		if previous, ok := g.indexes[document.Name]; ok {
+			return nil, fmt.Errorf("Document name %s is duplicated, found in %s and %s", document.Name, documentSource(documents[previous]), documentSource(document))
+		}
+		g.indexes[document.Name] = index
+	}
+
+	// Collect dependencies of each document
+	for _, document := range documents {
+		dependencies, err := document.GetDependencies()
+		if err != nil {
+			return nil, err
+		}
+		g.dependencies[document.Name] = dependencies
+	}
+
+	return g, nil
+}
+
+// documentSource return the configuration file of the document, if known
+func documentSource(d *document.Document) string {
+	if len(d.ConfigFile) > 0 {
+		return d.ConfigFile
+	}
+	return "unknown file"
+}
+
+// localDependencies return the dependencies that are part of the graph
+func (g *Graph) localDependencies(name string) []string {
+	dependencies := []string{}
+	for _, dependency := range g.dependencies[name] {
+		if _, ok := g.indexes[dependency]; ok {
+			dependencies = append(dependencies, dependency)
+		}
+	}
+	return dependencies
+}
+
+// Waves return documents grouped in waves, each wave depends only on documents of previous ones
+func (g *Graph) Waves() ([][]*document.Document, error) {
+	// Count dependencies of each document
+	pending := map[string]int{}
+	dependents := map[string][]string{}
+	for _, document := range g.documents {
+		dependencies := g.localDependencies(document.Name)
+		pending[document.Name] = len(dependencies)
+		for _, dependency := range dependencies {
+			dependents[dependency] = append(dependents[dependency], document.Name)
+		}
+	}
+
+	// Pick documents without pending dependencies, keeping original order
+	waves := [][]*document.Document{}
+	done := 0
+	for done < len(g.documents) {
+		wave := []*document.Document{}
+		for _, document := range g.documents {
+			if pending[document.Name] == 0 {
+				wave = append(wave, document)
+			}
+		}
+
+		if len(wave) == 0 {
+			return nil, fmt.Errorf("Dependency cycle detected: %s", strings.Join(g.findCycle(), " -> "))
+		}
+
+		for _, document := range wave {
+			pending[document.Name] = -1
+			for _, dependent := range dependents[document.Name] {
+				pending[dependent]--
+			}
+		}
+
+		waves = append(waves, wave)
+		done += len(wave)
+	}
+
+	return waves, nil
+}
+
+// findCycle return the names of documents that are part of a cycle
+func (g *Graph) findCycle() []string {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	states := map[string]int{}
+	stack := []string{}
+
+	var visit func(name string) []string
+	visit = func(name string) []string {
+		states[name] = visiting
+		stack = append(stack, name)
+
+		for _, dependency := range g.localDependencies(name) {
+			switch states[dependency] {
+			case visiting:
+				// Cycle found, return stack from dependency to current
+				for index, stackName := range stack {
+					if stackName == dependency {
+						return append(append([]string{}, stack[index:]...), dependency)
+					}
+				}
+			case unvisited:
+				if cycle := visit(dependency); cycle != nil {
+					return cycle
+				}
+			}
+		}
+
+		stack = stack[:len(stack)-1]
+		states[name] = visited
+		return nil
+	}
+
+	for _, document := range g.documents {
+		if states[document.Name] == unvisited {
+			if cycle := visit(document.Name); cycle != nil {
+				return cycle
+			}
+		}
+	}
+
+	return nil
+}
+
+// Run execute job on documents wave by wave using the pool, dependencies first.
+// When reverse is true dependents are processed first. A document is skipped
+// if a document it waits for did not complete. Outcomes are indexed as documents.
+func (g *Graph) Run(ctx context.Context, p *pool.Pool, reverse bool, job func(ctx context.Context, document *document.Document) error) ([]pool.Outcome, error) {
+	waves, err := g.Waves()
+	if err != nil {
+		return nil, err
+	}
+
+	// Reverse waves order
+	waitFor := g.localDependencies
+	if reverse {
+		for i, j := 0, len(waves)-1; i < j; i, j = i+1, j-1 {
+			waves[i], waves[j] = waves[j], waves[i]
+		}
+		waitFor = g.localDependents
+	}
+
+	outcomes := make([]pool.Outcome, len(g.documents))
+	for index := range outcomes {
+		outcomes[index].State = pool.StateNotStarted
+	}
+
+	for _, wave := range waves {
+		waveOutcomes := p.Run(ctx, len(wave), func(ctx context.Context, index int) error {
+			document := wave[index]
+
+			// Check that waited documents are completed
+			for _, name := range waitFor(document.Name) {
+				if outcomes[g.indexes[name]].State != pool.StateCompleted {
+					return fmt.Errorf("[%s] Skipped, %s did not complete", document.Name, name)
+				}
+			}
+
+			return job(ctx, document)
+		})
+
+		for index, outcome := range waveOutcomes {
+			outcomes[g.indexes[wave[index].Name]] = outcome
+		}
+	}
+
+	return outcomes, nil
+}
+
+// localDependents return the documents of the graph that depends on provided one
+func (g *Graph) localDependents(name string) []string {
+	dependents := []string{}
+	for _, document := range g.documents {
+		for _, dependency := range g.localDependencies(document.Name) {
+			if dependency == name {
+				dependents = append(dependents, document.Name)
+			}
+		}
+	}
+	return dependents
+}
+
+// Node of the exported graph
+type Node struct {
+	Name         string   `yaml:"name" json:"name"`
+	Type         string   `yaml:"type" json:"type"`
+	Dependencies []string `yaml:"dependencies" json:"dependencies"`
+	External     bool     `yaml:"external,omitempty" json:"external,omitempty"`
+}
+
+// Nodes return the graph nodes, including external documents
+func (g *Graph) Nodes() []Node {
+	nodes := []Node{}
+	external := map[string]bool{}
+	for _, document := range g.documents {
+		nodes = append(nodes, Node{
+			Name:         document.Name,
+			Type:         document.Type,
+			Dependencies: g.dependencies[document.Name],
+		})
+		for _, dependency := range g.dependencies[document.Name] {
+			if _, ok := g.indexes[dependency]; !ok {
+				external[dependency] = true
+			}
+		}
+	}
+
+	// Add external nodes sorted by name
+	externalNames := []string{}
+	for name := range external {
+		externalNames = append(externalNames, name)
+	}
+	sort.Strings(externalNames)
+	for _, name := range externalNames {
+		nodes = append(nodes, Node{
+			Name:         name,
+			Dependencies: []string{},
+			External:     true,
+		})
+	}
+
+	return nodes
+}
+
+// DOT return the graph in DOT format, external documents are drawn dashed
+func (g *Graph) DOT() string {
+	var builder strings.Builder
+	builder.WriteString("digraph documents {\n")
+	builder.WriteString("  rankdir=LR;\n")
+
+	// Write nodes
+	for _, node := range g.Nodes() {
+		if node.External {
+			builder.WriteString(fmt.Sprintf("  %q [style=dashed];\n", node.Name))
+		} else {
+			builder.WriteString(fmt.Sprintf("  %q [label=%q];\n", node.Name, node.Name+"\n"+node.Type))
+		}
+	}
+
+	// Write edges, from document to its dependency
+	for _, document := range g.documents {
+		for _, dependency := range g.dependencies[document.Name] {
+			builder.WriteString(fmt.Sprintf("  %q -> %q;\n", document.Name, dependency))
+		}
+	}
+
+	builder.WriteString("}\n")
+	return builder.String()
+}
@@ -0,0 +1,163 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/daaru00/aws-ssm-document-cli/internal/document"
+	"github.com/daaru00/aws-ssm-document-cli/internal/pool"
+)
+
+func newDocument(name string, dependsOn ...string) *document.Document {
+	return &document.Document{
+		Name:      name,
+		Type:      "Command",
+		DependsOn: dependsOn,
+		Content: document.Content{
+			SchemaVersion: "2.2",
+		},
+	}
+}
+
+func waveNames(waves [][]*document.Document) [][]string {
+	names := [][]string{}
+	for _, wave := range waves {
+		waveNames := []string{}
+		for _, document := range wave {
+			waveNames = append(waveNames, document.Name)
+		}
+		names = append(names, waveNames)
+	}
+	return names
+}
+
+func TestWaves(t *testing.T) {
+	g, err := New([]*document.Document{
+		newDocument("C", "B"),
+		newDocument("B", "A", "External"),
+		newDocument("A"),
+		newDocument("D", "A"),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	waves, err := g.Waves()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	got := strings.Join(func() []string {
+		parts := []string{}
+		for _, wave := range waveNames(waves) {
+			parts = append(parts, strings.Join(wave, ","))
+		}
+		return parts
+	}(), " | ")
+	if got != "A | B,D | C" {
+		t.Errorf("unexpected waves: %s", got)
+	}
+}
+
+func TestWavesCycle(t *testing.T) {
+	g, err := New([]*document.Document{
+		newDocument("A", "C"),
+		newDocument("B", "A"),
+		newDocument("C", "B"),
+		newDocument("D"),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	_, err = g.Waves()
+	if err == nil || !strings.Contains(err.Error(), "A -> C -> B -> A") {
+		t.Errorf("expected cycle error, got %v", err)
+	}
+}
+
+func TestRunSkipDependents(t *testing.T) {
+	documents := []*document.Document{
+		newDocument("Child", "Parent"),
+		newDocument("Parent"),
+		newDocument("Other"),
+	}
+	g, _ := New(documents)
+
+	var mu sync.Mutex
+	order := []string{}
+	outcomes, err := g.Run(context.Background(), pool.New(2, time.Second), false, func(ctx context.Context, document *document.Document) error {
+		mu.Lock()
+		order = append(order, document.Name)
+		mu.Unlock()
+		if document.Name == "Parent" {
+			return errors.New("failed")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if outcomes[0].State != pool.StateFailed || !strings.Contains(outcomes[0].Err.Error(), "Skipped") {
+		t.Errorf("child should be skipped, got %+v", outcomes[0])
+	}
+	if outcomes[1].State != pool.StateFailed || outcomes[2].State != pool.StateCompleted {
+		t.Errorf("unexpected outcomes: %+v", outcomes)
+	}
+	for _, name := range order {
+		if name == "Child" {
+			t.Error("child job should not run")
+		}
+	}
+}
+
+func TestRunReverse(t *testing.T) {
+	g, _ := New([]*document.Document{
+		newDocument("Parent"),
+		newDocument("Child", "Parent"),
+	})
+
+	order := []string{}
+	_, err := g.Run(context.Background(), pool.New(1, time.Second), true, func(ctx context.Context, document *document.Document) error {
+		order = append(order, document.Name)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if strings.Join(order, ",") != "Child,Parent" {
+		t.Errorf("unexpected order: %v", order)
+	}
+}
+
+func TestDOT(t *testing.T) {
+	g, _ := New([]*document.Document{
+		newDocument("A", "External"),
+	})
+
+	dot := g.DOT()
+
+	for _, expected := range []string{"digraph documents {", "\"External\" [style=dashed];", "\"A\" -> \"External\";"} {
+		if !strings.Contains(dot, expected) {
+			t.Errorf("expected %q in:\n%s", expected, dot)
+		}
+	}
+}
+
+func TestNewDuplicatedName(t *testing.T) {
+	first := newDocument("A")
+	first.ConfigFile = "a/document.yml"
+	second := newDocument("A")
+	second.ConfigFile = "b/document.yml"
+
+	_, err := New([]*document.Document{first, newDocument("B"), second})
+	if err == nil || !strings.Contains(err.Error(), "a/document.yml and b/document.yml") {
+		t.Errorf("expected duplicated name error, got %v", err)
+	}
+}
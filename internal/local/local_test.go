@@ -0,0 +1,248 @@
+package local
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/daaru00/aws-ssm-document-cli/internal/document"
+)
+
+func TestRender(t *testing.T) {
+	rendered, err := Render("echo {{ Message }} {{Name}}", map[string]string{"Message": "Hello", "Name": "World"})
+	if err != nil || rendered != "echo Hello World" {
+		t.Errorf("unexpected render: %q %v", rendered, err)
+	}
+
+	_, err = Render("echo {{ Missing }}", map[string]string{})
+	if err == nil || !strings.Contains(err.Error(), "Missing") {
+		t.Errorf("expected missing parameter error, got %v", err)
+	}
+
+	rendered, err = Render("echo {{ Message }} {{ssm:/app/token}}", map[string]string{"Message": "Hello"})
+	if err != nil || rendered != "echo Hello {{ssm:/app/token}}" {
+		t.Errorf("unexpected render of SSM reference: %q %v", rendered, err)
+	}
+}
+
+func TestPrepare(t *testing.T) {
+	d := &document.Document{
+		Name: "Test",
+		Content: document.Content{
+			SchemaVersion: "2.2",
+			Parameters: map[string]document.Parameter{
+				"Message": {Type: "String", Default: "Hello"},
+				"Name":    {Type: "String", Default: "World"},
+			},
+			MainSteps: []document.MainStep{
+				{
+					Action: ActionRunShellScript,
+					Name:   "run",
+					Inputs: document.ShellInput{
+						RunCommand:     []string{"echo {{ Message }} {{ Name }}"},
+						TimeoutSeconds: "10",
+					},
+				},
+			},
+		},
+	}
+
+	steps, err := Prepare(d, map[string]string{"Name": "Tester"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(steps) != 1 || steps[0].Commands[0] != "echo Hello Tester" || steps[0].TimeoutSeconds != 10 {
+		t.Errorf("unexpected steps: %+v", steps)
+	}
+
+	_, err = Prepare(d, map[string]string{"Unknown": "value"})
+	if err == nil {
+		t.Error("expected undeclared parameter error")
+	}
+}
+
+func TestRunnerExitCodes(t *testing.T) {
+	runner := NewRunner()
+
+	results, _, err := runner.Run(context.Background(), []Step{
+		{Name: "ok", TimeoutSeconds: 10, Commands: []string{"echo out", "echo err >&2"}},
+		{Name: "fail", TimeoutSeconds: 10, Commands: []string{"exit 3"}},
+		{Name: "reboot", TimeoutSeconds: 10, Commands: []string{"exit 194"}},
+		{Name: "timeout", TimeoutSeconds: 1, Commands: []string{"sleep 5"}, ExitOnFailure: true},
+		{Name: "skipped", TimeoutSeconds: 10, Commands: []string{"echo never"}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := []struct {
+		status   string
+		exitCode int
+	}{
+		{StatusSuccess, 0},
+		{StatusFailed, 3},
+		{StatusRebootRequested, 194},
+		{StatusTimedOut, -1},
+		{StatusSkipped, 0},
+	}
+	for index, result := range results {
+		if result.Status != expected[index].status || result.ExitCode != expected[index].exitCode {
+			t.Errorf("step %s: expected %s (%d), got %s (%d)", result.Step, expected[index].status, expected[index].exitCode, result.Status, result.ExitCode)
+		}
+	}
+	if results[0].Stdout != "out\n" || results[0].Stderr != "err\n" {
+		t.Errorf("unexpected output: %q %q", results[0].Stdout, results[0].Stderr)
+	}
+}
+
+func TestRunnerSandboxWorkingDirectory(t *testing.T) {
+	runner := NewRunner()
+
+	results, sandbox, err := runner.Run(context.Background(), []Step{
+		{Name: "pwd", TimeoutSeconds: 10, WorkingDirectory: "/opt/app", Commands: []string{"pwd"}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if !strings.HasPrefix(results[0].Stdout, sandbox) || !strings.HasSuffix(strings.TrimSpace(results[0].Stdout), "/opt/app") {
+		t.Errorf("step not executed in sandbox: %q", results[0].Stdout)
+	}
+}
+
+func TestRunTest(t *testing.T) {
+	d := &document.Document{
+		Name: "Test",
+		Content: document.Content{
+			SchemaVersion: "2.2",
+			Parameters: map[string]document.Parameter{
+				"Name": {Type: "String", Default: "World"},
+			},
+			MainSteps: []document.MainStep{
+				{
+					Action: ActionRunShellScript,
+					Name:   "run",
+					Inputs: document.ShellInput{
+						WorkingDirectory: "/opt/app",
+						RunCommand: []string{
+							"cat config.txt",
+							"echo Hello {{ Name }} from $APP_ENV > greeting.txt",
+							"cat greeting.txt",
+						},
+						TimeoutSeconds: "10",
+					},
+				},
+			},
+		},
+	}
+
+	testCase := document.TestCase{
+		Name:   "greeting",
+		Params: map[string]string{"Name": "Tester"},
+		Env:    map[string]string{"APP_ENV": "test"},
+		Fixtures: []document.Fixture{
+			{Target: "/opt/app/config.txt", Content: "configured\n"},
+		},
+		Expect: document.Expectation{
+			Stdout: []string{"^configured$", "^Hello Tester from test$"},
+			Files:  []string{"/opt/app/greeting.txt"},
+		},
+	}
+
+	result, err := RunTest(context.Background(), d, testCase, "sh")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !result.Passed {
+		t.Errorf("expected test to pass, failures: %v", result.Failures)
+	}
+
+	testCase.Expect = document.Expectation{
+		ExitCode: 1,
+		Stdout:   []string{"missing"},
+		Files:    []string{"/opt/app/missing.txt"},
+	}
+	result, err = RunTest(context.Background(), d, testCase, "sh")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if result.Passed || len(result.Failures) != 3 {
+		t.Errorf("expected 3 failures, got %v", result.Failures)
+	}
+}
+
+func TestRunnerSandboxEscape(t *testing.T) {
+	runner := NewRunner()
+
+	_, _, err := runner.Run(context.Background(), []Step{
+		{Name: "escape", TimeoutSeconds: 10, WorkingDirectory: "../../tmp", Commands: []string{"pwd"}},
+	})
+	if err == nil || !strings.Contains(err.Error(), "outside the sandbox") {
+		t.Errorf("expected sandbox escape error, got %v", err)
+	}
+}
+
+func TestSandboxPath(t *testing.T) {
+	sandbox := filepath.Join("tmp", "sandbox")
+
+	path, err := SandboxPath(sandbox, "/opt/app/config.txt")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if path != filepath.Join(sandbox, "root", "opt", "app", "config.txt") {
+		t.Errorf("unexpected path: %s", path)
+	}
+
+	for _, escape := range []string{"../outside.txt", "/opt/../../outside.txt", "../../etc/passwd"} {
+		_, err = SandboxPath(sandbox, escape)
+		if err == nil || !strings.Contains(err.Error(), "outside the sandbox") {
+			t.Errorf("expected sandbox escape error for %s, got %v", escape, err)
+		}
+	}
+}
+
+func TestRunTestSandboxEscape(t *testing.T) {
+	d := &document.Document{
+		Name: "Test",
+		Content: document.Content{
+			SchemaVersion: "2.2",
+			MainSteps: []document.MainStep{
+				{
+					Action: ActionRunShellScript,
+					Name:   "run",
+					Inputs: document.ShellInput{
+						RunCommand:     []string{"true"},
+						TimeoutSeconds: "10",
+					},
+				},
+			},
+		},
+	}
+
+	testCase := document.TestCase{
+		Name: "fixture",
+		Fixtures: []document.Fixture{
+			{Target: "../outside.txt", Content: "escaped\n"},
+		},
+	}
+	_, err := RunTest(context.Background(), d, testCase, "sh")
+	if err == nil || !strings.Contains(err.Error(), "outside the sandbox") {
+		t.Errorf("expected fixture escape error, got %v", err)
+	}
+
+	testCase = document.TestCase{
+		Name: "expect",
+		Expect: document.Expectation{
+			Files: []string{"../outside.txt"},
+		},
+	}
+	result, err := RunTest(context.Background(), d, testCase, "sh")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if result.Passed || len(result.Failures) != 1 || !strings.Contains(result.Failures[0], "outside the sandbox") {
+		t.Errorf("expected expect file escape failure, got %v", result.Failures)
+	}
+}
@@ -0,0 +1,59 @@
+package local
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/daaru00/aws-ssm-document-cli/internal/document"
+)
+
+// placeholderRegex match SSM parameters placeholders like {{ Name }}
+var placeholderRegex = document.PlaceholderRegex
+
+// Placeholders return the sorted unique parameter names used in text
+func Placeholders(text string) []string {
+	return document.Placeholders(text)
+}
+
+// Render replace placeholders with parameters values, unknown placeholders return an error.
+// References like {{ssm:/path}} are resolved by SSM and are left untouched.
+func Render(text string, params map[string]string) (string, error) {
+	missing := map[string]bool{}
+	rendered := placeholderRegex.ReplaceAllStringFunc(text, func(placeholder string) string {
+		name := placeholderRegex.FindStringSubmatch(placeholder)[1]
+		if strings.Contains(name, ":") {
+			return placeholder
+		}
+		value, ok := params[name]
+		if !ok {
+			missing[name] = true
+			return placeholder
+		}
+		return value
+	})
+
+	if len(missing) > 0 {
+		names := []string{}
+		for name := range missing {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return rendered, fmt.Errorf("Parameters %s have no value", strings.Join(names, ", "))
+	}
+
+	return rendered, nil
+}
+
+// ParseParams parse Name=Value pairs
+func ParseParams(pairs []string) (map[string]string, error) {
+	params := map[string]string{}
+	for _, pair := range pairs {
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 || len(parts[0]) == 0 {
+			return nil, fmt.Errorf("Parameter %s is not valid, expected Name=Value", pair)
+		}
+		params[parts[0]] = parts[1]
+	}
+	return params, nil
+}
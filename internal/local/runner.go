@@ -0,0 +1,196 @@
+package local
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	// StatusSuccess step exited with code 0
+	StatusSuccess = "Success"
+	// StatusFailed step exited with a non zero code
+	StatusFailed = "Failed"
+	// StatusTimedOut step was killed after timeout
+	StatusTimedOut = "TimedOut"
+	// StatusRebootRequested step exited with the SSM reboot exit code
+	StatusRebootRequested = "RebootRequested"
+	// StatusSkipped step was not executed because a previous step failed
+	StatusSkipped = "Skipped"
+
+	// rebootExitCode is the exit code that ask SSM agent to reboot the instance
+	rebootExitCode = 194
+	// timedOutExitCode is the exit code reported by SSM for timed out steps
+	timedOutExitCode = -1
+)
+
+// StepResult is the outcome of a step execution
+type StepResult struct {
+	Step     string  `yaml:"step" json:"step"`
+	Status   string  `yaml:"status" json:"status"`
+	ExitCode int     `yaml:"exitCode" json:"exitCode"`
+	Stdout   string  `yaml:"stdout" json:"stdout"`
+	Stderr   string  `yaml:"stderr" json:"stderr"`
+	Duration float64 `yaml:"duration" json:"duration"`
+}
+
+// Runner execute shell steps locally
+type Runner struct {
+	// Sandbox run steps in a throwaway directory, workingDirectory is created inside it
+	Sandbox bool
+	// KeepSandbox do not delete the sandbox directory at the end
+	KeepSandbox bool
+	// Dir is the sandbox directory to use instead of a new one, it is never deleted
+	Dir string
+	// Shell used to execute scripts
+	Shell string
+	// Env is added to the current environment
+	Env []string
+}
+
+// NewRunner creates a new Runner
+func NewRunner() *Runner {
+	return &Runner{
+		Sandbox: true,
+		Shell:   "sh",
+	}
+}
+
+// Run execute steps in order, a failing step with onFailure exit skip the remaining ones.
+// Return the sandbox directory, if used.
+func (r *Runner) Run(ctx context.Context, steps []Step) ([]StepResult, string, error) {
+	results := []StepResult{}
+
+	// Create sandbox directory
+	sandbox := r.Dir
+	if r.Sandbox && len(sandbox) == 0 {
+		var err error
+		sandbox, err = ioutil.TempDir("", "ssm-document-sandbox-")
+		if err != nil {
+			return nil, "", err
+		}
+		if !r.KeepSandbox {
+			defer os.RemoveAll(sandbox)
+		}
+	}
+
+	skip := false
+	for _, step := range steps {
+		if skip {
+			results = append(results, StepResult{Step: step.Name, Status: StatusSkipped})
+			continue
+		}
+
+		result, err := r.runStep(ctx, sandbox, step)
+		if err != nil {
+			return results, sandbox, err
+		}
+		results = append(results, result)
+
+		if result.Status != StatusSuccess && step.ExitOnFailure {
+			skip = true
+		}
+	}
+
+	return results, sandbox, nil
+}
+
+// runStep write the step script and execute it with timeout
+func (r *Runner) runStep(ctx context.Context, sandbox string, step Step) (StepResult, error) {
+	result := StepResult{Step: step.Name}
+
+	// Resolve working directory
+	workingDirectory := step.WorkingDirectory
+	scriptDirectory := sandbox
+	if len(sandbox) > 0 {
+		root := filepath.Join(sandbox, "root")
+		workingDirectory = filepath.Join(root, workingDirectory)
+		rel, err := filepath.Rel(root, workingDirectory)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return result, fmt.Errorf("Step %s working directory %s is outside the sandbox", step.Name, step.WorkingDirectory)
+		}
+		err = os.MkdirAll(workingDirectory, 0755)
+		if err != nil {
+			return result, err
+		}
+	} else {
+		var err error
+		scriptDirectory, err = ioutil.TempDir("", "ssm-document-script-")
+		if err != nil {
+			return result, err
+		}
+		defer os.RemoveAll(scriptDirectory)
+	}
+
+	// Write script like SSM agent does
+	scriptPath := filepath.Join(scriptDirectory, step.Name+"_script.sh")
+	err := ioutil.WriteFile(scriptPath, []byte(step.Script()), 0700)
+	if err != nil {
+		return result, err
+	}
+
+	// Execute script with timeout
+	stepCtx, cancel := context.WithTimeout(ctx, time.Duration(step.TimeoutSeconds)*time.Second)
+	defer cancel()
+
+	// Output is written to files, like SSM agent does, so background
+	// processes left by the script do not block the step end
+	stdout, err := os.Create(filepath.Join(scriptDirectory, step.Name+"_stdout"))
+	if err != nil {
+		return result, err
+	}
+	defer stdout.Close()
+	stderr, err := os.Create(filepath.Join(scriptDirectory, step.Name+"_stderr"))
+	if err != nil {
+		return result, err
+	}
+	defer stderr.Close()
+
+	cmd := exec.CommandContext(stepCtx, r.Shell, scriptPath)
+	cmd.Dir = workingDirectory
+	cmd.Env = append(os.Environ(), r.Env...)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	start := time.Now()
+	err = cmd.Run()
+	result.Duration = time.Since(start).Seconds()
+
+	// Read step output
+	stdoutContent, readErr := ioutil.ReadFile(stdout.Name())
+	if readErr != nil {
+		return result, readErr
+	}
+	result.Stdout = string(stdoutContent)
+	stderrContent, readErr := ioutil.ReadFile(stderr.Name())
+	if readErr != nil {
+		return result, readErr
+	}
+	result.Stderr = string(stderrContent)
+
+	// Map exit status like SSM would
+	var exitErr *exec.ExitError
+	switch {
+	case stepCtx.Err() == context.DeadlineExceeded:
+		result.Status = StatusTimedOut
+		result.ExitCode = timedOutExitCode
+	case err == nil:
+		result.Status = StatusSuccess
+	case errors.As(err, &exitErr):
+		result.ExitCode = exitErr.ExitCode()
+		result.Status = StatusFailed
+		if result.ExitCode == rebootExitCode {
+			result.Status = StatusRebootRequested
+		}
+	default:
+		return result, err
+	}
+
+	return result, nil
+}
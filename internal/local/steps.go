@@ -0,0 +1,152 @@
+package local
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/daaru00/aws-ssm-document-cli/internal/document"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	// ActionRunShellScript is the only action that can be executed locally
+	ActionRunShellScript = document.ActionRunShellScript
+
+	// DefaultTimeoutSeconds is the SSM default step timeout
+	DefaultTimeoutSeconds = 3600
+)
+
+// Step is a shell step ready to be executed
+type Step struct {
+	Name             string
+	WorkingDirectory string
+	TimeoutSeconds   int
+	Commands         []string
+	ExitOnFailure    bool
+}
+
+// Script return the step commands as a script
+func (s *Step) Script() string {
+	return strings.Join(s.Commands, "\n") + "\n"
+}
+
+// localContent is the content structure needed to run steps locally
+type localContent struct {
+	Parameters map[string]struct {
+		Type    string      `yaml:"type"`
+		Default interface{} `yaml:"default"`
+	} `yaml:"parameters"`
+	MainSteps []document.MainStep `yaml:"mainSteps"`
+}
+
+// Prepare return the document shell steps with parameters rendered,
+// declared defaults are overridden by params
+func Prepare(d *document.Document, params map[string]string) ([]Step, error) {
+	format, content, err := d.GetContent()
+	if err != nil {
+		return nil, err
+	}
+	if *format == "TEXT" {
+		return nil, fmt.Errorf("[%s] Document with TEXT format cannot be executed locally", d.Name)
+	}
+
+	parsed := localContent{}
+	err = yaml.Unmarshal([]byte(*content), &parsed)
+	if err != nil {
+		return nil, fmt.Errorf("[%s] Cannot parse content: %s", d.Name, err)
+	}
+
+	// Merge defaults and provided values
+	values := map[string]string{}
+	for name, parameter := range parsed.Parameters {
+		// Parameter Store references are resolved by SSM, a value must be provided
+		if parameter.Default != nil && !document.IsSSMReference(parameter.Default) {
+			values[name] = formatValue(parameter.Default)
+		}
+	}
+	for name, value := range params {
+		if _, ok := parsed.Parameters[name]; !ok {
+			return nil, fmt.Errorf("[%s] Parameter %s is not declared", d.Name, name)
+		}
+		values[name] = value
+	}
+
+	// Build shell steps
+	steps := []Step{}
+	for _, mainStep := range parsed.MainSteps {
+		if mainStep.Action != ActionRunShellScript {
+			return nil, fmt.Errorf("[%s] Step %s use action %s, only %s can be executed locally", d.Name, mainStep.Name, mainStep.Action, ActionRunShellScript)
+		}
+
+		step := Step{
+			Name:           mainStep.Name,
+			TimeoutSeconds: DefaultTimeoutSeconds,
+			ExitOnFailure:  mainStep.OnFailure == "exit",
+		}
+
+		// Decode inputs
+		decoded, err := mainStep.DecodeInputs()
+		if err != nil {
+			return nil, fmt.Errorf("[%s] Step %s: %s", d.Name, step.Name, err)
+		}
+		inputs := decoded.(*document.ShellInput)
+
+		// Render inputs
+		step.WorkingDirectory, err = Render(inputs.WorkingDirectory, values)
+		if err != nil {
+			return nil, fmt.Errorf("[%s] Step %s: %s", d.Name, step.Name, err)
+		}
+		renderedTimeout, err := Render(inputs.TimeoutSeconds, values)
+		if err != nil {
+			return nil, fmt.Errorf("[%s] Step %s: %s", d.Name, step.Name, err)
+		}
+		if len(renderedTimeout) > 0 {
+			step.TimeoutSeconds, err = strconv.Atoi(renderedTimeout)
+			if err != nil {
+				return nil, fmt.Errorf("[%s] Step %s has an invalid timeoutSeconds %s", d.Name, step.Name, renderedTimeout)
+			}
+		}
+
+		// Render commands
+		commands := inputs.RunCommand
+		for _, command := range commands {
+			rendered, err := Render(command, values)
+			if err != nil {
+				return nil, fmt.Errorf("[%s] Step %s: %s", d.Name, step.Name, err)
+			}
+			step.Commands = append(step.Commands, rendered)
+		}
+
+		steps = append(steps, step)
+	}
+
+	if len(steps) == 0 {
+		return nil, fmt.Errorf("[%s] Document has no steps", d.Name)
+	}
+
+	return steps, nil
+}
+
+// formatValue convert a parameter value into its string representation
+func formatValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case []interface{}:
+		parts := []string{}
+		for _, item := range v {
+			parts = append(parts, formatValue(item))
+		}
+		return strings.Join(parts, ",")
+	case map[interface{}]interface{}, map[string]interface{}:
+		encoded, err := json.Marshal(document.NormalizeValue(v))
+		if err != nil {
+			return fmt.Sprintf("%v", v)
+		}
+		return string(encoded)
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
@@ -0,0 +1,180 @@
+package local
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/daaru00/aws-ssm-document-cli/internal/document"
+)
+
+// TestResult is the outcome of a document test case
+type TestResult struct {
+	Document string       `yaml:"document" json:"document"`
+	Name     string       `yaml:"name" json:"name"`
+	Passed   bool         `yaml:"passed" json:"passed"`
+	ExitCode int          `yaml:"exitCode" json:"exitCode"`
+	Failures []string     `yaml:"failures,omitempty" json:"failures,omitempty"`
+	Steps    []StepResult `yaml:"steps" json:"steps"`
+	Duration float64      `yaml:"duration" json:"duration"`
+}
+
+// SandboxPath return the path inside the sandbox root, paths escaping the root are refused
+func SandboxPath(sandbox string, path string) (string, error) {
+	root := filepath.Join(sandbox, "root")
+	target := filepath.Join(root, filepath.FromSlash(strings.TrimPrefix(path, "/")))
+	rel, err := filepath.Rel(root, target)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("Path %s is outside the sandbox", path)
+	}
+	return target, nil
+}
+
+// RunTest execute a document test case in a new sandbox and check expectations
+func RunTest(ctx context.Context, d *document.Document, testCase document.TestCase, shell string) (*TestResult, error) {
+	result := &TestResult{
+		Document: d.Name,
+		Name:     testCase.Name,
+		Steps:    []StepResult{},
+	}
+
+	// Prepare steps
+	steps, err := Prepare(d, testCase.Params)
+	if err != nil {
+		return result, err
+	}
+
+	// Create sandbox with fixtures
+	sandbox, err := ioutil.TempDir("", "ssm-document-test-")
+	if err != nil {
+		return result, err
+	}
+	defer os.RemoveAll(sandbox)
+	for _, fixture := range testCase.Fixtures {
+		err = writeFixture(sandbox, fixture)
+		if err != nil {
+			return result, fmt.Errorf("[%s] Test %s: %s", d.Name, testCase.Name, err)
+		}
+	}
+
+	// Setup runner
+	runner := NewRunner()
+	runner.Dir = sandbox
+	runner.Shell = shell
+	envKeys := []string{}
+	for key := range testCase.Env {
+		envKeys = append(envKeys, key)
+	}
+	sort.Strings(envKeys)
+	for _, key := range envKeys {
+		runner.Env = append(runner.Env, key+"="+testCase.Env[key])
+	}
+
+	// Execute steps
+	start := time.Now()
+	result.Steps, _, err = runner.Run(ctx, steps)
+	result.Duration = time.Since(start).Seconds()
+	if err != nil {
+		return result, err
+	}
+
+	// Check expectations
+	result.ExitCode = ExitCode(result.Steps)
+	result.Failures = checkExpectation(sandbox, testCase.Expect, result)
+	result.Passed = len(result.Failures) == 0
+
+	return result, nil
+}
+
+// ExitCode return the exit code of the first step not succeeded, 0 if all succeeded
+func ExitCode(steps []StepResult) int {
+	for _, step := range steps {
+		if step.Status != StatusSuccess && step.Status != StatusSkipped {
+			return step.ExitCode
+		}
+	}
+	return 0
+}
+
+// writeFixture create fixture file inside the sandbox
+func writeFixture(sandbox string, fixture document.Fixture) error {
+	if len(fixture.Target) == 0 {
+		return fmt.Errorf("Fixture target is required")
+	}
+
+	content := []byte(fixture.Content)
+	if len(fixture.Source) > 0 {
+		var err error
+		content, err = ioutil.ReadFile(fixture.Source)
+		if err != nil {
+			return err
+		}
+	}
+
+	target, err := SandboxPath(sandbox, fixture.Target)
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(filepath.Dir(target), 0755)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(target, content, 0644)
+}
+
+// checkExpectation return the list of unmet expectations
+func checkExpectation(sandbox string, expect document.Expectation, result *TestResult) []string {
+	failures := []string{}
+
+	// Check exit code
+	if result.ExitCode != expect.ExitCode {
+		failures = append(failures, fmt.Sprintf("expected exit code %d, got %d", expect.ExitCode, result.ExitCode))
+	}
+
+	// Check output patterns
+	var stdout, stderr strings.Builder
+	for _, step := range result.Steps {
+		stdout.WriteString(step.Stdout)
+		stderr.WriteString(step.Stderr)
+	}
+	failures = append(failures, checkPatterns("stdout", expect.Stdout, stdout.String())...)
+	failures = append(failures, checkPatterns("stderr", expect.Stderr, stderr.String())...)
+
+	// Check created files
+	for _, file := range expect.Files {
+		path, err := SandboxPath(sandbox, file)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("expected file %s: %s", file, err))
+			continue
+		}
+		_, err = os.Stat(path)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("expected file %s to exist", file))
+		}
+	}
+
+	return failures
+}
+
+// checkPatterns return a failure for each regular expression that does not match content,
+// patterns are multi-line so ^ and $ match at line boundaries
+func checkPatterns(name string, patterns []string, content string) []string {
+	failures := []string{}
+	for _, pattern := range patterns {
+		regex, err := regexp.Compile("(?m)" + pattern)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("invalid %s pattern %q: %s", name, pattern, err))
+			continue
+		}
+		if !regex.MatchString(content) {
+			failures = append(failures, fmt.Sprintf("expected %s to match %q", name, pattern))
+		}
+	}
+	return failures
+}
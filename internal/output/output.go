@@ -0,0 +1,126 @@
+package output
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/AlecAivazis/survey/v2"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/urfave/cli/v2"
+	"golang.org/x/term"
+	"gopkg.in/yaml.v2"
+)
+
+// ErrNonInteractive is returned asking input when no terminal is attached or input is disabled
+var ErrNonInteractive = errors.New("no terminal attached or input disabled")
+
+// Printer write progress messages and command results
+type Printer struct {
+	format      string
+	progress    io.Writer
+	out         io.Writer
+	interactive bool
+}
+
+// NewPrinter creates a new Printer using the output flag,
+// progress messages are written to stderr when a structured output is selected
+func NewPrinter(c *cli.Context) (*Printer, error) {
+	format := c.String("output")
+	if len(format) == 0 {
+		format = "text"
+	}
+
+	printer := &Printer{
+		format:   format,
+		progress: os.Stdout,
+		out:      os.Stdout,
+	}
+
+	// Check output format
+	switch format {
+	case "text":
+		break
+	case "json", "yaml", "yml":
+		printer.progress = os.Stderr
+	default:
+		return nil, fmt.Errorf("Output %s not supported, valid values are \"text\", \"json\" or \"yaml\"", format)
+	}
+
+	// Check if prompts can be answered
+	printer.interactive = !c.Bool("no-input") && !isCI() && isTerminal(os.Stdin) && isTerminal(printer.progress)
+
+	return printer, nil
+}
+
+// isCI check the CI environment variable set by most CI services
+func isCI() bool {
+	ci := os.Getenv("CI")
+	return strings.EqualFold(ci, "true") || ci == "1"
+}
+
+// isTerminal check if the writer or reader is a terminal
+func isTerminal(stream interface{}) bool {
+	file, ok := stream.(*os.File)
+	return ok && term.IsTerminal(int(file.Fd()))
+}
+
+// IsStructured return true if results are printed in a machine readable format
+func (p *Printer) IsStructured() bool {
+	return p.format != "text"
+}
+
+// Progress return the writer for progress messages
+func (p *Printer) Progress() io.Writer {
+	return p.progress
+}
+
+// Progressf print a progress message line
+func (p *Printer) Progressf(format string, a ...interface{}) {
+	fmt.Fprintf(p.progress, format+"\n", a...)
+}
+
+// IsInteractive return true if prompts can be answered by the user
+func (p *Printer) IsInteractive() bool {
+	return p.interactive
+}
+
+// Ask run the prompt, fails with ErrNonInteractive without prompting when the session is not interactive
+func (p *Printer) Ask(prompt survey.Prompt, response interface{}) error {
+	if !p.interactive {
+		return ErrNonInteractive
+	}
+	return survey.AskOne(prompt, response, p.AskOptions()...)
+}
+
+// AskOptions return survey options that keep prompts out of structured output
+func (p *Printer) AskOptions() []survey.AskOpt {
+	if !p.IsStructured() {
+		return []survey.AskOpt{}
+	}
+	return []survey.AskOpt{survey.WithStdio(os.Stdin, os.Stderr, os.Stderr)}
+}
+
+// Print write results in the selected structured format, nothing is printed for text output
+func (p *Printer) Print(results interface{}) error {
+	var content []byte
+	var err error
+
+	switch p.format {
+	case "json":
+		content, err = jsoniter.MarshalIndent(results, "", "  ")
+		content = append(content, '\n')
+	case "yaml", "yml":
+		content, err = yaml.Marshal(results)
+	default:
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = p.out.Write(content)
+	return err
+}
@@ -0,0 +1,148 @@
+package output
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/daaru00/aws-ssm-document-cli/internal/document"
+	"github.com/daaru00/aws-ssm-document-cli/internal/pool"
+)
+
+func newMixedReport() *Report {
+	report := NewReport("deploy", aws.String("123456789012"), aws.String("us-east-1"), []*document.Document{
+		{Name: "Created"},
+		{Name: "Failed"},
+		{Name: "Pending"},
+	})
+	report.Results[0].Region = "us-east-1"
+	report.Results[0].Action = document.ActionCreated
+	report.Results[0].Version = "1"
+	report.Results[0].TagsAdded = []string{"Team"}
+	report.Results[0].Duration = 1.5
+	report.Results[1].Region = "us-east-1"
+	report.Results[2].Region = "us-east-1"
+
+	report.Collect([]pool.Outcome{
+		{State: pool.StateCompleted},
+		{State: pool.StateFailed, Err: awserr.New("ValidationException", "invalid content", nil)},
+		{State: pool.StateNotStarted},
+	})
+	return report
+}
+
+func TestPrint(t *testing.T) {
+	cases := []struct {
+		format   string
+		expected string
+	}{
+		{"json", `{
+  "command": "deploy",
+  "accountId": "123456789012",
+  "region": "us-east-1",
+  "results": [
+    {
+      "name": "Created",
+      "region": "us-east-1",
+      "action": "created",
+      "version": "1",
+      "tagsAdded": [
+        "Team"
+      ],
+      "duration": 1.5
+    },
+    {
+      "name": "Failed",
+      "region": "us-east-1",
+      "action": "skipped",
+      "error": "ValidationException: invalid content",
+      "errorCode": "ValidationException",
+      "duration": 0
+    },
+    {
+      "name": "Pending",
+      "region": "us-east-1",
+      "action": "not-started",
+      "duration": 0
+    }
+  ]
+}
+`},
+		{"yaml", `command: deploy
+accountId: "123456789012"
+region: us-east-1
+results:
+- name: Created
+  region: us-east-1
+  action: created
+  version: "1"
+  tagsAdded:
+  - Team
+  duration: 1.5
+- name: Failed
+  region: us-east-1
+  action: skipped
+  error: 'ValidationException: invalid content'
+  errorCode: ValidationException
+  duration: 0
+- name: Pending
+  region: us-east-1
+  action: not-started
+  duration: 0
+`},
+		{"text", ""},
+	}
+
+	for _, tc := range cases {
+		out := &bytes.Buffer{}
+		printer := &Printer{format: tc.format, progress: &bytes.Buffer{}, out: out}
+
+		err := printer.Print(newMixedReport())
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %s", tc.format, err)
+		}
+		if out.String() != tc.expected {
+			t.Errorf("%s: unexpected output:\n%s", tc.format, out.String())
+		}
+	}
+}
+
+func TestCollect(t *testing.T) {
+	report := newMixedReport()
+
+	cases := []struct {
+		action    string
+		err       string
+		errorCode string
+	}{
+		{document.ActionCreated, "", ""},
+		{document.ActionSkipped, "ValidationException: invalid content", "ValidationException"},
+		{document.ActionNotStarted, "", ""},
+	}
+	for index, tc := range cases {
+		result := report.Results[index]
+		if result.Action != tc.action || result.Error != tc.err || result.ErrorCode != tc.errorCode {
+			t.Errorf("result %d: unexpected %+v", index, result)
+		}
+	}
+	if report.Failed() != 1 {
+		t.Errorf("expected 1 failed result, got %d", report.Failed())
+	}
+}
+
+func TestCollectPlainError(t *testing.T) {
+	report := NewReport("remove", nil, nil, []*document.Document{{Name: "Test"}})
+
+	report.Collect([]pool.Outcome{
+		{State: pool.StateFailed, Err: errors.New("plain error")},
+	})
+
+	if report.Results[0].Error != "plain error" || len(report.Results[0].ErrorCode) != 0 {
+		t.Errorf("unexpected result: %+v", report.Results[0])
+	}
+	if len(report.AccountID) != 0 || len(report.Region) != 0 {
+		t.Errorf("unexpected caller infos: %+v", report)
+	}
+}
@@ -0,0 +1,67 @@
+package output
+
+import (
+	"errors"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/daaru00/aws-ssm-document-cli/internal/document"
+	"github.com/daaru00/aws-ssm-document-cli/internal/pool"
+)
+
+// Report is the structured output of a command
+type Report struct {
+	Command   string             `yaml:"command" json:"command"`
+	AccountID string             `yaml:"accountId,omitempty" json:"accountId,omitempty"`
+	Region    string             `yaml:"region,omitempty" json:"region,omitempty"`
+	Results   []*document.Result `yaml:"results" json:"results"`
+}
+
+// NewReport creates a new Report with a result for each document
+func NewReport(command string, accountID *string, region *string, documents []*document.Document) *Report {
+	report := &Report{
+		Command: command,
+		Results: []*document.Result{},
+	}
+	if accountID != nil {
+		report.AccountID = *accountID
+	}
+	if region != nil {
+		report.Region = *region
+	}
+
+	for _, document := range documents {
+		report.Results = append(report.Results, document.NewResult())
+	}
+
+	return report
+}
+
+// Collect copy jobs outcomes into results, outcomes must be indexed as results
+func (r *Report) Collect(outcomes []pool.Outcome) {
+	for index, outcome := range outcomes {
+		result := r.Results[index]
+		if outcome.Err != nil {
+			result.Error = outcome.Err.Error()
+
+			// Extract AWS error code
+			var awsErr awserr.Error
+			if errors.As(outcome.Err, &awsErr) {
+				result.ErrorCode = awsErr.Code()
+			}
+		}
+		if outcome.State == pool.StateNotStarted {
+			result.Action = document.ActionNotStarted
+		}
+	}
+}
+
+// Failed return the number of results in error
+func (r *Report) Failed() int {
+	count := 0
+	for _, result := range r.Results {
+		if len(result.Error) > 0 {
+			count++
+		}
+	}
+	return count
+}
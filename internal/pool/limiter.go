@@ -0,0 +1,112 @@
+package pool
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultRate is the default number of API requests per second,
+	// tuned on SSM document APIs default throughput quotas
+	DefaultRate = 5.0
+
+	// minRate is the lowest rate reached reducing it after throttling
+	minRate = 0.5
+
+	// recoveryStep is the rate increment applied after each successful request
+	recoveryStep = 0.1
+)
+
+// Limiter is a token bucket rate limiter that adapt its rate on throttling
+type Limiter struct {
+	mu      sync.Mutex
+	rate    float64
+	maxRate float64
+	burst   float64
+	tokens  float64
+	last    time.Time
+}
+
+// NewLimiter creates a new Limiter
+func NewLimiter(rate float64) *Limiter {
+	if rate <= 0 {
+		rate = DefaultRate
+	}
+
+	// Allow short bursts up to the configured rate
+	burst := math.Max(1, math.Floor(rate))
+
+	return &Limiter{
+		rate:    rate,
+		maxRate: rate,
+		burst:   burst,
+		tokens:  burst,
+		last:    time.Now(),
+	}
+}
+
+// refill add tokens accumulated since last call, must be called with lock held
+func (l *Limiter) refill(now time.Time) {
+	elapsed := now.Sub(l.last).Seconds()
+	l.last = now
+	l.tokens = math.Min(l.burst, l.tokens+elapsed*l.rate)
+}
+
+// Wait block until a token is available or the context is done
+func (l *Limiter) Wait(ctx context.Context) error {
+	l.mu.Lock()
+	l.refill(time.Now())
+
+	// Reserve a token, tokens can go negative to queue waiters
+	l.tokens--
+	if l.tokens >= 0 {
+		l.mu.Unlock()
+		return nil
+	}
+	delay := time.Duration(-l.tokens / l.rate * float64(time.Second))
+	l.mu.Unlock()
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		// Give back reserved token
+		l.mu.Lock()
+		l.tokens++
+		l.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+// Throttled halve the current rate
+func (l *Limiter) Throttled() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.refill(time.Now())
+	l.rate = math.Max(minRate, l.rate/2)
+}
+
+// Succeeded slowly increase the rate back to the configured one
+func (l *Limiter) Succeeded() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.rate < l.maxRate {
+		l.refill(time.Now())
+		l.rate = math.Min(l.maxRate, l.rate+recoveryStep)
+	}
+}
+
+// Rate return the current rate
+func (l *Limiter) Rate() float64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.rate
+}
@@ -0,0 +1,131 @@
+package pool
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// DefaultGracePeriod is the default time given to running jobs to complete after cancellation
+const DefaultGracePeriod = 30 * time.Second
+
+// State of a job
+type State string
+
+const (
+	// StateNotStarted job never started because of cancellation
+	StateNotStarted State = "not-started"
+	// StateCompleted job completed without errors
+	StateCompleted State = "completed"
+	// StateFailed job returned an error
+	StateFailed State = "failed"
+)
+
+// Outcome of a job
+type Outcome struct {
+	State State
+	Err   error
+}
+
+// Pool execute jobs using a fixed number of workers
+type Pool struct {
+	workers     int
+	gracePeriod time.Duration
+}
+
+// New creates a new Pool
+func New(workers int, gracePeriod time.Duration) *Pool {
+	if workers < 1 {
+		workers = 1
+	}
+
+	return &Pool{
+		workers:     workers,
+		gracePeriod: gracePeriod,
+	}
+}
+
+// Run execute job for each index between 0 and size, return outcomes indexed as jobs.
+// When ctx is done no new jobs are started, running jobs receive a context
+// that is cancelled once the grace period expires.
+func (p *Pool) Run(ctx context.Context, size int, job func(ctx context.Context, index int) error) []Outcome {
+	outcomes := make([]Outcome, size)
+	for index := range outcomes {
+		outcomes[index].State = StateNotStarted
+	}
+
+	// Running jobs context outlive ctx until grace period expires
+	jobsCtx, cancelJobs := context.WithCancel(context.Background())
+	defer cancelJobs()
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			timer := time.NewTimer(p.gracePeriod)
+			defer timer.Stop()
+			select {
+			case <-timer.C:
+				cancelJobs()
+			case <-done:
+			}
+		case <-done:
+		}
+	}()
+
+	// Do not start more workers than jobs
+	workers := p.workers
+	if workers > size {
+		workers = size
+	}
+
+	// Start workers
+	jobs := make(chan int)
+	var waitGroup sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		waitGroup.Add(1)
+		go func() {
+			defer waitGroup.Done()
+			for index := range jobs {
+				// Skip job dispatched while cancelling
+				if ctx.Err() != nil {
+					continue
+				}
+
+				err := job(jobsCtx, index)
+				if err != nil {
+					outcomes[index] = Outcome{State: StateFailed, Err: err}
+				} else {
+					outcomes[index] = Outcome{State: StateCompleted}
+				}
+			}
+		}()
+	}
+
+	// Dispatch jobs, a job start as soon as a worker is free
+dispatch:
+	for index := 0; index < size; index++ {
+		select {
+		case jobs <- index:
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(jobs)
+
+	// Wait until all started jobs ends
+	waitGroup.Wait()
+
+	return outcomes
+}
+
+// Count return the number of outcomes in provided state
+func Count(outcomes []Outcome, state State) int {
+	count := 0
+	for _, outcome := range outcomes {
+		if outcome.State == state {
+			count++
+		}
+	}
+	return count
+}
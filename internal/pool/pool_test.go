@@ -0,0 +1,120 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+)
+
+func TestRun(t *testing.T) {
+	var running, maxRunning int32
+
+	outcomes := New(3, time.Second).Run(context.Background(), 10, func(ctx context.Context, index int) error {
+		current := atomic.AddInt32(&running, 1)
+		for {
+			max := atomic.LoadInt32(&maxRunning)
+			if current <= max || atomic.CompareAndSwapInt32(&maxRunning, max, current) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		atomic.AddInt32(&running, -1)
+
+		if index == 4 {
+			return errors.New("failed")
+		}
+		return nil
+	})
+
+	if maxRunning > 3 {
+		t.Errorf("expected at most 3 parallel jobs, got %d", maxRunning)
+	}
+	if Count(outcomes, StateCompleted) != 9 || Count(outcomes, StateFailed) != 1 {
+		t.Errorf("unexpected outcomes: %+v", outcomes)
+	}
+	if outcomes[4].State != StateFailed {
+		t.Errorf("unexpected outcome: %+v", outcomes[4])
+	}
+}
+
+func TestRunCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	outcomes := New(1, 50*time.Millisecond).Run(ctx, 3, func(jobCtx context.Context, index int) error {
+		cancel()
+		select {
+		case <-jobCtx.Done():
+			return jobCtx.Err()
+		case <-time.After(5 * time.Second):
+			return nil
+		}
+	})
+
+	if outcomes[0].State != StateFailed || !errors.Is(outcomes[0].Err, context.Canceled) {
+		t.Errorf("running job should be aborted after grace period, got %+v", outcomes[0])
+	}
+	if Count(outcomes, StateNotStarted) != 2 {
+		t.Errorf("expected 2 jobs not started, got %+v", outcomes)
+	}
+}
+
+func TestLimiter(t *testing.T) {
+	limiter := NewLimiter(20)
+
+	start := time.Now()
+	for i := 0; i < 30; i++ {
+		err := limiter.Wait(context.Background())
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+
+	// 20 tokens of burst, 10 more at 20 per second
+	if elapsed := time.Since(start); elapsed < 400*time.Millisecond {
+		t.Errorf("limiter too fast: %s", elapsed)
+	}
+}
+
+func TestLimiterAdaptive(t *testing.T) {
+	limiter := NewLimiter(4)
+
+	limiter.Throttled()
+	limiter.Throttled()
+	if limiter.Rate() != 1 {
+		t.Errorf("expected rate 1, got %f", limiter.Rate())
+	}
+
+	for i := 0; i < 100; i++ {
+		limiter.Succeeded()
+	}
+	if limiter.Rate() != 4 {
+		t.Errorf("expected rate restored to 4, got %f", limiter.Rate())
+	}
+}
+
+func TestRetryRulesCapped(t *testing.T) {
+	retryer := NewRetryer(NewLimiter(1), DefaultMaxRetries)
+
+	cases := []struct {
+		err error
+		max time.Duration
+	}{
+		{awserr.New("InternalError", "internal error", nil), maxRetryDelay},
+		{awserr.New("ThrottlingException", "rate exceeded", nil), maxThrottleDelay},
+	}
+	for _, tc := range cases {
+		for retry := 0; retry < 20; retry++ {
+			for i := 0; i < 50; i++ {
+				req := &request.Request{Error: tc.err, RetryCount: retry}
+				if delay := retryer.RetryRules(req); delay > tc.max {
+					t.Fatalf("retry %d: delay %s over max %s", retry, delay, tc.max)
+				}
+			}
+		}
+	}
+}
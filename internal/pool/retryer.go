@@ -0,0 +1,93 @@
+package pool
+
+import (
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/client"
+	"github.com/aws/aws-sdk-go/aws/request"
+)
+
+const (
+	// DefaultMaxRetries is the default number of retries for a single API request
+	DefaultMaxRetries = 8
+
+	baseRetryDelay    = 100 * time.Millisecond
+	baseThrottleDelay = 500 * time.Millisecond
+	maxRetryDelay     = 20 * time.Second
+	maxThrottleDelay  = 60 * time.Second
+)
+
+// Retryer retry failed requests with jittered exponential backoff and
+// reduce the limiter rate when requests are throttled
+type Retryer struct {
+	client.DefaultRetryer
+	limiter *Limiter
+}
+
+// NewRetryer creates a new Retryer
+func NewRetryer(limiter *Limiter, maxRetries int) *Retryer {
+	if maxRetries < 0 {
+		maxRetries = DefaultMaxRetries
+	}
+
+	return &Retryer{
+		DefaultRetryer: client.DefaultRetryer{
+			NumMaxRetries: maxRetries,
+		},
+		limiter: limiter,
+	}
+}
+
+// ShouldRetry return if the request should be retried
+func (r *Retryer) ShouldRetry(req *request.Request) bool {
+	if request.IsErrorThrottle(req.Error) {
+		r.limiter.Throttled()
+		return true
+	}
+
+	return r.DefaultRetryer.ShouldRetry(req)
+}
+
+// RetryRules return the delay before retrying the request using full jitter
+func (r *Retryer) RetryRules(req *request.Request) time.Duration {
+	base, max := baseRetryDelay, maxRetryDelay
+	if request.IsErrorThrottle(req.Error) {
+		base, max = baseThrottleDelay, maxThrottleDelay
+	}
+
+	// Calculate exponential ceiling
+	ceil := float64(base) * math.Pow(2, float64(req.RetryCount))
+	if ceil > float64(max) {
+		ceil = float64(max)
+	}
+
+	// Pick a random delay between base and ceiling, capped after adding jitter
+	delay := base + time.Duration(rand.Int63n(int64(ceil)))
+	if delay > max {
+		delay = max
+	}
+	return delay
+}
+
+// Attach wire limiter to the request handlers, each request attempt wait for a token before being signed
+func Attach(handlers *request.Handlers, limiter *Limiter) {
+	handlers.Sign.PushFrontNamed(request.NamedHandler{
+		Name: "pool.Limiter.Wait",
+		Fn: func(req *request.Request) {
+			err := limiter.Wait(req.Context())
+			if err != nil {
+				req.Error = err
+			}
+		},
+	})
+	handlers.Complete.PushBackNamed(request.NamedHandler{
+		Name: "pool.Limiter.Succeeded",
+		Fn: func(req *request.Request) {
+			if req.Error == nil {
+				limiter.Succeeded()
+			}
+		},
+	})
+}
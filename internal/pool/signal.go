@@ -0,0 +1,63 @@
+package pool
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// NotifyContext return a context cancelled on SIGINT or SIGTERM, a message is
+// printed when the signal is received. A second signal terminate the process.
+func NotifyContext(w io.Writer) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	stopped := make(chan struct{})
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		select {
+		case <-signals:
+			fmt.Fprintln(w, "Interrupt received, waiting for running operations to complete (press again to force exit)..")
+			cancel()
+		case <-stopped:
+			return
+		}
+
+		// Force exit on second signal
+		select {
+		case <-signals:
+			os.Exit(130)
+		case <-stopped:
+		}
+	}()
+
+	return ctx, func() {
+		signal.Stop(signals)
+		close(stopped)
+		cancel()
+	}
+}
+
+// PrintSummary print which operations completed, failed or never started
+func PrintSummary(w io.Writer, names []string, outcomes []Outcome) {
+	for _, state := range []State{StateCompleted, StateFailed, StateNotStarted} {
+		if Count(outcomes, state) == 0 {
+			continue
+		}
+
+		fmt.Fprintf(w, "%s:\n", state)
+		for index, outcome := range outcomes {
+			if outcome.State != state {
+				continue
+			}
+			if outcome.Err != nil {
+				fmt.Fprintf(w, "  - %s: %s\n", names[index], outcome.Err)
+			} else {
+				fmt.Fprintf(w, "  - %s\n", names[index])
+			}
+		}
+	}
+}
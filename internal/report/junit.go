@@ -0,0 +1,143 @@
+package report
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/daaru00/aws-ssm-document-cli/internal/document"
+	"github.com/daaru00/aws-ssm-document-cli/internal/output"
+)
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	Time      string          `xml:"time,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+	seconds   float64
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *junitSkipped `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Content string `xml:",chardata"`
+}
+
+type junitSkipped struct {
+	Message string `xml:"message,attr"`
+}
+
+// JUnitWriter write a JUnit XML report, each document is a test case
+type JUnitWriter struct{}
+
+// Write report in JUnit XML format
+func (j *JUnitWriter) Write(w io.Writer, report *output.Report) error {
+	suite := junitTestSuite{
+		Name:      fmt.Sprintf("aws-ssm-document %s", report.Command),
+		TestCases: []junitTestCase{},
+	}
+
+	var total float64
+	for _, result := range report.Results {
+		testCase := junitTestCase{
+			Name:      result.Name,
+			ClassName: className(report.Command, result.Region),
+			Time:      formatSeconds(result.Duration),
+			SystemOut: Details(result),
+		}
+		total += result.Duration
+
+		if len(result.Error) > 0 {
+			testCase.Failure = &junitFailure{
+				Message: firstLine(result.Error),
+				Type:    errorType(result),
+				Content: result.Error,
+			}
+			suite.Failures++
+		} else if result.Action == document.ActionSkipped || result.Action == document.ActionNotStarted {
+			testCase.Skipped = &junitSkipped{
+				Message: result.Action,
+			}
+			suite.Skipped++
+		}
+
+		suite.TestCases = append(suite.TestCases, testCase)
+		suite.Tests++
+	}
+	suite.Time = formatSeconds(total)
+	suite.seconds = total
+
+	return encodeJUnit(w, []junitTestSuite{suite})
+}
+
+// encodeJUnit write test suites in JUnit XML format, totals are computed from suites
+func encodeJUnit(w io.Writer, testSuites []junitTestSuite) error {
+	suites := junitTestSuites{
+		Suites: testSuites,
+	}
+	var total float64
+	for _, suite := range testSuites {
+		suites.Tests += suite.Tests
+		suites.Failures += suite.Failures
+		suites.Skipped += suite.Skipped
+		total += suite.seconds
+	}
+	suites.Time = formatSeconds(total)
+
+	_, err := io.WriteString(w, xml.Header)
+	if err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	err = encoder.Encode(suites)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(w, "\n")
+	return err
+}
+
+// errorType return the AWS error code, a generic type if not available
+func errorType(result *document.Result) string {
+	if len(result.ErrorCode) > 0 {
+		return result.ErrorCode
+	}
+	return "Error"
+}
+
+// className return the test case class name, region is omitted if not set
+func className(command string, region string) string {
+	parts := []string{"aws-ssm-document", command}
+	if len(region) > 0 {
+		parts = append(parts, region)
+	}
+	return strings.Join(parts, ".")
+}
+
+func formatSeconds(seconds float64) string {
+	return fmt.Sprintf("%.3f", seconds)
+}
@@ -0,0 +1,139 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/daaru00/aws-ssm-document-cli/internal/document"
+	"github.com/daaru00/aws-ssm-document-cli/internal/output"
+)
+
+// MarkdownWriter write a Markdown summary, ready for $GITHUB_STEP_SUMMARY or merge request comments
+type MarkdownWriter struct{}
+
+// Write report in Markdown format
+func (m *MarkdownWriter) Write(w io.Writer, report *output.Report) error {
+	var builder strings.Builder
+
+	// Write title and totals
+	builder.WriteString(fmt.Sprintf("### aws-ssm-document %s\n\n", report.Command))
+	if len(report.AccountID) > 0 || len(report.Region) > 0 {
+		builder.WriteString(fmt.Sprintf("Account `%s`, region `%s`: ", report.AccountID, report.Region))
+	}
+	builder.WriteString(fmt.Sprintf("%d documents, %d failed\n\n", len(report.Results), report.Failed()))
+
+	// Write results table
+	builder.WriteString("| Status | Document | Action | Version | Duration | Details |\n")
+	builder.WriteString("| :----: | -------- | ------ | ------- | -------: | ------- |\n")
+	for _, result := range report.Results {
+		builder.WriteString(fmt.Sprintf("| %s | `%s` | %s | %s | %.1fs | %s |\n",
+			status(result),
+			result.Name,
+			result.Action,
+			result.Version,
+			result.Duration,
+			escapeCell(Details(result)),
+		))
+	}
+
+	// Write failures details
+	failures := []*document.Result{}
+	for _, result := range report.Results {
+		if len(result.Error) > 0 {
+			failures = append(failures, result)
+		}
+	}
+	if len(failures) > 0 {
+		builder.WriteString("\n#### Failures\n")
+		for _, result := range failures {
+			fence := codeFence(result.Error)
+			builder.WriteString(fmt.Sprintf("\n**%s** (%s)\n\n%s\n%s\n%s\n", result.Name, errorType(result), fence, result.Error, fence))
+		}
+	}
+
+	_, err := io.WriteString(w, builder.String())
+	return err
+}
+
+// status return an emoji that summarize the result
+func status(result *document.Result) string {
+	switch {
+	case len(result.Error) > 0:
+		return ":x:"
+	case result.Action == document.ActionSkipped || result.Action == document.ActionNotStarted:
+		return ":fast_forward:"
+	default:
+		return ":white_check_mark:"
+	}
+}
+
+// Details return a short description of result changes
+func Details(result *document.Result) string {
+	details := []string{}
+	if len(result.TagsAdded) > 0 {
+		details = append(details, fmt.Sprintf("tags added: %s", strings.Join(result.TagsAdded, ", ")))
+	}
+	if len(result.TagsRemoved) > 0 {
+		details = append(details, fmt.Sprintf("tags removed: %s", strings.Join(result.TagsRemoved, ", ")))
+	}
+	if len(result.AccountsAdded) > 0 {
+		details = append(details, fmt.Sprintf("accounts added: %s", strings.Join(result.AccountsAdded, ", ")))
+	}
+	if len(result.AccountsRemoved) > 0 {
+		details = append(details, fmt.Sprintf("accounts removed: %s", strings.Join(result.AccountsRemoved, ", ")))
+	}
+	if len(result.Backup) > 0 {
+		details = append(details, fmt.Sprintf("backup: %s", result.Backup))
+	}
+	if len(result.VersionsRemoved) > 0 {
+		details = append(details, fmt.Sprintf("versions removed: %s", strings.Join(result.VersionsRemoved, ", ")))
+	}
+	if len(result.AssociationsCreated) > 0 {
+		details = append(details, fmt.Sprintf("associations created: %s", strings.Join(result.AssociationsCreated, ", ")))
+	}
+	if len(result.AssociationsUpdated) > 0 {
+		details = append(details, fmt.Sprintf("associations updated: %s", strings.Join(result.AssociationsUpdated, ", ")))
+	}
+	if len(result.AssociationsRemoved) > 0 {
+		details = append(details, fmt.Sprintf("associations removed: %s", strings.Join(result.AssociationsRemoved, ", ")))
+	}
+	if len(result.WindowsCreated) > 0 {
+		details = append(details, fmt.Sprintf("maintenance windows created: %s", strings.Join(result.WindowsCreated, ", ")))
+	}
+	if len(result.WindowTasksRegistered) > 0 {
+		details = append(details, fmt.Sprintf("window tasks registered: %s", strings.Join(result.WindowTasksRegistered, ", ")))
+	}
+	if len(result.WindowTasksUpdated) > 0 {
+		details = append(details, fmt.Sprintf("window tasks updated: %s", strings.Join(result.WindowTasksUpdated, ", ")))
+	}
+	if len(result.WindowTasksDeregistered) > 0 {
+		details = append(details, fmt.Sprintf("window tasks deregistered: %s", strings.Join(result.WindowTasksDeregistered, ", ")))
+	}
+	return strings.Join(details, "; ")
+}
+
+// codeFence return a backtick fence longer than any backtick run in content, at least three
+func codeFence(content string) string {
+	longest, run := 0, 0
+	for _, char := range content {
+		if char != '`' {
+			run = 0
+			continue
+		}
+		run++
+		if run > longest {
+			longest = run
+		}
+	}
+	if longest < 3 {
+		return "```"
+	}
+	return strings.Repeat("`", longest+1)
+}
+
+// escapeCell make text safe to be used inside a table cell
+func escapeCell(text string) string {
+	text = strings.ReplaceAll(text, "|", "\\|")
+	return strings.ReplaceAll(text, "\n", " ")
+}
@@ -0,0 +1,69 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/daaru00/aws-ssm-document-cli/internal/output"
+)
+
+// Writer write a report in a specific format
+type Writer interface {
+	Write(w io.Writer, report *output.Report) error
+}
+
+// NewWriter return the writer for provided format
+func NewWriter(format string) (Writer, error) {
+	switch format {
+	case "junit":
+		return &JUnitWriter{}, nil
+	case "markdown", "md":
+		return &MarkdownWriter{}, nil
+	default:
+		return nil, fmt.Errorf("Report format %s not supported, valid values are \"junit\" or \"markdown\"", format)
+	}
+}
+
+// ParseSpec split a report specification in the form format=path
+func ParseSpec(spec string) (string, string, error) {
+	parts := strings.SplitN(spec, "=", 2)
+	if len(parts) != 2 || len(parts[0]) == 0 || len(parts[1]) == 0 {
+		return "", "", fmt.Errorf("Report %s is not valid, expected format=path (for example junit=report.xml)", spec)
+	}
+	return parts[0], parts[1], nil
+}
+
+// WriteAll write report to each specification path
+func WriteAll(specs []string, report *output.Report) error {
+	for _, spec := range specs {
+		format, path, err := ParseSpec(spec)
+		if err != nil {
+			return err
+		}
+
+		writer, err := NewWriter(format)
+		if err != nil {
+			return err
+		}
+
+		file, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+
+		err = writer.Write(file, report)
+		if err != nil {
+			file.Close()
+			return err
+		}
+
+		err = file.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
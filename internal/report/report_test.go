@@ -0,0 +1,150 @@
+package report
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/daaru00/aws-ssm-document-cli/internal/document"
+	"github.com/daaru00/aws-ssm-document-cli/internal/output"
+)
+
+func newReport() *output.Report {
+	return &output.Report{
+		Command:   "deploy",
+		AccountID: "123456789012",
+		Region:    "us-east-1",
+		Results: []*document.Result{
+			{Name: "Created", Region: "us-east-1", Action: document.ActionCreated, Version: "1", TagsAdded: []string{"Team"}, Duration: 1.5},
+			{Name: "Failed", Region: "us-east-1", Action: document.ActionSkipped, Error: "invalid content:\n```\nline | 2\n```", ErrorCode: "ValidationException", Duration: 0.5},
+			{Name: "Pending", Region: "us-east-1", Action: document.ActionNotStarted},
+		},
+	}
+}
+
+func TestJUnitWriter(t *testing.T) {
+	buffer := &bytes.Buffer{}
+	err := (&JUnitWriter{}).Write(buffer, newReport())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites tests="3" failures="1" skipped="1" time="2.000">
+  <testsuite name="aws-ssm-document deploy" tests="3" failures="1" skipped="1" time="2.000">
+    <testcase name="Created" classname="aws-ssm-document.deploy.us-east-1" time="1.500">
+      <system-out>tags added: Team</system-out>
+    </testcase>
+    <testcase name="Failed" classname="aws-ssm-document.deploy.us-east-1" time="0.500">
+      <failure message="invalid content:" type="ValidationException">invalid content:&#xA;` + "```" + `&#xA;line | 2&#xA;` + "```" + `</failure>
+    </testcase>
+    <testcase name="Pending" classname="aws-ssm-document.deploy.us-east-1" time="0.000">
+      <skipped message="not-started"></skipped>
+    </testcase>
+  </testsuite>
+</testsuites>
+`
+	if buffer.String() != expected {
+		t.Errorf("unexpected report:\n%s\nexpected:\n%s", buffer.String(), expected)
+	}
+}
+
+func TestMarkdownWriter(t *testing.T) {
+	buffer := &bytes.Buffer{}
+	err := (&MarkdownWriter{}).Write(buffer, newReport())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "### aws-ssm-document deploy\n" +
+		"\n" +
+		"Account `123456789012`, region `us-east-1`: 3 documents, 1 failed\n" +
+		"\n" +
+		"| Status | Document | Action | Version | Duration | Details |\n" +
+		"| :----: | -------- | ------ | ------- | -------: | ------- |\n" +
+		"| :white_check_mark: | `Created` | created | 1 | 1.5s | tags added: Team |\n" +
+		"| :x: | `Failed` | skipped |  | 0.5s |  |\n" +
+		"| :fast_forward: | `Pending` | not-started |  | 0.0s |  |\n" +
+		"\n" +
+		"#### Failures\n" +
+		"\n" +
+		"**Failed** (ValidationException)\n" +
+		"\n" +
+		"````\n" +
+		"invalid content:\n" +
+		"```\n" +
+		"line | 2\n" +
+		"```\n" +
+		"````\n"
+	if buffer.String() != expected {
+		t.Errorf("unexpected report:\n%s\nexpected:\n%s", buffer.String(), expected)
+	}
+}
+
+func TestCodeFence(t *testing.T) {
+	cases := map[string]string{
+		"plain":           "```",
+		"inline `code`":   "```",
+		"``` fenced ```":  "````",
+		"a ````` b ``` c": "``````",
+	}
+	for content, expected := range cases {
+		if fence := codeFence(content); fence != expected {
+			t.Errorf("codeFence(%q) = %q, expected %q", content, fence, expected)
+		}
+	}
+}
+
+func TestParseSpec(t *testing.T) {
+	cases := []struct {
+		spec   string
+		format string
+		path   string
+		valid  bool
+	}{
+		{"junit=report.xml", "junit", "report.xml", true},
+		{"markdown=out/summary=1.md", "markdown", "out/summary=1.md", true},
+		{"junit", "", "", false},
+		{"=report.xml", "", "", false},
+		{"junit=", "", "", false},
+	}
+	for _, c := range cases {
+		format, path, err := ParseSpec(c.spec)
+		if (err == nil) != c.valid {
+			t.Errorf("ParseSpec(%q) error = %v, expected valid %v", c.spec, err, c.valid)
+			continue
+		}
+		if format != c.format || path != c.path {
+			t.Errorf("ParseSpec(%q) = %q, %q, expected %q, %q", c.spec, format, path, c.format, c.path)
+		}
+	}
+}
+
+func TestWriteAll(t *testing.T) {
+	dir, err := ioutil.TempDir("", "report")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	junit := filepath.Join(dir, "report.xml")
+	markdown := filepath.Join(dir, "summary.md")
+	err = WriteAll([]string{"junit=" + junit, "md=" + markdown}, newReport())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{junit, markdown} {
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(content) == 0 {
+			t.Errorf("report %s is empty", path)
+		}
+	}
+
+	err = WriteAll([]string{"html=" + filepath.Join(dir, "report.html")}, newReport())
+	if err == nil {
+		t.Error("expected an error for unsupported format")
+	}
+}
@@ -0,0 +1,105 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/daaru00/aws-ssm-document-cli/internal/local"
+)
+
+// WriteTestsJUnit write test cases results in JUnit XML format, each document is a test suite
+func WriteTestsJUnit(w io.Writer, results []*local.TestResult) error {
+	suites := []junitTestSuite{}
+	indexes := map[string]int{}
+
+	for _, result := range results {
+		// Group test cases by document
+		index, exist := indexes[result.Document]
+		if !exist {
+			index = len(suites)
+			indexes[result.Document] = index
+			suites = append(suites, junitTestSuite{
+				Name:      result.Document,
+				TestCases: []junitTestCase{},
+			})
+		}
+		suite := &suites[index]
+
+		testCase := junitTestCase{
+			Name:      result.Name,
+			ClassName: className("test", result.Document),
+			Time:      formatSeconds(result.Duration),
+			SystemOut: testOutput(result),
+		}
+		suite.seconds += result.Duration
+
+		if !result.Passed {
+			message := strings.Join(result.Failures, "\n")
+			testCase.Failure = &junitFailure{
+				Message: firstLine(message),
+				Type:    "AssertionError",
+				Content: message,
+			}
+			suite.Failures++
+		}
+
+		suite.TestCases = append(suite.TestCases, testCase)
+		suite.Tests++
+	}
+
+	for i := range suites {
+		suites[i].Time = formatSeconds(suites[i].seconds)
+	}
+
+	return encodeJUnit(w, suites)
+}
+
+// WriteTestsAll write test cases results to each specification path, only JUnit format is supported
+func WriteTestsAll(specs []string, results []*local.TestResult) error {
+	for _, spec := range specs {
+		format, path, err := ParseSpec(spec)
+		if err != nil {
+			return err
+		}
+
+		if format != "junit" {
+			return fmt.Errorf("Report format %s not supported for tests, valid value is \"junit\"", format)
+		}
+
+		file, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+
+		err = WriteTestsJUnit(file, results)
+		if err != nil {
+			file.Close()
+			return err
+		}
+
+		err = file.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// testOutput return the steps output of a test case
+func testOutput(result *local.TestResult) string {
+	var out strings.Builder
+	for _, step := range result.Steps {
+		fmt.Fprintf(&out, "[%s] %s (exit code %d)\n", step.Step, step.Status, step.ExitCode)
+		out.WriteString(step.Stdout)
+		out.WriteString(step.Stderr)
+	}
+	return out.String()
+}
+
+// firstLine return the first line of a message
+func firstLine(message string) string {
+	return strings.SplitN(message, "\n", 2)[0]
+}
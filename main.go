@@ -4,10 +4,20 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strings"
 
 	"github.com/daaru00/aws-ssm-document-cli/cmd/deploy"
+	"github.com/daaru00/aws-ssm-document-cli/cmd/exec"
+	"github.com/daaru00/aws-ssm-document-cli/cmd/graph"
+	"github.com/daaru00/aws-ssm-document-cli/cmd/mock"
+	"github.com/daaru00/aws-ssm-document-cli/cmd/prune"
 	"github.com/daaru00/aws-ssm-document-cli/cmd/remove"
+	"github.com/daaru00/aws-ssm-document-cli/cmd/restore"
+	"github.com/daaru00/aws-ssm-document-cli/cmd/review"
+	"github.com/daaru00/aws-ssm-document-cli/cmd/test"
+	"github.com/daaru00/aws-ssm-document-cli/cmd/validate"
 	"github.com/daaru00/aws-ssm-document-cli/internal/config"
+	"github.com/daaru00/aws-ssm-document-cli/internal/pool"
 	"github.com/urfave/cli/v2"
 )
 
@@ -34,6 +44,11 @@ func main() {
 			Usage:   "AWS region",
 			EnvVars: []string{"AWS_REGION", "AWS_DEFAULT_REGION"},
 		},
+		&cli.StringFlag{
+			Name:    "endpoint-url",
+			Usage:   "Override SSM and STS endpoint URL, for example a local serve-mock server",
+			EnvVars: []string{"AWS_ENDPOINT_URL", "SSM_DOCUMENT_ENDPOINT_URL"},
+		},
 		&cli.StringFlag{
 			Name:    "config-file",
 			Aliases: []string{"cf"},
@@ -48,6 +63,45 @@ func main() {
 			Value:   "yml",
 			EnvVars: []string{"SSM_DOCUMENT_CONFIG_PARSER"},
 		},
+		&cli.StringFlag{
+			Name:    "output",
+			Aliases: []string{"o"},
+			Usage:   "Output format, valid values are \"text\", \"json\" or \"yaml\"",
+			Value:   "text",
+			EnvVars: []string{"SSM_DOCUMENT_OUTPUT"},
+		},
+		&cli.StringFlag{
+			Name:    "project",
+			Usage:   "Project identifier written in the ownership tag of deployed documents, required by deploy, remove and prune",
+			EnvVars: []string{"SSM_DOCUMENT_PROJECT"},
+		},
+		&cli.BoolFlag{
+			Name:    "no-input",
+			Usage:   "Never prompt, fail when an answer is required, also enabled when CI environment variable is \"true\"",
+			EnvVars: []string{"SSM_DOCUMENT_NO_INPUT"},
+		},
+		&cli.StringSliceFlag{
+			Name:    "protected-stage",
+			Usage:   "Stage, the SSM_DOCUMENT_ENV value, where all documents are protected",
+			EnvVars: []string{"SSM_DOCUMENT_PROTECTED_STAGES"},
+		},
+		&cli.StringSliceFlag{
+			Name:    "protected-account",
+			Usage:   "Account ID where all documents are protected",
+			EnvVars: []string{"SSM_DOCUMENT_PROTECTED_ACCOUNTS"},
+		},
+		&cli.Float64Flag{
+			Name:    "api-rate-limit",
+			Usage:   "Max AWS API requests per second, reduced automatically when throttled",
+			Value:   pool.DefaultRate,
+			EnvVars: []string{"SSM_DOCUMENT_API_RATE_LIMIT"},
+		},
+		&cli.IntFlag{
+			Name:    "api-max-retries",
+			Usage:   "Max retries for a single AWS API request",
+			Value:   pool.DefaultMaxRetries,
+			EnvVars: []string{"SSM_DOCUMENT_API_MAX_RETRIES"},
+		},
 	}
 
 	// Create CLI application
@@ -60,6 +114,16 @@ func main() {
 		Commands: []*cli.Command{
 			deploy.NewCommand(globalFlags),
 			remove.NewCommand(globalFlags),
+			prune.NewCommand(globalFlags),
+			restore.NewCommand(globalFlags),
+			graph.NewCommand(globalFlags),
+			validate.NewCommand(globalFlags),
+			mock.NewCommand(globalFlags),
+			exec.NewCommand(globalFlags),
+			test.NewCommand(globalFlags),
+			review.NewReviewsCommand(globalFlags),
+			review.NewApproveCommand(globalFlags),
+			review.NewRejectCommand(globalFlags),
 		},
 		Flags:                globalFlags,
 		EnableBashCompletion: true,
@@ -76,6 +140,30 @@ func main() {
 			if len(c.String("config-parser")) > 0 {
 				os.Setenv("CONFIG_PARSER", c.String("config-parser"))
 			}
+			if c.IsSet("endpoint-url") {
+				os.Setenv("SSM_DOCUMENT_ENDPOINT_URL", c.String("endpoint-url"))
+			}
+			if c.IsSet("output") {
+				os.Setenv("SSM_DOCUMENT_OUTPUT", c.String("output"))
+			}
+			if c.IsSet("project") {
+				os.Setenv("SSM_DOCUMENT_PROJECT", c.String("project"))
+			}
+			if c.IsSet("no-input") {
+				os.Setenv("SSM_DOCUMENT_NO_INPUT", c.String("no-input"))
+			}
+			if c.IsSet("protected-stage") {
+				os.Setenv("SSM_DOCUMENT_PROTECTED_STAGES", strings.Join(c.StringSlice("protected-stage"), ","))
+			}
+			if c.IsSet("protected-account") {
+				os.Setenv("SSM_DOCUMENT_PROTECTED_ACCOUNTS", strings.Join(c.StringSlice("protected-account"), ","))
+			}
+			if c.IsSet("api-rate-limit") {
+				os.Setenv("SSM_DOCUMENT_API_RATE_LIMIT", c.String("api-rate-limit"))
+			}
+			if c.IsSet("api-max-retries") {
+				os.Setenv("SSM_DOCUMENT_API_MAX_RETRIES", c.String("api-max-retries"))
+			}
 			return nil
 		},
 	}
@@ -83,7 +171,7 @@ func main() {
 	// Run the CLI application
 	err = app.Run(os.Args)
 	if err != nil {
-		fmt.Println(err)
+		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
 }
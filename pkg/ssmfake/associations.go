@@ -0,0 +1,374 @@
+package ssmfake
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/ssm"
+)
+
+// Target of an association
+type Target struct {
+	Key    string   `json:"key"`
+	Values []string `json:"values"`
+}
+
+// OutputLocation is the S3 location where association output is stored
+type OutputLocation struct {
+	Bucket string `json:"bucket"`
+	Prefix string `json:"prefix,omitempty"`
+	Region string `json:"region,omitempty"`
+}
+
+// Association stored by the fake
+type Association struct {
+	ID                 string              `json:"id"`
+	Name               string              `json:"name,omitempty"`
+	DocumentName       string              `json:"documentName"`
+	DocumentVersion    string              `json:"documentVersion"`
+	Version            int                 `json:"version"`
+	Targets            []Target            `json:"targets"`
+	ScheduleExpression string              `json:"scheduleExpression,omitempty"`
+	Parameters         map[string][]string `json:"parameters,omitempty"`
+	OutputLocation     *OutputLocation     `json:"outputLocation,omitempty"`
+	ComplianceSeverity string              `json:"complianceSeverity,omitempty"`
+	MaxConcurrency     string              `json:"maxConcurrency,omitempty"`
+	MaxErrors          string              `json:"maxErrors,omitempty"`
+	CreatedDate        time.Time           `json:"createdDate"`
+}
+
+// associationFields is the association configuration shared by create and update inputs
+type associationFields struct {
+	name               *string
+	documentVersion    *string
+	targets            []*ssm.Target
+	scheduleExpression *string
+	parameters         map[string][]*string
+	outputLocation     *ssm.InstanceAssociationOutputLocation
+	complianceSeverity *string
+	maxConcurrency     *string
+	maxErrors          *string
+}
+
+// apply overwrite association configuration, like SSM unset fields are reset
+func (a *Association) apply(fields associationFields) {
+	a.Name = aws.StringValue(fields.name)
+	a.DocumentVersion = aws.StringValue(fields.documentVersion)
+	if len(a.DocumentVersion) == 0 {
+		a.DocumentVersion = "$DEFAULT"
+	}
+	a.ScheduleExpression = aws.StringValue(fields.scheduleExpression)
+	a.ComplianceSeverity = aws.StringValue(fields.complianceSeverity)
+	a.MaxConcurrency = aws.StringValue(fields.maxConcurrency)
+	a.MaxErrors = aws.StringValue(fields.maxErrors)
+
+	a.Targets = []Target{}
+	for _, target := range fields.targets {
+		a.Targets = append(a.Targets, Target{
+			Key:    aws.StringValue(target.Key),
+			Values: aws.StringValueSlice(target.Values),
+		})
+	}
+
+	a.Parameters = nil
+	for name, values := range fields.parameters {
+		if a.Parameters == nil {
+			a.Parameters = map[string][]string{}
+		}
+		a.Parameters[name] = aws.StringValueSlice(values)
+	}
+
+	a.OutputLocation = nil
+	if fields.outputLocation != nil && fields.outputLocation.S3Location != nil {
+		a.OutputLocation = &OutputLocation{
+			Bucket: aws.StringValue(fields.outputLocation.S3Location.OutputS3BucketName),
+			Prefix: aws.StringValue(fields.outputLocation.S3Location.OutputS3KeyPrefix),
+			Region: aws.StringValue(fields.outputLocation.S3Location.OutputS3Region),
+		}
+	}
+}
+
+// description build the association description
+func (a *Association) description() *ssm.AssociationDescription {
+	description := &ssm.AssociationDescription{
+		AssociationId:      aws.String(a.ID),
+		AssociationVersion: aws.String(strconv.Itoa(a.Version)),
+		Name:               aws.String(a.DocumentName),
+		DocumentVersion:    aws.String(a.DocumentVersion),
+		Date:               aws.Time(a.CreatedDate),
+	}
+	if len(a.Name) > 0 {
+		description.AssociationName = aws.String(a.Name)
+	}
+	if len(a.ScheduleExpression) > 0 {
+		description.ScheduleExpression = aws.String(a.ScheduleExpression)
+	}
+	if len(a.ComplianceSeverity) > 0 {
+		description.ComplianceSeverity = aws.String(a.ComplianceSeverity)
+	}
+	if len(a.MaxConcurrency) > 0 {
+		description.MaxConcurrency = aws.String(a.MaxConcurrency)
+	}
+	if len(a.MaxErrors) > 0 {
+		description.MaxErrors = aws.String(a.MaxErrors)
+	}
+	for _, target := range a.Targets {
+		description.Targets = append(description.Targets, &ssm.Target{
+			Key:    aws.String(target.Key),
+			Values: aws.StringSlice(target.Values),
+		})
+	}
+	for name, values := range a.Parameters {
+		if description.Parameters == nil {
+			description.Parameters = map[string][]*string{}
+		}
+		description.Parameters[name] = aws.StringSlice(values)
+	}
+	if a.OutputLocation != nil {
+		location := &ssm.S3OutputLocation{
+			OutputS3BucketName: aws.String(a.OutputLocation.Bucket),
+		}
+		if len(a.OutputLocation.Prefix) > 0 {
+			location.OutputS3KeyPrefix = aws.String(a.OutputLocation.Prefix)
+		}
+		if len(a.OutputLocation.Region) > 0 {
+			location.OutputS3Region = aws.String(a.OutputLocation.Region)
+		}
+		description.OutputLocation = &ssm.InstanceAssociationOutputLocation{S3Location: location}
+	}
+	return description
+}
+
+// Association return a stored association by name, nil if not found
+func (f *Fake) Association(name string) *Association {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, association := range f.state.Associations {
+		if association.Name == name {
+			return association
+		}
+	}
+	return nil
+}
+
+// findAssociation return the association or a not found error, must be called with lock held
+func (f *Fake) findAssociation(id *string) (*Association, error) {
+	association, ok := f.state.Associations[aws.StringValue(id)]
+	if !ok {
+		return nil, newError(ssm.ErrCodeAssociationDoesNotExist, fmt.Sprintf("Association %s does not exist", aws.StringValue(id)))
+	}
+	return association, nil
+}
+
+// checkAssociationName check that no other association use name, must be called with lock held
+func (f *Fake) checkAssociationName(name *string, id string) error {
+	if name == nil {
+		return nil
+	}
+	for _, association := range f.state.Associations {
+		if association.Name == *name && association.ID != id {
+			return newError(ssm.ErrCodeAssociationAlreadyExists, fmt.Sprintf("Association with name %s already exists", *name))
+		}
+	}
+	return nil
+}
+
+// CreateAssociation create a new association of a document
+func (f *Fake) CreateAssociation(input *ssm.CreateAssociationInput) (*ssm.CreateAssociationOutput, error) {
+	return f.CreateAssociationWithContext(aws.BackgroundContext(), input)
+}
+
+// CreateAssociationWithContext create a new association of a document, association name must be unique
+func (f *Fake) CreateAssociationWithContext(ctx aws.Context, input *ssm.CreateAssociationInput, opts ...request.Option) (*ssm.CreateAssociationOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.begin("CreateAssociation", input); err != nil {
+		return nil, err
+	}
+
+	document, err := f.find(input.Name)
+	if err != nil {
+		return nil, err
+	}
+	if err := f.checkAssociationName(input.AssociationName, ""); err != nil {
+		return nil, err
+	}
+
+	// Store association
+	created := time.Now().UTC()
+	association := &Association{
+		ID:           newID(fmt.Sprintf("%s/%s/%d", document.Name, aws.StringValue(input.AssociationName), created.UnixNano())),
+		DocumentName: document.Name,
+		Version:      1,
+		CreatedDate:  created,
+	}
+	association.apply(associationFields{
+		name:               input.AssociationName,
+		documentVersion:    input.DocumentVersion,
+		targets:            input.Targets,
+		scheduleExpression: input.ScheduleExpression,
+		parameters:         input.Parameters,
+		outputLocation:     input.OutputLocation,
+		complianceSeverity: input.ComplianceSeverity,
+		maxConcurrency:     input.MaxConcurrency,
+		maxErrors:          input.MaxErrors,
+	})
+	f.state.Associations[association.ID] = association
+
+	return &ssm.CreateAssociationOutput{
+		AssociationDescription: association.description(),
+	}, nil
+}
+
+// UpdateAssociation update an association creating a new association version
+func (f *Fake) UpdateAssociation(input *ssm.UpdateAssociationInput) (*ssm.UpdateAssociationOutput, error) {
+	return f.UpdateAssociationWithContext(aws.BackgroundContext(), input)
+}
+
+// UpdateAssociationWithContext update an association creating a new association version,
+// like SSM optional fields not provided are reset
+func (f *Fake) UpdateAssociationWithContext(ctx aws.Context, input *ssm.UpdateAssociationInput, opts ...request.Option) (*ssm.UpdateAssociationOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.begin("UpdateAssociation", input); err != nil {
+		return nil, err
+	}
+
+	association, err := f.findAssociation(input.AssociationId)
+	if err != nil {
+		return nil, err
+	}
+	if input.AssociationVersion != nil && *input.AssociationVersion != "$LATEST" && *input.AssociationVersion != strconv.Itoa(association.Version) {
+		return nil, newError(ssm.ErrCodeInvalidAssociationVersion, fmt.Sprintf("Association version %s is not the latest", *input.AssociationVersion))
+	}
+	if err := f.checkAssociationName(input.AssociationName, association.ID); err != nil {
+		return nil, err
+	}
+
+	// Update association
+	association.apply(associationFields{
+		name:               input.AssociationName,
+		documentVersion:    input.DocumentVersion,
+		targets:            input.Targets,
+		scheduleExpression: input.ScheduleExpression,
+		parameters:         input.Parameters,
+		outputLocation:     input.OutputLocation,
+		complianceSeverity: input.ComplianceSeverity,
+		maxConcurrency:     input.MaxConcurrency,
+		maxErrors:          input.MaxErrors,
+	})
+	association.Version++
+
+	return &ssm.UpdateAssociationOutput{
+		AssociationDescription: association.description(),
+	}, nil
+}
+
+// DescribeAssociation return an association by ID
+func (f *Fake) DescribeAssociation(input *ssm.DescribeAssociationInput) (*ssm.DescribeAssociationOutput, error) {
+	return f.DescribeAssociationWithContext(aws.BackgroundContext(), input)
+}
+
+// DescribeAssociationWithContext return an association by ID
+func (f *Fake) DescribeAssociationWithContext(ctx aws.Context, input *ssm.DescribeAssociationInput, opts ...request.Option) (*ssm.DescribeAssociationOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.begin("DescribeAssociation", unvalidated{}); err != nil {
+		return nil, err
+	}
+
+	association, err := f.findAssociation(input.AssociationId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ssm.DescribeAssociationOutput{
+		AssociationDescription: association.description(),
+	}, nil
+}
+
+// DeleteAssociation delete an association by ID
+func (f *Fake) DeleteAssociation(input *ssm.DeleteAssociationInput) (*ssm.DeleteAssociationOutput, error) {
+	return f.DeleteAssociationWithContext(aws.BackgroundContext(), input)
+}
+
+// DeleteAssociationWithContext delete an association by ID
+func (f *Fake) DeleteAssociationWithContext(ctx aws.Context, input *ssm.DeleteAssociationInput, opts ...request.Option) (*ssm.DeleteAssociationOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.begin("DeleteAssociation", unvalidated{}); err != nil {
+		return nil, err
+	}
+
+	association, err := f.findAssociation(input.AssociationId)
+	if err != nil {
+		return nil, err
+	}
+	delete(f.state.Associations, association.ID)
+
+	return &ssm.DeleteAssociationOutput{}, nil
+}
+
+// ListAssociations return associations, filters by document name and association name are supported
+func (f *Fake) ListAssociations(input *ssm.ListAssociationsInput) (*ssm.ListAssociationsOutput, error) {
+	return f.ListAssociationsWithContext(aws.BackgroundContext(), input)
+}
+
+// ListAssociationsWithContext return associations, filters by document name and association name are supported
+func (f *Fake) ListAssociationsWithContext(ctx aws.Context, input *ssm.ListAssociationsInput, opts ...request.Option) (*ssm.ListAssociationsOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.begin("ListAssociations", input); err != nil {
+		return nil, err
+	}
+
+	// Filter associations
+	associations := []*Association{}
+	for _, association := range f.state.Associations {
+		match := true
+		for _, filter := range input.AssociationFilterList {
+			switch aws.StringValue(filter.Key) {
+			case ssm.AssociationFilterKeyName:
+				match = match && association.DocumentName == aws.StringValue(filter.Value)
+			case ssm.AssociationFilterKeyAssociationName:
+				match = match && association.Name == aws.StringValue(filter.Value)
+			default:
+				return nil, newError(ssm.ErrCodeInvalidFilterKey, fmt.Sprintf("Filter key %s is not supported", aws.StringValue(filter.Key)))
+			}
+		}
+		if match {
+			associations = append(associations, association)
+		}
+	}
+	sort.Slice(associations, func(i, j int) bool {
+		return associations[i].ID < associations[j].ID
+	})
+
+	output := &ssm.ListAssociationsOutput{
+		Associations: []*ssm.Association{},
+	}
+	for _, association := range associations {
+		description := association.description()
+		output.Associations = append(output.Associations, &ssm.Association{
+			AssociationId:      description.AssociationId,
+			AssociationName:    description.AssociationName,
+			AssociationVersion: description.AssociationVersion,
+			DocumentVersion:    description.DocumentVersion,
+			Name:               description.Name,
+			ScheduleExpression: description.ScheduleExpression,
+			Targets:            description.Targets,
+		})
+	}
+
+	return output, nil
+}
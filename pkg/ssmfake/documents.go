@@ -0,0 +1,471 @@
+package ssmfake
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"gopkg.in/yaml.v2"
+)
+
+// validateContent check that content can be parsed using format
+func validateContent(content string, format string) error {
+	switch format {
+	case "JSON":
+		if !json.Valid([]byte(content)) {
+			return newError(ssm.ErrCodeInvalidDocumentContent, "Document content is not valid JSON")
+		}
+	case "YAML":
+		parsed := map[string]interface{}{}
+		if yaml.Unmarshal([]byte(content), &parsed) != nil {
+			return newError(ssm.ErrCodeInvalidDocumentContent, "Document content is not valid YAML")
+		}
+	case "TEXT":
+		break
+	default:
+		return newError(ssm.ErrCodeInvalidDocumentContent, fmt.Sprintf("Document format %s is not supported", format))
+	}
+	return nil
+}
+
+// description build the document description for version, must be called with lock held
+func (f *Fake) description(document *Document, version *Version) *ssm.DocumentDescription {
+	description := &ssm.DocumentDescription{
+		Name:            aws.String(document.Name),
+		DocumentType:    aws.String(document.Type),
+		DocumentFormat:  aws.String(version.Format),
+		DocumentVersion: aws.String(version.Version),
+		DefaultVersion:  aws.String(document.DefaultVersion),
+		LatestVersion:   aws.String(document.LatestVersion().Version),
+		Hash:            aws.String(version.Hash),
+		HashType:        aws.String(ssm.DocumentHashTypeSha256),
+		Owner:           aws.String(document.Owner),
+		CreatedDate:     aws.Time(version.CreatedDate),
+		Status:          aws.String(ssm.DocumentStatusActive),
+		Tags:            tagList(document.Tags),
+	}
+	if len(version.VersionName) > 0 {
+		description.VersionName = aws.String(version.VersionName)
+	}
+	if len(version.ReviewStatus) > 0 {
+		description.ReviewStatus = aws.String(version.ReviewStatus)
+	}
+	for _, v := range document.Versions {
+		switch v.ReviewStatus {
+		case ssm.ReviewStatusPending:
+			description.PendingReviewVersion = aws.String(v.Version)
+		case ssm.ReviewStatusApproved:
+			description.ApprovedVersion = aws.String(v.Version)
+		}
+	}
+	for _, review := range version.Reviews {
+		description.ReviewInformation = append(description.ReviewInformation, &ssm.ReviewInformation{
+			Reviewer:     aws.String(review.Reviewer),
+			Status:       aws.String(reviewStatus(review.Action)),
+			ReviewedTime: aws.Time(review.Date),
+		})
+	}
+	for _, requirement := range document.Requires {
+		requires := &ssm.DocumentRequires{Name: aws.String(requirement.Name)}
+		if len(requirement.Version) > 0 {
+			requires.Version = aws.String(requirement.Version)
+		}
+		description.Requires = append(description.Requires, requires)
+	}
+	return description
+}
+
+// CreateDocument create a new document with version 1
+func (f *Fake) CreateDocument(input *ssm.CreateDocumentInput) (*ssm.CreateDocumentOutput, error) {
+	return f.CreateDocumentWithContext(aws.BackgroundContext(), input)
+}
+
+// CreateDocumentWithContext create a new document with version 1
+func (f *Fake) CreateDocumentWithContext(ctx aws.Context, input *ssm.CreateDocumentInput, opts ...request.Option) (*ssm.CreateDocumentOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.begin("CreateDocument", input); err != nil {
+		return nil, err
+	}
+
+	// Check document
+	if _, ok := f.state.Documents[*input.Name]; ok {
+		return nil, newError(ssm.ErrCodeDocumentAlreadyExists, fmt.Sprintf("Document with name %s already exists", *input.Name))
+	}
+	format := aws.StringValue(input.DocumentFormat)
+	if len(format) == 0 {
+		format = ssm.DocumentFormatJson
+	}
+	if err := validateContent(*input.Content, format); err != nil {
+		return nil, err
+	}
+	documentType := aws.StringValue(input.DocumentType)
+	if len(documentType) == 0 {
+		documentType = ssm.DocumentTypeCommand
+	}
+
+	// Store document
+	document := &Document{
+		Name:           *input.Name,
+		Type:           documentType,
+		Owner:          f.AccountID,
+		DefaultVersion: "1",
+		Versions: []*Version{
+			{
+				Version:     "1",
+				VersionName: aws.StringValue(input.VersionName),
+				Content:     *input.Content,
+				Format:      format,
+				Hash:        hash(*input.Content),
+				CreatedDate: time.Now().UTC(),
+			},
+		},
+		Tags:       map[string]string{},
+		AccountIDs: []string{},
+	}
+	for _, tag := range input.Tags {
+		document.Tags[*tag.Key] = *tag.Value
+	}
+	for _, requires := range input.Requires {
+		document.Requires = append(document.Requires, Requirement{
+			Name:    aws.StringValue(requires.Name),
+			Version: aws.StringValue(requires.Version),
+		})
+	}
+	f.state.Documents[document.Name] = document
+
+	return &ssm.CreateDocumentOutput{
+		DocumentDescription: f.description(document, document.LatestVersion()),
+	}, nil
+}
+
+// UpdateDocument create a new document version, default version is not changed
+func (f *Fake) UpdateDocument(input *ssm.UpdateDocumentInput) (*ssm.UpdateDocumentOutput, error) {
+	return f.UpdateDocumentWithContext(aws.BackgroundContext(), input)
+}
+
+// UpdateDocumentWithContext create a new document version, default version is not changed
+func (f *Fake) UpdateDocumentWithContext(ctx aws.Context, input *ssm.UpdateDocumentInput, opts ...request.Option) (*ssm.UpdateDocumentOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.begin("UpdateDocument", input); err != nil {
+		return nil, err
+	}
+
+	document, err := f.find(input.Name)
+	if err != nil {
+		return nil, err
+	}
+	latest := document.LatestVersion()
+
+	// Only latest version can be updated
+	if input.DocumentVersion != nil && *input.DocumentVersion != "$LATEST" && *input.DocumentVersion != latest.Version {
+		return nil, newError(ssm.ErrCodeInvalidDocumentVersion, "Only the latest version can be updated")
+	}
+
+	// Check content
+	format := aws.StringValue(input.DocumentFormat)
+	if len(format) == 0 {
+		format = latest.Format
+	}
+	if err := validateContent(*input.Content, format); err != nil {
+		return nil, err
+	}
+	if hash(*input.Content) == latest.Hash {
+		return nil, newError(ssm.ErrCodeDuplicateDocumentContent, "The content of the association document matches another document")
+	}
+	for _, version := range document.Versions {
+		if input.VersionName != nil && version.VersionName == *input.VersionName {
+			return nil, newError(ssm.ErrCodeDuplicateDocumentVersionName, fmt.Sprintf("Version name %s already exists", *input.VersionName))
+		}
+	}
+
+	// Add new version
+	number, _ := strconv.Atoi(latest.Version)
+	version := &Version{
+		Version:     strconv.Itoa(number + 1),
+		VersionName: aws.StringValue(input.VersionName),
+		Content:     *input.Content,
+		Format:      format,
+		Hash:        hash(*input.Content),
+		CreatedDate: time.Now().UTC(),
+	}
+	document.Versions = append(document.Versions, version)
+
+	return &ssm.UpdateDocumentOutput{
+		DocumentDescription: f.description(document, version),
+	}, nil
+}
+
+// UpdateDocumentDefaultVersion change the document default version
+func (f *Fake) UpdateDocumentDefaultVersion(input *ssm.UpdateDocumentDefaultVersionInput) (*ssm.UpdateDocumentDefaultVersionOutput, error) {
+	return f.UpdateDocumentDefaultVersionWithContext(aws.BackgroundContext(), input)
+}
+
+// UpdateDocumentDefaultVersionWithContext change the document default version
+func (f *Fake) UpdateDocumentDefaultVersionWithContext(ctx aws.Context, input *ssm.UpdateDocumentDefaultVersionInput, opts ...request.Option) (*ssm.UpdateDocumentDefaultVersionOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.begin("UpdateDocumentDefaultVersion", input); err != nil {
+		return nil, err
+	}
+
+	document, err := f.find(input.Name)
+	if err != nil {
+		return nil, err
+	}
+	version := document.GetVersion(*input.DocumentVersion)
+	if version == nil {
+		return nil, newError(ssm.ErrCodeInvalidDocumentVersion, fmt.Sprintf("Version %s does not exist", *input.DocumentVersion))
+	}
+	document.DefaultVersion = version.Version
+
+	return &ssm.UpdateDocumentDefaultVersionOutput{
+		Description: &ssm.DocumentDefaultVersionDescription{
+			Name:               aws.String(document.Name),
+			DefaultVersion:     aws.String(version.Version),
+			DefaultVersionName: aws.String(version.VersionName),
+		},
+	}, nil
+}
+
+// GetDocument return the document content
+func (f *Fake) GetDocument(input *ssm.GetDocumentInput) (*ssm.GetDocumentOutput, error) {
+	return f.GetDocumentWithContext(aws.BackgroundContext(), input)
+}
+
+// GetDocumentWithContext return the document content
+func (f *Fake) GetDocumentWithContext(ctx aws.Context, input *ssm.GetDocumentInput, opts ...request.Option) (*ssm.GetDocumentOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.begin("GetDocument", input); err != nil {
+		return nil, err
+	}
+
+	document, err := f.find(input.Name)
+	if err != nil {
+		return nil, err
+	}
+	version := document.GetVersion(aws.StringValue(input.DocumentVersion))
+	if version == nil {
+		return nil, newError(ssm.ErrCodeInvalidDocumentVersion, fmt.Sprintf("Version %s does not exist", *input.DocumentVersion))
+	}
+
+	output := &ssm.GetDocumentOutput{
+		Name:            aws.String(document.Name),
+		DocumentType:    aws.String(document.Type),
+		DocumentFormat:  aws.String(version.Format),
+		DocumentVersion: aws.String(version.Version),
+		Content:         aws.String(version.Content),
+		CreatedDate:     aws.Time(version.CreatedDate),
+		Status:          aws.String(ssm.DocumentStatusActive),
+	}
+	if len(version.VersionName) > 0 {
+		output.VersionName = aws.String(version.VersionName)
+	}
+	if len(version.ReviewStatus) > 0 {
+		output.ReviewStatus = aws.String(version.ReviewStatus)
+	}
+	return output, nil
+}
+
+// DescribeDocument return the document description
+func (f *Fake) DescribeDocument(input *ssm.DescribeDocumentInput) (*ssm.DescribeDocumentOutput, error) {
+	return f.DescribeDocumentWithContext(aws.BackgroundContext(), input)
+}
+
+// DescribeDocumentWithContext return the document description
+func (f *Fake) DescribeDocumentWithContext(ctx aws.Context, input *ssm.DescribeDocumentInput, opts ...request.Option) (*ssm.DescribeDocumentOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.begin("DescribeDocument", input); err != nil {
+		return nil, err
+	}
+
+	document, err := f.find(input.Name)
+	if err != nil {
+		return nil, err
+	}
+	version := document.GetVersion(aws.StringValue(input.DocumentVersion))
+	if version == nil {
+		return nil, newError(ssm.ErrCodeInvalidDocumentVersion, fmt.Sprintf("Version %s does not exist", *input.DocumentVersion))
+	}
+
+	return &ssm.DescribeDocumentOutput{
+		Document: f.description(document, version),
+	}, nil
+}
+
+// ListDocumentVersions return all document versions
+func (f *Fake) ListDocumentVersions(input *ssm.ListDocumentVersionsInput) (*ssm.ListDocumentVersionsOutput, error) {
+	return f.ListDocumentVersionsWithContext(aws.BackgroundContext(), input)
+}
+
+// ListDocumentVersionsWithContext return all document versions
+func (f *Fake) ListDocumentVersionsWithContext(ctx aws.Context, input *ssm.ListDocumentVersionsInput, opts ...request.Option) (*ssm.ListDocumentVersionsOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.begin("ListDocumentVersions", input); err != nil {
+		return nil, err
+	}
+
+	document, err := f.find(input.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	output := &ssm.ListDocumentVersionsOutput{}
+	for _, version := range document.Versions {
+		info := &ssm.DocumentVersionInfo{
+			Name:             aws.String(document.Name),
+			DocumentVersion:  aws.String(version.Version),
+			DocumentFormat:   aws.String(version.Format),
+			CreatedDate:      aws.Time(version.CreatedDate),
+			IsDefaultVersion: aws.Bool(version.Version == document.DefaultVersion),
+			Status:           aws.String(ssm.DocumentStatusActive),
+		}
+		if len(version.VersionName) > 0 {
+			info.VersionName = aws.String(version.VersionName)
+		}
+		if len(version.ReviewStatus) > 0 {
+			info.ReviewStatus = aws.String(version.ReviewStatus)
+		}
+		output.DocumentVersions = append(output.DocumentVersions, info)
+	}
+	return output, nil
+}
+
+// ListDocuments return documents matching filters, supported keys are
+// Owner (Self), Name, DocumentType and tag:<key>. All results are returned in a single page.
+func (f *Fake) ListDocuments(input *ssm.ListDocumentsInput) (*ssm.ListDocumentsOutput, error) {
+	return f.ListDocumentsWithContext(aws.BackgroundContext(), input)
+}
+
+// ListDocumentsWithContext return documents matching filters
+func (f *Fake) ListDocumentsWithContext(ctx aws.Context, input *ssm.ListDocumentsInput, opts ...request.Option) (*ssm.ListDocumentsOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.begin("ListDocuments", input); err != nil {
+		return nil, err
+	}
+
+	names := []string{}
+	for name := range f.state.Documents {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	output := &ssm.ListDocumentsOutput{
+		DocumentIdentifiers: []*ssm.DocumentIdentifier{},
+	}
+	for _, name := range names {
+		document := f.state.Documents[name]
+		if !f.matchFilters(document, input.Filters) {
+			continue
+		}
+
+		version := document.GetVersion("")
+		output.DocumentIdentifiers = append(output.DocumentIdentifiers, &ssm.DocumentIdentifier{
+			Name:            aws.String(document.Name),
+			DocumentType:    aws.String(document.Type),
+			DocumentFormat:  aws.String(version.Format),
+			DocumentVersion: aws.String(version.Version),
+			Owner:           aws.String(document.Owner),
+			CreatedDate:     aws.Time(version.CreatedDate),
+			Tags:            tagList(document.Tags),
+		})
+	}
+	return output, nil
+}
+
+// matchFilters check if document match all filters, must be called with lock held
+func (f *Fake) matchFilters(document *Document, filters []*ssm.DocumentKeyValuesFilter) bool {
+	for _, filter := range filters {
+		key := aws.StringValue(filter.Key)
+		values := aws.StringValueSlice(filter.Values)
+
+		var value string
+		var present bool
+		switch {
+		case key == "Owner":
+			value, present = document.Owner, true
+			if document.Owner == f.AccountID {
+				value = "Self"
+			}
+		case key == "Name":
+			value, present = document.Name, true
+		case key == "DocumentType":
+			value, present = document.Type, true
+		case strings.HasPrefix(key, "tag:"):
+			value, present = document.Tags[strings.TrimPrefix(key, "tag:")]
+		default:
+			continue
+		}
+
+		if !present {
+			return false
+		}
+		if len(values) == 0 {
+			continue
+		}
+		matched := false
+		for _, v := range values {
+			if v == value {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// DeleteDocument delete a document and all its versions, shared and associated documents cannot be deleted
+func (f *Fake) DeleteDocument(input *ssm.DeleteDocumentInput) (*ssm.DeleteDocumentOutput, error) {
+	return f.DeleteDocumentWithContext(aws.BackgroundContext(), input)
+}
+
+// DeleteDocumentWithContext delete a document and all its versions, shared and associated documents
+// cannot be deleted and ApplicationConfigurationSchema documents require Force
+func (f *Fake) DeleteDocumentWithContext(ctx aws.Context, input *ssm.DeleteDocumentInput, opts ...request.Option) (*ssm.DeleteDocumentOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.begin("DeleteDocument", input); err != nil {
+		return nil, err
+	}
+
+	document, err := f.find(input.Name)
+	if err != nil {
+		return nil, err
+	}
+	if len(document.AccountIDs) > 0 {
+		return nil, newError(ssm.ErrCodeInvalidDocumentOperation, "You must stop sharing the document before you can delete it")
+	}
+	for _, association := range f.state.Associations {
+		if association.DocumentName == document.Name {
+			return nil, newError(ssm.ErrCodeAssociatedInstances, "You must disassociate a document from all managed nodes before you can delete it")
+		}
+	}
+	if document.Type == ssm.DocumentTypeApplicationConfigurationSchema && !aws.BoolValue(input.Force) {
+		return nil, newError(ssm.ErrCodeInvalidDocumentOperation, "You must specify Force to delete this document type")
+	}
+	delete(f.state.Documents, document.Name)
+
+	return &ssm.DeleteDocumentOutput{}, nil
+}
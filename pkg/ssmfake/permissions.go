@@ -0,0 +1,100 @@
+package ssmfake
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/ssm"
+)
+
+// maxAccountsPerPermissionCall is the max number of accounts that can be added or removed in a single call
+const maxAccountsPerPermissionCall = 20
+
+// DescribeDocumentPermission return the accounts the document is shared with
+func (f *Fake) DescribeDocumentPermission(input *ssm.DescribeDocumentPermissionInput) (*ssm.DescribeDocumentPermissionOutput, error) {
+	return f.DescribeDocumentPermissionWithContext(aws.BackgroundContext(), input)
+}
+
+// DescribeDocumentPermissionWithContext return the accounts the document is shared with
+func (f *Fake) DescribeDocumentPermissionWithContext(ctx aws.Context, input *ssm.DescribeDocumentPermissionInput, opts ...request.Option) (*ssm.DescribeDocumentPermissionOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.begin("DescribeDocumentPermission", input); err != nil {
+		return nil, err
+	}
+
+	document, err := f.find(input.Name)
+	if err != nil {
+		return nil, err
+	}
+	if *input.PermissionType != ssm.DocumentPermissionTypeShare {
+		return nil, newError(ssm.ErrCodeInvalidPermissionType, "Permission type must be Share")
+	}
+
+	output := &ssm.DescribeDocumentPermissionOutput{
+		AccountIds:             aws.StringSlice(append([]string{}, document.AccountIDs...)),
+		AccountSharingInfoList: []*ssm.AccountSharingInfo{},
+	}
+	for _, accountID := range document.AccountIDs {
+		output.AccountSharingInfoList = append(output.AccountSharingInfoList, &ssm.AccountSharingInfo{
+			AccountId:             aws.String(accountID),
+			SharedDocumentVersion: aws.String(document.DefaultVersion),
+		})
+	}
+	return output, nil
+}
+
+// ModifyDocumentPermission share or unshare the document, at most 20 accounts per call
+func (f *Fake) ModifyDocumentPermission(input *ssm.ModifyDocumentPermissionInput) (*ssm.ModifyDocumentPermissionOutput, error) {
+	return f.ModifyDocumentPermissionWithContext(aws.BackgroundContext(), input)
+}
+
+// ModifyDocumentPermissionWithContext share or unshare the document, at most 20 accounts per call
+func (f *Fake) ModifyDocumentPermissionWithContext(ctx aws.Context, input *ssm.ModifyDocumentPermissionInput, opts ...request.Option) (*ssm.ModifyDocumentPermissionOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.begin("ModifyDocumentPermission", input); err != nil {
+		return nil, err
+	}
+
+	document, err := f.find(input.Name)
+	if err != nil {
+		return nil, err
+	}
+	if *input.PermissionType != ssm.DocumentPermissionTypeShare {
+		return nil, newError(ssm.ErrCodeInvalidPermissionType, "Permission type must be Share")
+	}
+	if len(input.AccountIdsToAdd) > maxAccountsPerPermissionCall || len(input.AccountIdsToRemove) > maxAccountsPerPermissionCall {
+		return nil, newError(ssm.ErrCodeDocumentPermissionLimit, "Document permission limit exceeded")
+	}
+
+	// Remove accounts
+	toRemove := map[string]bool{}
+	for _, accountID := range input.AccountIdsToRemove {
+		toRemove[*accountID] = true
+	}
+	accountIDs := []string{}
+	for _, accountID := range document.AccountIDs {
+		if !toRemove[accountID] {
+			accountIDs = append(accountIDs, accountID)
+		}
+	}
+
+	// Add accounts
+	for _, accountID := range input.AccountIdsToAdd {
+		found := false
+		for _, current := range accountIDs {
+			if current == *accountID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			accountIDs = append(accountIDs, *accountID)
+		}
+	}
+	document.AccountIDs = accountIDs
+
+	return &ssm.ModifyDocumentPermissionOutput{}, nil
+}
@@ -0,0 +1,137 @@
+package ssmfake
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/ssm"
+)
+
+// reviewStatus return the version review status after a review action
+func reviewStatus(action string) string {
+	switch action {
+	case ssm.DocumentReviewActionSendForReview, ssm.DocumentReviewActionUpdateReview:
+		return ssm.ReviewStatusPending
+	case ssm.DocumentReviewActionApprove:
+		return ssm.ReviewStatusApproved
+	case ssm.DocumentReviewActionReject:
+		return ssm.ReviewStatusRejected
+	default:
+		return ssm.ReviewStatusNotReviewed
+	}
+}
+
+// UpdateDocumentMetadata send a document version for review, approve or reject it
+func (f *Fake) UpdateDocumentMetadata(input *ssm.UpdateDocumentMetadataInput) (*ssm.UpdateDocumentMetadataOutput, error) {
+	return f.UpdateDocumentMetadataWithContext(aws.BackgroundContext(), input)
+}
+
+// UpdateDocumentMetadataWithContext send a document version for review, approve or reject it,
+// only change templates support reviews and only a pending version can be approved or rejected
+func (f *Fake) UpdateDocumentMetadataWithContext(ctx aws.Context, input *ssm.UpdateDocumentMetadataInput, opts ...request.Option) (*ssm.UpdateDocumentMetadataOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.begin("UpdateDocumentMetadata", input); err != nil {
+		return nil, err
+	}
+
+	document, err := f.find(input.Name)
+	if err != nil {
+		return nil, err
+	}
+	if document.Type != ssm.DocumentTypeAutomationChangeTemplate {
+		return nil, newError(ssm.ErrCodeInvalidDocumentOperation, fmt.Sprintf("DocumentReviews metadata is supported only by %s documents", ssm.DocumentTypeAutomationChangeTemplate))
+	}
+	version := document.GetVersion(aws.StringValue(input.DocumentVersion))
+	if version == nil {
+		return nil, newError(ssm.ErrCodeInvalidDocumentVersion, fmt.Sprintf("Version %s does not exist", aws.StringValue(input.DocumentVersion)))
+	}
+
+	// Check review transition
+	action := aws.StringValue(input.DocumentReviews.Action)
+	pending := version.ReviewStatus == ssm.ReviewStatusPending
+	switch action {
+	case ssm.DocumentReviewActionSendForReview:
+		if pending || version.ReviewStatus == ssm.ReviewStatusApproved {
+			return nil, newError(ssm.ErrCodeInvalidDocumentOperation, fmt.Sprintf("Version %s is already %s", version.Version, version.ReviewStatus))
+		}
+	case ssm.DocumentReviewActionUpdateReview, ssm.DocumentReviewActionApprove, ssm.DocumentReviewActionReject:
+		if !pending {
+			return nil, newError(ssm.ErrCodeInvalidDocumentOperation, fmt.Sprintf("Version %s has no pending review", version.Version))
+		}
+	}
+
+	// Record review
+	review := Review{
+		Action:   action,
+		Reviewer: f.CallerArn(),
+		Date:     time.Now().UTC(),
+	}
+	for _, comment := range input.DocumentReviews.Comment {
+		review.Comment = aws.StringValue(comment.Content)
+	}
+	version.Reviews = append(version.Reviews, review)
+	version.ReviewStatus = reviewStatus(action)
+	if action == ssm.DocumentReviewActionSendForReview {
+		version.Author = review.Reviewer
+	}
+
+	return &ssm.UpdateDocumentMetadataOutput{}, nil
+}
+
+// ListDocumentMetadataHistory return the review history of a document version
+func (f *Fake) ListDocumentMetadataHistory(input *ssm.ListDocumentMetadataHistoryInput) (*ssm.ListDocumentMetadataHistoryOutput, error) {
+	return f.ListDocumentMetadataHistoryWithContext(aws.BackgroundContext(), input)
+}
+
+// ListDocumentMetadataHistoryWithContext return the review history of a document version
+func (f *Fake) ListDocumentMetadataHistoryWithContext(ctx aws.Context, input *ssm.ListDocumentMetadataHistoryInput, opts ...request.Option) (*ssm.ListDocumentMetadataHistoryOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.begin("ListDocumentMetadataHistory", input); err != nil {
+		return nil, err
+	}
+
+	document, err := f.find(input.Name)
+	if err != nil {
+		return nil, err
+	}
+	version := document.GetVersion(aws.StringValue(input.DocumentVersion))
+	if version == nil {
+		return nil, newError(ssm.ErrCodeInvalidDocumentVersion, fmt.Sprintf("Version %s does not exist", aws.StringValue(input.DocumentVersion)))
+	}
+
+	author := version.Author
+	if len(author) == 0 {
+		author = document.Owner
+	}
+
+	responses := []*ssm.DocumentReviewerResponseSource{}
+	for _, review := range version.Reviews {
+		response := &ssm.DocumentReviewerResponseSource{
+			Reviewer:     aws.String(review.Reviewer),
+			ReviewStatus: aws.String(reviewStatus(review.Action)),
+			CreateTime:   aws.Time(review.Date),
+			UpdatedTime:  aws.Time(review.Date),
+		}
+		if len(review.Comment) > 0 {
+			response.Comment = []*ssm.DocumentReviewCommentSource{
+				{Type: aws.String(ssm.DocumentReviewCommentTypeComment), Content: aws.String(review.Comment)},
+			}
+		}
+		responses = append(responses, response)
+	}
+
+	return &ssm.ListDocumentMetadataHistoryOutput{
+		Name:            aws.String(document.Name),
+		DocumentVersion: aws.String(version.Version),
+		Author:          aws.String(author),
+		Metadata: &ssm.DocumentMetadataResponseInfo{
+			ReviewerResponse: responses,
+		},
+	}, nil
+}
@@ -0,0 +1,373 @@
+package ssmfake
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/private/protocol/json/jsonutil"
+	"github.com/aws/aws-sdk-go/service/ssm"
+)
+
+// targetPrefix is the X-Amz-Target header prefix of SSM JSON protocol requests
+const targetPrefix = "AmazonSSM."
+
+// operation decode the request body, call the fake and return the output
+type operation func(f *Fake, body []byte) (interface{}, error)
+
+// decode unmarshal a JSON protocol request body into input
+func decode(body []byte, input interface{}) error {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return nil
+	}
+	err := jsonutil.UnmarshalJSON(input, bytes.NewReader(body))
+	if err != nil {
+		return newError("SerializationException", err.Error())
+	}
+	return nil
+}
+
+var operations = map[string]operation{
+	"CreateDocument": func(f *Fake, body []byte) (interface{}, error) {
+		input := &ssm.CreateDocumentInput{}
+		if err := decode(body, input); err != nil {
+			return nil, err
+		}
+		return f.CreateDocument(input)
+	},
+	"UpdateDocument": func(f *Fake, body []byte) (interface{}, error) {
+		input := &ssm.UpdateDocumentInput{}
+		if err := decode(body, input); err != nil {
+			return nil, err
+		}
+		return f.UpdateDocument(input)
+	},
+	"UpdateDocumentDefaultVersion": func(f *Fake, body []byte) (interface{}, error) {
+		input := &ssm.UpdateDocumentDefaultVersionInput{}
+		if err := decode(body, input); err != nil {
+			return nil, err
+		}
+		return f.UpdateDocumentDefaultVersion(input)
+	},
+	"GetDocument": func(f *Fake, body []byte) (interface{}, error) {
+		input := &ssm.GetDocumentInput{}
+		if err := decode(body, input); err != nil {
+			return nil, err
+		}
+		return f.GetDocument(input)
+	},
+	"DescribeDocument": func(f *Fake, body []byte) (interface{}, error) {
+		input := &ssm.DescribeDocumentInput{}
+		if err := decode(body, input); err != nil {
+			return nil, err
+		}
+		return f.DescribeDocument(input)
+	},
+	"ListDocumentVersions": func(f *Fake, body []byte) (interface{}, error) {
+		input := &ssm.ListDocumentVersionsInput{}
+		if err := decode(body, input); err != nil {
+			return nil, err
+		}
+		return f.ListDocumentVersions(input)
+	},
+	"ListDocuments": func(f *Fake, body []byte) (interface{}, error) {
+		input := &ssm.ListDocumentsInput{}
+		if err := decode(body, input); err != nil {
+			return nil, err
+		}
+		return f.ListDocuments(input)
+	},
+	"DeleteDocument": func(f *Fake, body []byte) (interface{}, error) {
+		input := &ssm.DeleteDocumentInput{}
+		if err := decode(body, input); err != nil {
+			return nil, err
+		}
+		return f.DeleteDocument(input)
+	},
+	"DescribeDocumentPermission": func(f *Fake, body []byte) (interface{}, error) {
+		input := &ssm.DescribeDocumentPermissionInput{}
+		if err := decode(body, input); err != nil {
+			return nil, err
+		}
+		return f.DescribeDocumentPermission(input)
+	},
+	"ModifyDocumentPermission": func(f *Fake, body []byte) (interface{}, error) {
+		input := &ssm.ModifyDocumentPermissionInput{}
+		if err := decode(body, input); err != nil {
+			return nil, err
+		}
+		return f.ModifyDocumentPermission(input)
+	},
+	"ListTagsForResource": func(f *Fake, body []byte) (interface{}, error) {
+		input := &ssm.ListTagsForResourceInput{}
+		if err := decode(body, input); err != nil {
+			return nil, err
+		}
+		return f.ListTagsForResource(input)
+	},
+	"AddTagsToResource": func(f *Fake, body []byte) (interface{}, error) {
+		input := &ssm.AddTagsToResourceInput{}
+		if err := decode(body, input); err != nil {
+			return nil, err
+		}
+		return f.AddTagsToResource(input)
+	},
+	"RemoveTagsFromResource": func(f *Fake, body []byte) (interface{}, error) {
+		input := &ssm.RemoveTagsFromResourceInput{}
+		if err := decode(body, input); err != nil {
+			return nil, err
+		}
+		return f.RemoveTagsFromResource(input)
+	},
+	"UpdateDocumentMetadata": func(f *Fake, body []byte) (interface{}, error) {
+		input := &ssm.UpdateDocumentMetadataInput{}
+		if err := decode(body, input); err != nil {
+			return nil, err
+		}
+		return f.UpdateDocumentMetadata(input)
+	},
+	"ListDocumentMetadataHistory": func(f *Fake, body []byte) (interface{}, error) {
+		input := &ssm.ListDocumentMetadataHistoryInput{}
+		if err := decode(body, input); err != nil {
+			return nil, err
+		}
+		return f.ListDocumentMetadataHistory(input)
+	},
+	"CreateAssociation": func(f *Fake, body []byte) (interface{}, error) {
+		input := &ssm.CreateAssociationInput{}
+		if err := decode(body, input); err != nil {
+			return nil, err
+		}
+		return f.CreateAssociation(input)
+	},
+	"UpdateAssociation": func(f *Fake, body []byte) (interface{}, error) {
+		input := &ssm.UpdateAssociationInput{}
+		if err := decode(body, input); err != nil {
+			return nil, err
+		}
+		return f.UpdateAssociation(input)
+	},
+	"DescribeAssociation": func(f *Fake, body []byte) (interface{}, error) {
+		input := &ssm.DescribeAssociationInput{}
+		if err := decode(body, input); err != nil {
+			return nil, err
+		}
+		return f.DescribeAssociation(input)
+	},
+	"DeleteAssociation": func(f *Fake, body []byte) (interface{}, error) {
+		input := &ssm.DeleteAssociationInput{}
+		if err := decode(body, input); err != nil {
+			return nil, err
+		}
+		return f.DeleteAssociation(input)
+	},
+	"ListAssociations": func(f *Fake, body []byte) (interface{}, error) {
+		input := &ssm.ListAssociationsInput{}
+		if err := decode(body, input); err != nil {
+			return nil, err
+		}
+		return f.ListAssociations(input)
+	},
+	"CreateMaintenanceWindow": func(f *Fake, body []byte) (interface{}, error) {
+		input := &ssm.CreateMaintenanceWindowInput{}
+		if err := decode(body, input); err != nil {
+			return nil, err
+		}
+		return f.CreateMaintenanceWindow(input)
+	},
+	"DescribeMaintenanceWindows": func(f *Fake, body []byte) (interface{}, error) {
+		input := &ssm.DescribeMaintenanceWindowsInput{}
+		if err := decode(body, input); err != nil {
+			return nil, err
+		}
+		return f.DescribeMaintenanceWindows(input)
+	},
+	"RegisterTaskWithMaintenanceWindow": func(f *Fake, body []byte) (interface{}, error) {
+		input := &ssm.RegisterTaskWithMaintenanceWindowInput{}
+		if err := decode(body, input); err != nil {
+			return nil, err
+		}
+		return f.RegisterTaskWithMaintenanceWindow(input)
+	},
+	"UpdateMaintenanceWindowTask": func(f *Fake, body []byte) (interface{}, error) {
+		input := &ssm.UpdateMaintenanceWindowTaskInput{}
+		if err := decode(body, input); err != nil {
+			return nil, err
+		}
+		return f.UpdateMaintenanceWindowTask(input)
+	},
+	"GetMaintenanceWindowTask": func(f *Fake, body []byte) (interface{}, error) {
+		input := &ssm.GetMaintenanceWindowTaskInput{}
+		if err := decode(body, input); err != nil {
+			return nil, err
+		}
+		return f.GetMaintenanceWindowTask(input)
+	},
+	"DescribeMaintenanceWindowTasks": func(f *Fake, body []byte) (interface{}, error) {
+		input := &ssm.DescribeMaintenanceWindowTasksInput{}
+		if err := decode(body, input); err != nil {
+			return nil, err
+		}
+		return f.DescribeMaintenanceWindowTasks(input)
+	},
+	"DeregisterTaskFromMaintenanceWindow": func(f *Fake, body []byte) (interface{}, error) {
+		input := &ssm.DeregisterTaskFromMaintenanceWindowInput{}
+		if err := decode(body, input); err != nil {
+			return nil, err
+		}
+		return f.DeregisterTaskFromMaintenanceWindow(input)
+	},
+}
+
+// readOnlyPrefixes identify operations that do not change the state
+var readOnlyPrefixes = []string{"Get", "Describe", "List"}
+
+// Server expose the fake over HTTP speaking the SSM JSON protocol,
+// STS GetCallerIdentity is also supported to resolve the caller account
+type Server struct {
+	mu        sync.Mutex
+	fake      *Fake
+	stateFile string
+}
+
+// NewServer creates a new Server, when stateFile is set the state is loaded
+// from it and saved after each change
+func NewServer(fake *Fake, stateFile string) (*Server, error) {
+	server := &Server{
+		fake:      fake,
+		stateFile: stateFile,
+	}
+
+	// Load state from file
+	if len(stateFile) > 0 {
+		content, err := ioutil.ReadFile(stateFile)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		if err == nil {
+			state := &State{}
+			err = json.Unmarshal(content, state)
+			if err != nil {
+				return nil, fmt.Errorf("Cannot parse state file %s: %s", stateFile, err)
+			}
+			fake.SetState(state)
+		}
+	}
+
+	return server, nil
+}
+
+// ServeHTTP handle SSM and STS requests
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Check for STS query protocol request
+	target := r.Header.Get("X-Amz-Target")
+	if len(target) == 0 {
+		s.serveSTS(w, body)
+		return
+	}
+
+	// Search SSM operation
+	name := strings.TrimPrefix(target, targetPrefix)
+	operation, ok := operations[name]
+	if !strings.HasPrefix(target, targetPrefix) || !ok {
+		writeError(w, newError("UnknownOperationException", fmt.Sprintf("Operation %s is not supported", target)))
+		return
+	}
+
+	// Execute operation
+	output, err := operation(s.fake, body)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	// Persist state after changes
+	if !isReadOnly(name) {
+		err = s.save()
+		if err != nil {
+			writeError(w, awserr.NewRequestFailure(awserr.New("InternalServerError", err.Error(), nil), 500, "fake-request-id"))
+			return
+		}
+	}
+
+	content, err := jsonutil.BuildJSON(output)
+	if err != nil {
+		writeError(w, awserr.NewRequestFailure(awserr.New("InternalServerError", err.Error(), nil), 500, "fake-request-id"))
+		return
+	}
+	w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+	w.Header().Set("X-Amzn-Requestid", "fake-request-id")
+	w.Write(content)
+}
+
+// serveSTS handle STS GetCallerIdentity returning the fake account
+func (s *Server) serveSTS(w http.ResponseWriter, body []byte) {
+	values, err := url.ParseQuery(string(body))
+	if err != nil || values.Get("Action") != "GetCallerIdentity" {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `<ErrorResponse><Error><Type>Sender</Type><Code>InvalidAction</Code><Message>Only GetCallerIdentity is supported</Message></Error><RequestId>fake-request-id</RequestId></ErrorResponse>`)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/xml")
+	fmt.Fprintf(w, `<GetCallerIdentityResponse xmlns="https://sts.amazonaws.com/doc/2011-06-15/"><GetCallerIdentityResult><Arn>%s</Arn><UserId>AIDASSMFAKE</UserId><Account>%s</Account></GetCallerIdentityResult><ResponseMetadata><RequestId>fake-request-id</RequestId></ResponseMetadata></GetCallerIdentityResponse>`, s.fake.CallerArn(), s.fake.AccountID)
+}
+
+// save write state to file, if configured
+func (s *Server) save() error {
+	if len(s.stateFile) == 0 {
+		return nil
+	}
+
+	content, err := json.MarshalIndent(s.fake.State(), "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(s.stateFile, content, 0644)
+}
+
+// isReadOnly check if operation does not change the state
+func isReadOnly(name string) bool {
+	for _, prefix := range readOnlyPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// writeError write an error using the JSON protocol error format
+func writeError(w http.ResponseWriter, err error) {
+	code, message, status := "InternalServerError", err.Error(), http.StatusInternalServerError
+	if awsErr, ok := err.(awserr.Error); ok {
+		code, message, status = awsErr.Code(), awsErr.Message(), http.StatusBadRequest
+	}
+	if requestErr, ok := err.(awserr.RequestFailure); ok {
+		status = requestErr.StatusCode()
+	}
+
+	content, _ := json.Marshal(map[string]string{
+		"__type":  code,
+		"message": message,
+	})
+	w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+	w.Header().Set("X-Amzn-Errortype", code)
+	w.WriteHeader(status)
+	w.Write(content)
+}
@@ -0,0 +1,74 @@
+package ssmfake
+
+import (
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/aws/aws-sdk-go/service/sts"
+)
+
+func newTestSession(t *testing.T, url string) *session.Session {
+	t.Helper()
+
+	return session.Must(session.NewSession(&aws.Config{
+		Region:      aws.String("us-east-1"),
+		Endpoint:    aws.String(url),
+		Credentials: credentials.NewStaticCredentials("id", "secret", ""),
+		MaxRetries:  aws.Int(0),
+	}))
+}
+
+func TestServer(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "state.json")
+	server, err := NewServer(New(), stateFile)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+
+	ses := newTestSession(t, httpServer.URL)
+	client := ssm.New(ses)
+
+	// Create document using the real SDK client
+	_, err = client.CreateDocument(&ssm.CreateDocumentInput{
+		Name:    aws.String("Test"),
+		Content: aws.String(`{"schemaVersion":"2.2"}`),
+		Tags:    []*ssm.Tag{{Key: aws.String("Team"), Value: aws.String("ops")}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// Check errors are returned with their code
+	_, err = client.UpdateDocument(&ssm.UpdateDocumentInput{
+		Name:            aws.String("Test"),
+		Content:         aws.String(`{"schemaVersion":"2.2"}`),
+		DocumentVersion: aws.String("$LATEST"),
+	})
+	if errorCode(err) != ssm.ErrCodeDuplicateDocumentContent {
+		t.Errorf("expected duplicate content error, got %v", err)
+	}
+
+	// Check caller identity
+	identity, err := sts.New(ses).GetCallerIdentity(&sts.GetCallerIdentityInput{})
+	if err != nil || *identity.Account != "123456789012" {
+		t.Errorf("unexpected identity: %v %v", identity, err)
+	}
+
+	// Check state is reloaded from file
+	reloaded := New()
+	_, err = NewServer(reloaded, stateFile)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	document := reloaded.Document("Test")
+	if document == nil || document.Tags["Team"] != "ops" || document.DefaultVersion != "1" {
+		t.Errorf("unexpected reloaded document: %+v", document)
+	}
+}
@@ -0,0 +1,233 @@
+// Package ssmfake provide an in-memory implementation of the SSM document APIs,
+// useful to test code that manage documents without calling AWS.
+package ssmfake
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/ssm/ssmiface"
+)
+
+// ErrCodeThrottling is the error code returned for throttled requests
+const ErrCodeThrottling = "ThrottlingException"
+
+// Version of a document
+type Version struct {
+	Version      string    `json:"version"`
+	VersionName  string    `json:"versionName,omitempty"`
+	Content      string    `json:"content"`
+	Format       string    `json:"format"`
+	Hash         string    `json:"hash"`
+	CreatedDate  time.Time `json:"createdDate"`
+	ReviewStatus string    `json:"reviewStatus,omitempty"`
+	Author       string    `json:"author,omitempty"`
+	Reviews      []Review  `json:"reviews,omitempty"`
+}
+
+// Review is an action taken on a document version review
+type Review struct {
+	Action   string    `json:"action"`
+	Comment  string    `json:"comment,omitempty"`
+	Reviewer string    `json:"reviewer"`
+	Date     time.Time `json:"date"`
+}
+
+// Requirement of a document
+type Requirement struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+}
+
+// Document stored by the fake
+type Document struct {
+	Name           string            `json:"name"`
+	Type           string            `json:"type"`
+	Owner          string            `json:"owner"`
+	DefaultVersion string            `json:"defaultVersion"`
+	Versions       []*Version        `json:"versions"`
+	Tags           map[string]string `json:"tags"`
+	AccountIDs     []string          `json:"accountIds"`
+	Requires       []Requirement     `json:"requires,omitempty"`
+}
+
+// LatestVersion return the last created version
+func (d *Document) LatestVersion() *Version {
+	return d.Versions[len(d.Versions)-1]
+}
+
+// GetVersion return the version by number, empty string return the default one
+func (d *Document) GetVersion(version string) *Version {
+	if len(version) == 0 {
+		version = d.DefaultVersion
+	}
+	if version == "$LATEST" {
+		return d.LatestVersion()
+	}
+	if version == "$DEFAULT" {
+		version = d.DefaultVersion
+	}
+	for _, v := range d.Versions {
+		if v.Version == version {
+			return v
+		}
+	}
+	return nil
+}
+
+// State hold all the data managed by the fake
+type State struct {
+	Documents          map[string]*Document          `json:"documents"`
+	Associations       map[string]*Association       `json:"associations,omitempty"`
+	MaintenanceWindows map[string]*MaintenanceWindow `json:"maintenanceWindows,omitempty"`
+}
+
+// Fake is an in-memory SSM client, APIs not implemented panic
+type Fake struct {
+	ssmiface.SSMAPI
+
+	mu       sync.Mutex
+	state    *State
+	throttle int
+	calls    map[string]int
+
+	// AccountID used as owner of created documents
+	AccountID string
+	// Region returned in ARNs
+	Region string
+	// CallerName is the IAM user name of the caller, author and reviewer of document reviews
+	CallerName string
+}
+
+// New creates a new empty Fake
+func New() *Fake {
+	return &Fake{
+		state: &State{
+			Documents:          map[string]*Document{},
+			Associations:       map[string]*Association{},
+			MaintenanceWindows: map[string]*MaintenanceWindow{},
+		},
+		calls:      map[string]int{},
+		AccountID:  "123456789012",
+		Region:     "us-east-1",
+		CallerName: "ssmfake",
+	}
+}
+
+// CallerArn return the ARN of the caller identity
+func (f *Fake) CallerArn() string {
+	return fmt.Sprintf("arn:aws:iam::%s:user/%s", f.AccountID, f.CallerName)
+}
+
+// Throttle make the next count calls fail with a throttling error
+func (f *Fake) Throttle(count int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.throttle = count
+}
+
+// Calls return how many times the operation was called
+func (f *Fake) Calls(operation string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.calls[operation]
+}
+
+// Document return a stored document, nil if not found
+func (f *Fake) Document(name string) *Document {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.state.Documents[name]
+}
+
+// PutDocument store a document as is, useful to prepare test scenarios
+func (f *Fake) PutDocument(document *Document) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if document.Tags == nil {
+		document.Tags = map[string]string{}
+	}
+	f.state.Documents[document.Name] = document
+}
+
+// State return the current state
+func (f *Fake) State() *State {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.state
+}
+
+// SetState replace the current state
+func (f *Fake) SetState(state *State) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if state.Documents == nil {
+		state.Documents = map[string]*Document{}
+	}
+	if state.Associations == nil {
+		state.Associations = map[string]*Association{}
+	}
+	if state.MaintenanceWindows == nil {
+		state.MaintenanceWindows = map[string]*MaintenanceWindow{}
+	}
+	f.state = state
+}
+
+// begin register the call and check throttling, must be called with lock held
+func (f *Fake) begin(operation string, input interface{ Validate() error }) error {
+	f.calls[operation]++
+
+	if f.throttle > 0 {
+		f.throttle--
+		return newError(ErrCodeThrottling, "Rate exceeded")
+	}
+
+	return input.Validate()
+}
+
+// unvalidated is used to begin operations whose input has no required fields
+type unvalidated struct{}
+
+// Validate always succeed
+func (unvalidated) Validate() error {
+	return nil
+}
+
+// find return the document or a not found error, must be called with lock held
+func (f *Fake) find(name *string) (*Document, error) {
+	if name == nil {
+		return nil, newError("InvalidDocument", "Document name is required")
+	}
+	document, ok := f.state.Documents[*name]
+	if !ok {
+		return nil, newError("InvalidDocument", fmt.Sprintf("Document %s does not exist", *name))
+	}
+	return document, nil
+}
+
+// newError creates an AWS request error with a client error status code
+func newError(code string, message string) error {
+	return awserr.NewRequestFailure(awserr.New(code, message, nil), 400, "fake-request-id")
+}
+
+// newID generate an ID in UUID format from seed
+func newID(seed string) string {
+	sum := hash(seed)
+	return fmt.Sprintf("%s-%s-%s-%s-%s", sum[0:8], sum[8:12], sum[12:16], sum[16:20], sum[20:32])
+}
+
+// hash return the sha256 hash of content
+func hash(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,156 @@
+package ssmfake
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/ssm"
+)
+
+func errorCode(err error) string {
+	if awsErr, ok := err.(awserr.Error); ok {
+		return awsErr.Code()
+	}
+	return ""
+}
+
+func TestDocumentLifecycle(t *testing.T) {
+	fake := New()
+
+	_, err := fake.CreateDocument(&ssm.CreateDocumentInput{
+		Name:    aws.String("Test"),
+		Content: aws.String(`{"schemaVersion":"2.2"}`),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	_, err = fake.CreateDocument(&ssm.CreateDocumentInput{
+		Name:    aws.String("Test"),
+		Content: aws.String(`{"schemaVersion":"2.2"}`),
+	})
+	if errorCode(err) != ssm.ErrCodeDocumentAlreadyExists {
+		t.Errorf("expected already exists error, got %v", err)
+	}
+
+	_, err = fake.UpdateDocument(&ssm.UpdateDocumentInput{
+		Name:            aws.String("Test"),
+		Content:         aws.String(`{"schemaVersion":"2.2"}`),
+		DocumentVersion: aws.String("$LATEST"),
+	})
+	if errorCode(err) != ssm.ErrCodeDuplicateDocumentContent {
+		t.Errorf("expected duplicate content error, got %v", err)
+	}
+
+	res, err := fake.UpdateDocument(&ssm.UpdateDocumentInput{
+		Name:            aws.String("Test"),
+		Content:         aws.String(`{"schemaVersion":"2.2","description":"new"}`),
+		DocumentVersion: aws.String("$LATEST"),
+	})
+	if err != nil || *res.DocumentDescription.DocumentVersion != "2" || *res.DocumentDescription.DefaultVersion != "1" {
+		t.Fatalf("unexpected update result: %v %v", res, err)
+	}
+
+	_, err = fake.ModifyDocumentPermission(&ssm.ModifyDocumentPermissionInput{
+		Name:            aws.String("Test"),
+		PermissionType:  aws.String("Share"),
+		AccountIdsToAdd: aws.StringSlice([]string{"111111111111"}),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	_, err = fake.DeleteDocument(&ssm.DeleteDocumentInput{Name: aws.String("Test")})
+	if errorCode(err) != ssm.ErrCodeInvalidDocumentOperation {
+		t.Errorf("expected shared document delete error, got %v", err)
+	}
+}
+
+func TestDeleteAssociatedDocument(t *testing.T) {
+	fake := New()
+
+	_, err := fake.CreateDocument(&ssm.CreateDocumentInput{
+		Name:    aws.String("Test"),
+		Content: aws.String(`{"schemaVersion":"2.2"}`),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	res, err := fake.CreateAssociation(&ssm.CreateAssociationInput{
+		Name:    aws.String("Test"),
+		Targets: []*ssm.Target{{Key: aws.String("InstanceIds"), Values: aws.StringSlice([]string{"*"})}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	_, err = fake.DeleteDocument(&ssm.DeleteDocumentInput{Name: aws.String("Test")})
+	if errorCode(err) != ssm.ErrCodeAssociatedInstances {
+		t.Errorf("expected associated document delete error, got %v", err)
+	}
+
+	_, err = fake.DeleteAssociation(&ssm.DeleteAssociationInput{AssociationId: res.AssociationDescription.AssociationId})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	_, err = fake.DeleteDocument(&ssm.DeleteDocumentInput{Name: aws.String("Test")})
+	if err != nil {
+		t.Errorf("unexpected delete error: %s", err)
+	}
+}
+
+func TestDuplicateVersionName(t *testing.T) {
+	fake := New()
+
+	_, err := fake.CreateDocument(&ssm.CreateDocumentInput{
+		Name:         aws.String("Package"),
+		DocumentType: aws.String(ssm.DocumentTypePackage),
+		VersionName:  aws.String("1.0.0"),
+		Content:      aws.String(`{"schemaVersion":"2.0","version":"1.0.0"}`),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	_, err = fake.UpdateDocument(&ssm.UpdateDocumentInput{
+		Name:            aws.String("Package"),
+		VersionName:     aws.String("1.0.0"),
+		Content:         aws.String(`{"schemaVersion":"2.0","version":"1.0.0","publisher":"new"}`),
+		DocumentVersion: aws.String("$LATEST"),
+	})
+	if errorCode(err) != ssm.ErrCodeDuplicateDocumentVersionName {
+		t.Errorf("expected duplicate version name error, got %v", err)
+	}
+}
+
+func TestParameterValidation(t *testing.T) {
+	fake := New()
+	fake.PutDocument(&Document{Name: "Test", Versions: []*Version{{Version: "1"}}, DefaultVersion: "1"})
+
+	_, err := fake.ModifyDocumentPermission(&ssm.ModifyDocumentPermissionInput{
+		Name: aws.String("Test"),
+	})
+	if errorCode(err) != request.InvalidParameterErrCode {
+		t.Errorf("expected invalid parameter error, got %v", err)
+	}
+}
+
+func TestThrottle(t *testing.T) {
+	fake := New()
+	fake.Throttle(1)
+
+	_, err := fake.ListDocuments(&ssm.ListDocumentsInput{})
+	if errorCode(err) != ErrCodeThrottling {
+		t.Errorf("expected throttling error, got %v", err)
+	}
+
+	_, err = fake.ListDocuments(&ssm.ListDocumentsInput{})
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	if fake.Calls("ListDocuments") != 2 {
+		t.Errorf("expected 2 calls, got %d", fake.Calls("ListDocuments"))
+	}
+}
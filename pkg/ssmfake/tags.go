@@ -0,0 +1,113 @@
+package ssmfake
+
+import (
+	"sort"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/ssm"
+)
+
+// tagList convert tags map in a sorted list
+func tagList(tags map[string]string) []*ssm.Tag {
+	keys := []string{}
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	list := []*ssm.Tag{}
+	for _, key := range keys {
+		list = append(list, &ssm.Tag{
+			Key:   aws.String(key),
+			Value: aws.String(tags[key]),
+		})
+	}
+	return list
+}
+
+// findTagged return the tagged document, only Document resource type is supported
+func (f *Fake) findTagged(resourceType *string, resourceID *string) (*Document, error) {
+	if *resourceType != ssm.ResourceTypeForTaggingDocument {
+		return nil, newError(ssm.ErrCodeInvalidResourceType, "Only Document resource type is supported")
+	}
+	document, ok := f.state.Documents[*resourceID]
+	if !ok {
+		return nil, newError(ssm.ErrCodeInvalidResourceId, "Resource not found")
+	}
+	return document, nil
+}
+
+// ListTagsForResource return document tags
+func (f *Fake) ListTagsForResource(input *ssm.ListTagsForResourceInput) (*ssm.ListTagsForResourceOutput, error) {
+	return f.ListTagsForResourceWithContext(aws.BackgroundContext(), input)
+}
+
+// ListTagsForResourceWithContext return document tags
+func (f *Fake) ListTagsForResourceWithContext(ctx aws.Context, input *ssm.ListTagsForResourceInput, opts ...request.Option) (*ssm.ListTagsForResourceOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.begin("ListTagsForResource", input); err != nil {
+		return nil, err
+	}
+
+	document, err := f.findTagged(input.ResourceType, input.ResourceId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ssm.ListTagsForResourceOutput{
+		TagList: tagList(document.Tags),
+	}, nil
+}
+
+// AddTagsToResource add or overwrite document tags
+func (f *Fake) AddTagsToResource(input *ssm.AddTagsToResourceInput) (*ssm.AddTagsToResourceOutput, error) {
+	return f.AddTagsToResourceWithContext(aws.BackgroundContext(), input)
+}
+
+// AddTagsToResourceWithContext add or overwrite document tags
+func (f *Fake) AddTagsToResourceWithContext(ctx aws.Context, input *ssm.AddTagsToResourceInput, opts ...request.Option) (*ssm.AddTagsToResourceOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.begin("AddTagsToResource", input); err != nil {
+		return nil, err
+	}
+
+	document, err := f.findTagged(input.ResourceType, input.ResourceId)
+	if err != nil {
+		return nil, err
+	}
+	for _, tag := range input.Tags {
+		document.Tags[*tag.Key] = *tag.Value
+	}
+
+	return &ssm.AddTagsToResourceOutput{}, nil
+}
+
+// RemoveTagsFromResource remove document tags by key
+func (f *Fake) RemoveTagsFromResource(input *ssm.RemoveTagsFromResourceInput) (*ssm.RemoveTagsFromResourceOutput, error) {
+	return f.RemoveTagsFromResourceWithContext(aws.BackgroundContext(), input)
+}
+
+// RemoveTagsFromResourceWithContext remove document tags by key
+func (f *Fake) RemoveTagsFromResourceWithContext(ctx aws.Context, input *ssm.RemoveTagsFromResourceInput, opts ...request.Option) (*ssm.RemoveTagsFromResourceOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.begin("RemoveTagsFromResource", input); err != nil {
+		return nil, err
+	}
+
+	document, err := f.findTagged(input.ResourceType, input.ResourceId)
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range input.TagKeys {
+		delete(document.Tags, *key)
+	}
+
+	return &ssm.RemoveTagsFromResourceOutput{}, nil
+}
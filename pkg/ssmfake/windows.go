@@ -0,0 +1,479 @@
+package ssmfake
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/ssm"
+)
+
+// WindowTask is a task registered with a maintenance window
+type WindowTask struct {
+	ID              string              `json:"id"`
+	Name            string              `json:"name,omitempty"`
+	Type            string              `json:"type"`
+	TaskArn         string              `json:"taskArn"`
+	ServiceRoleArn  string              `json:"serviceRoleArn,omitempty"`
+	Priority        int64               `json:"priority"`
+	MaxConcurrency  string              `json:"maxConcurrency,omitempty"`
+	MaxErrors       string              `json:"maxErrors,omitempty"`
+	Targets         []Target            `json:"targets,omitempty"`
+	DocumentVersion string              `json:"documentVersion,omitempty"`
+	Parameters      map[string][]string `json:"parameters,omitempty"`
+}
+
+// MaintenanceWindow stored by the fake
+type MaintenanceWindow struct {
+	ID                       string                 `json:"id"`
+	Name                     string                 `json:"name"`
+	Schedule                 string                 `json:"schedule"`
+	Duration                 int64                  `json:"duration"`
+	Cutoff                   int64                  `json:"cutoff"`
+	AllowUnassociatedTargets bool                   `json:"allowUnassociatedTargets"`
+	Tasks                    map[string]*WindowTask `json:"tasks"`
+}
+
+// windowTaskFields is the task configuration shared by register and update inputs
+type windowTaskFields struct {
+	name           *string
+	taskType       *string
+	taskArn        *string
+	serviceRoleArn *string
+	priority       *int64
+	maxConcurrency *string
+	maxErrors      *string
+	targets        []*ssm.Target
+	invocation     *ssm.MaintenanceWindowTaskInvocationParameters
+}
+
+// apply overwrite task configuration
+func (t *WindowTask) apply(fields windowTaskFields) {
+	t.Name = aws.StringValue(fields.name)
+	if fields.taskType != nil {
+		t.Type = *fields.taskType
+	}
+	if fields.taskArn != nil {
+		t.TaskArn = *fields.taskArn
+	}
+	t.ServiceRoleArn = aws.StringValue(fields.serviceRoleArn)
+	t.Priority = aws.Int64Value(fields.priority)
+	t.MaxConcurrency = aws.StringValue(fields.maxConcurrency)
+	t.MaxErrors = aws.StringValue(fields.maxErrors)
+
+	t.Targets = nil
+	for _, target := range fields.targets {
+		t.Targets = append(t.Targets, Target{
+			Key:    aws.StringValue(target.Key),
+			Values: aws.StringValueSlice(target.Values),
+		})
+	}
+
+	// Read document invocation parameters
+	t.DocumentVersion = ""
+	t.Parameters = nil
+	var parameters map[string][]*string
+	if fields.invocation != nil && fields.invocation.RunCommand != nil {
+		t.DocumentVersion = aws.StringValue(fields.invocation.RunCommand.DocumentVersion)
+		parameters = fields.invocation.RunCommand.Parameters
+	}
+	if fields.invocation != nil && fields.invocation.Automation != nil {
+		t.DocumentVersion = aws.StringValue(fields.invocation.Automation.DocumentVersion)
+		parameters = fields.invocation.Automation.Parameters
+	}
+	for name, values := range parameters {
+		if t.Parameters == nil {
+			t.Parameters = map[string][]string{}
+		}
+		t.Parameters[name] = aws.StringValueSlice(values)
+	}
+}
+
+// targets return the task targets in SSM format
+func (t *WindowTask) targets() []*ssm.Target {
+	targets := []*ssm.Target{}
+	for _, target := range t.Targets {
+		targets = append(targets, &ssm.Target{
+			Key:    aws.String(target.Key),
+			Values: aws.StringSlice(target.Values),
+		})
+	}
+	return targets
+}
+
+// invocation return the task invocation parameters in SSM format
+func (t *WindowTask) invocation() *ssm.MaintenanceWindowTaskInvocationParameters {
+	var documentVersion *string
+	if len(t.DocumentVersion) > 0 {
+		documentVersion = aws.String(t.DocumentVersion)
+	}
+	var parameters map[string][]*string
+	for name, values := range t.Parameters {
+		if parameters == nil {
+			parameters = map[string][]*string{}
+		}
+		parameters[name] = aws.StringSlice(values)
+	}
+
+	switch t.Type {
+	case ssm.MaintenanceWindowTaskTypeRunCommand:
+		return &ssm.MaintenanceWindowTaskInvocationParameters{
+			RunCommand: &ssm.MaintenanceWindowRunCommandParameters{
+				DocumentVersion: documentVersion,
+				Parameters:      parameters,
+			},
+		}
+	case ssm.MaintenanceWindowTaskTypeAutomation:
+		return &ssm.MaintenanceWindowTaskInvocationParameters{
+			Automation: &ssm.MaintenanceWindowAutomationParameters{
+				DocumentVersion: documentVersion,
+				Parameters:      parameters,
+			},
+		}
+	default:
+		return nil
+	}
+}
+
+// MaintenanceWindow return a stored maintenance window by name, nil if not found
+func (f *Fake) MaintenanceWindow(name string) *MaintenanceWindow {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, window := range f.state.MaintenanceWindows {
+		if window.Name == name {
+			return window
+		}
+	}
+	return nil
+}
+
+// findWindow return the maintenance window or a not found error, must be called with lock held
+func (f *Fake) findWindow(id *string) (*MaintenanceWindow, error) {
+	window, ok := f.state.MaintenanceWindows[aws.StringValue(id)]
+	if !ok {
+		return nil, newError(ssm.ErrCodeDoesNotExistException, fmt.Sprintf("Maintenance window %s does not exist", aws.StringValue(id)))
+	}
+	return window, nil
+}
+
+// findWindowTask return the maintenance window task or a not found error, must be called with lock held
+func (f *Fake) findWindowTask(windowID *string, taskID *string) (*MaintenanceWindow, *WindowTask, error) {
+	window, err := f.findWindow(windowID)
+	if err != nil {
+		return nil, nil, err
+	}
+	task, ok := window.Tasks[aws.StringValue(taskID)]
+	if !ok {
+		return nil, nil, newError(ssm.ErrCodeDoesNotExistException, fmt.Sprintf("Maintenance window task %s does not exist", aws.StringValue(taskID)))
+	}
+	return window, task, nil
+}
+
+// CreateMaintenanceWindow create a new maintenance window
+func (f *Fake) CreateMaintenanceWindow(input *ssm.CreateMaintenanceWindowInput) (*ssm.CreateMaintenanceWindowOutput, error) {
+	return f.CreateMaintenanceWindowWithContext(aws.BackgroundContext(), input)
+}
+
+// CreateMaintenanceWindowWithContext create a new maintenance window, like SSM names are not unique
+func (f *Fake) CreateMaintenanceWindowWithContext(ctx aws.Context, input *ssm.CreateMaintenanceWindowInput, opts ...request.Option) (*ssm.CreateMaintenanceWindowOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.begin("CreateMaintenanceWindow", input); err != nil {
+		return nil, err
+	}
+	if *input.Cutoff >= *input.Duration {
+		return nil, newError("ValidationException", "Cutoff must be less than duration")
+	}
+
+	// Store window
+	window := &MaintenanceWindow{
+		ID:                       "mw-" + hash(fmt.Sprintf("%s/%d", *input.Name, time.Now().UnixNano()))[0:17],
+		Name:                     *input.Name,
+		Schedule:                 *input.Schedule,
+		Duration:                 *input.Duration,
+		Cutoff:                   *input.Cutoff,
+		AllowUnassociatedTargets: *input.AllowUnassociatedTargets,
+		Tasks:                    map[string]*WindowTask{},
+	}
+	f.state.MaintenanceWindows[window.ID] = window
+
+	return &ssm.CreateMaintenanceWindowOutput{
+		WindowId: aws.String(window.ID),
+	}, nil
+}
+
+// DescribeMaintenanceWindows return maintenance windows, filter by name is supported
+func (f *Fake) DescribeMaintenanceWindows(input *ssm.DescribeMaintenanceWindowsInput) (*ssm.DescribeMaintenanceWindowsOutput, error) {
+	return f.DescribeMaintenanceWindowsWithContext(aws.BackgroundContext(), input)
+}
+
+// DescribeMaintenanceWindowsWithContext return maintenance windows, filter by name is supported
+func (f *Fake) DescribeMaintenanceWindowsWithContext(ctx aws.Context, input *ssm.DescribeMaintenanceWindowsInput, opts ...request.Option) (*ssm.DescribeMaintenanceWindowsOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.begin("DescribeMaintenanceWindows", input); err != nil {
+		return nil, err
+	}
+
+	// Filter windows
+	windows := []*MaintenanceWindow{}
+	for _, window := range f.state.MaintenanceWindows {
+		match := true
+		for _, filter := range input.Filters {
+			if aws.StringValue(filter.Key) != "Name" {
+				return nil, newError("ValidationException", fmt.Sprintf("Filter key %s is not supported", aws.StringValue(filter.Key)))
+			}
+			match = match && contains(aws.StringValueSlice(filter.Values), window.Name)
+		}
+		if match {
+			windows = append(windows, window)
+		}
+	}
+	sort.Slice(windows, func(i, j int) bool {
+		return windows[i].ID < windows[j].ID
+	})
+
+	output := &ssm.DescribeMaintenanceWindowsOutput{
+		WindowIdentities: []*ssm.MaintenanceWindowIdentity{},
+	}
+	for _, window := range windows {
+		output.WindowIdentities = append(output.WindowIdentities, &ssm.MaintenanceWindowIdentity{
+			WindowId: aws.String(window.ID),
+			Name:     aws.String(window.Name),
+			Schedule: aws.String(window.Schedule),
+			Duration: aws.Int64(window.Duration),
+			Cutoff:   aws.Int64(window.Cutoff),
+			Enabled:  aws.Bool(true),
+		})
+	}
+
+	return output, nil
+}
+
+// RegisterTaskWithMaintenanceWindow add a task to a maintenance window
+func (f *Fake) RegisterTaskWithMaintenanceWindow(input *ssm.RegisterTaskWithMaintenanceWindowInput) (*ssm.RegisterTaskWithMaintenanceWindowOutput, error) {
+	return f.RegisterTaskWithMaintenanceWindowWithContext(aws.BackgroundContext(), input)
+}
+
+// RegisterTaskWithMaintenanceWindowWithContext add a task to a maintenance window
+func (f *Fake) RegisterTaskWithMaintenanceWindowWithContext(ctx aws.Context, input *ssm.RegisterTaskWithMaintenanceWindowInput, opts ...request.Option) (*ssm.RegisterTaskWithMaintenanceWindowOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.begin("RegisterTaskWithMaintenanceWindow", input); err != nil {
+		return nil, err
+	}
+
+	window, err := f.findWindow(input.WindowId)
+	if err != nil {
+		return nil, err
+	}
+
+	// Store task
+	task := &WindowTask{
+		ID: newID(fmt.Sprintf("%s/%s/%d", window.ID, aws.StringValue(input.Name), time.Now().UnixNano())),
+	}
+	task.apply(windowTaskFields{
+		name:           input.Name,
+		taskType:       input.TaskType,
+		taskArn:        input.TaskArn,
+		serviceRoleArn: input.ServiceRoleArn,
+		priority:       input.Priority,
+		maxConcurrency: input.MaxConcurrency,
+		maxErrors:      input.MaxErrors,
+		targets:        input.Targets,
+		invocation:     input.TaskInvocationParameters,
+	})
+	window.Tasks[task.ID] = task
+
+	return &ssm.RegisterTaskWithMaintenanceWindowOutput{
+		WindowTaskId: aws.String(task.ID),
+	}, nil
+}
+
+// UpdateMaintenanceWindowTask update a maintenance window task
+func (f *Fake) UpdateMaintenanceWindowTask(input *ssm.UpdateMaintenanceWindowTaskInput) (*ssm.UpdateMaintenanceWindowTaskOutput, error) {
+	return f.UpdateMaintenanceWindowTaskWithContext(aws.BackgroundContext(), input)
+}
+
+// UpdateMaintenanceWindowTaskWithContext update a maintenance window task, only Replace mode is supported
+func (f *Fake) UpdateMaintenanceWindowTaskWithContext(ctx aws.Context, input *ssm.UpdateMaintenanceWindowTaskInput, opts ...request.Option) (*ssm.UpdateMaintenanceWindowTaskOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.begin("UpdateMaintenanceWindowTask", input); err != nil {
+		return nil, err
+	}
+	if !aws.BoolValue(input.Replace) {
+		return nil, newError(ssm.ErrCodeFeatureNotAvailableException, "Only updates with Replace are supported")
+	}
+
+	window, task, err := f.findWindowTask(input.WindowId, input.WindowTaskId)
+	if err != nil {
+		return nil, err
+	}
+
+	// Update task
+	task.apply(windowTaskFields{
+		name:           input.Name,
+		taskArn:        input.TaskArn,
+		serviceRoleArn: input.ServiceRoleArn,
+		priority:       input.Priority,
+		maxConcurrency: input.MaxConcurrency,
+		maxErrors:      input.MaxErrors,
+		targets:        input.Targets,
+		invocation:     input.TaskInvocationParameters,
+	})
+
+	return &ssm.UpdateMaintenanceWindowTaskOutput{
+		WindowId:     aws.String(window.ID),
+		WindowTaskId: aws.String(task.ID),
+	}, nil
+}
+
+// GetMaintenanceWindowTask return a maintenance window task
+func (f *Fake) GetMaintenanceWindowTask(input *ssm.GetMaintenanceWindowTaskInput) (*ssm.GetMaintenanceWindowTaskOutput, error) {
+	return f.GetMaintenanceWindowTaskWithContext(aws.BackgroundContext(), input)
+}
+
+// GetMaintenanceWindowTaskWithContext return a maintenance window task
+func (f *Fake) GetMaintenanceWindowTaskWithContext(ctx aws.Context, input *ssm.GetMaintenanceWindowTaskInput, opts ...request.Option) (*ssm.GetMaintenanceWindowTaskOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.begin("GetMaintenanceWindowTask", input); err != nil {
+		return nil, err
+	}
+
+	window, task, err := f.findWindowTask(input.WindowId, input.WindowTaskId)
+	if err != nil {
+		return nil, err
+	}
+
+	output := &ssm.GetMaintenanceWindowTaskOutput{
+		WindowId:                 aws.String(window.ID),
+		WindowTaskId:             aws.String(task.ID),
+		TaskType:                 aws.String(task.Type),
+		TaskArn:                  aws.String(task.TaskArn),
+		Priority:                 aws.Int64(task.Priority),
+		Targets:                  task.targets(),
+		TaskInvocationParameters: task.invocation(),
+	}
+	if len(task.Name) > 0 {
+		output.Name = aws.String(task.Name)
+	}
+	if len(task.ServiceRoleArn) > 0 {
+		output.ServiceRoleArn = aws.String(task.ServiceRoleArn)
+	}
+	if len(task.MaxConcurrency) > 0 {
+		output.MaxConcurrency = aws.String(task.MaxConcurrency)
+	}
+	if len(task.MaxErrors) > 0 {
+		output.MaxErrors = aws.String(task.MaxErrors)
+	}
+
+	return output, nil
+}
+
+// DescribeMaintenanceWindowTasks return the tasks of a maintenance window, filter by task ARN and type are supported
+func (f *Fake) DescribeMaintenanceWindowTasks(input *ssm.DescribeMaintenanceWindowTasksInput) (*ssm.DescribeMaintenanceWindowTasksOutput, error) {
+	return f.DescribeMaintenanceWindowTasksWithContext(aws.BackgroundContext(), input)
+}
+
+// DescribeMaintenanceWindowTasksWithContext return the tasks of a maintenance window, filter by task ARN and type are supported
+func (f *Fake) DescribeMaintenanceWindowTasksWithContext(ctx aws.Context, input *ssm.DescribeMaintenanceWindowTasksInput, opts ...request.Option) (*ssm.DescribeMaintenanceWindowTasksOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.begin("DescribeMaintenanceWindowTasks", input); err != nil {
+		return nil, err
+	}
+
+	window, err := f.findWindow(input.WindowId)
+	if err != nil {
+		return nil, err
+	}
+
+	// Filter tasks
+	tasks := []*WindowTask{}
+	for _, task := range window.Tasks {
+		match := true
+		for _, filter := range input.Filters {
+			switch aws.StringValue(filter.Key) {
+			case "TaskArn":
+				match = match && contains(aws.StringValueSlice(filter.Values), task.TaskArn)
+			case "TaskType":
+				match = match && contains(aws.StringValueSlice(filter.Values), task.Type)
+			default:
+				return nil, newError("ValidationException", fmt.Sprintf("Filter key %s is not supported", aws.StringValue(filter.Key)))
+			}
+		}
+		if match {
+			tasks = append(tasks, task)
+		}
+	}
+	sort.Slice(tasks, func(i, j int) bool {
+		return tasks[i].ID < tasks[j].ID
+	})
+
+	output := &ssm.DescribeMaintenanceWindowTasksOutput{
+		Tasks: []*ssm.MaintenanceWindowTask{},
+	}
+	for _, task := range tasks {
+		item := &ssm.MaintenanceWindowTask{
+			WindowId:     aws.String(window.ID),
+			WindowTaskId: aws.String(task.ID),
+			Type:         aws.String(task.Type),
+			TaskArn:      aws.String(task.TaskArn),
+			Priority:     aws.Int64(task.Priority),
+			Targets:      task.targets(),
+		}
+		if len(task.Name) > 0 {
+			item.Name = aws.String(task.Name)
+		}
+		output.Tasks = append(output.Tasks, item)
+	}
+
+	return output, nil
+}
+
+// DeregisterTaskFromMaintenanceWindow remove a task from a maintenance window
+func (f *Fake) DeregisterTaskFromMaintenanceWindow(input *ssm.DeregisterTaskFromMaintenanceWindowInput) (*ssm.DeregisterTaskFromMaintenanceWindowOutput, error) {
+	return f.DeregisterTaskFromMaintenanceWindowWithContext(aws.BackgroundContext(), input)
+}
+
+// DeregisterTaskFromMaintenanceWindowWithContext remove a task from a maintenance window
+func (f *Fake) DeregisterTaskFromMaintenanceWindowWithContext(ctx aws.Context, input *ssm.DeregisterTaskFromMaintenanceWindowInput, opts ...request.Option) (*ssm.DeregisterTaskFromMaintenanceWindowOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.begin("DeregisterTaskFromMaintenanceWindow", input); err != nil {
+		return nil, err
+	}
+
+	window, task, err := f.findWindowTask(input.WindowId, input.WindowTaskId)
+	if err != nil {
+		return nil, err
+	}
+	delete(window.Tasks, task.ID)
+
+	return &ssm.DeregisterTaskFromMaintenanceWindowOutput{
+		WindowId:     aws.String(window.ID),
+		WindowTaskId: aws.String(task.ID),
+	}, nil
+}
+
+// contains check if value is in values
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}